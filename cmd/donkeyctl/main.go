@@ -0,0 +1,102 @@
+// Command donkeyctl administers a Donkey database. It connects the same way
+// the server does, through DATABASE_URL or SQLITE_PATH.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/kairodrad/donkey/internal/db"
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/dump"
+)
+
+const usage = `usage: donkeyctl <command>
+
+commands:
+  export [file]   write every game, player and log to a JSON archive (default stdout)
+  import <file>   load a JSON archive into an empty database`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	default:
+		err = errors.New(usage)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runExport implements `export`: it dumps the configured database to the
+// given file, or to stdout.
+func runExport(args []string) error {
+	if !db.Configured() {
+		return errors.New("set DATABASE_URL or SQLITE_PATH to the database to export")
+	}
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	if len(args) == 0 {
+		return dump.Export(db.DB, os.Stdout)
+	}
+	f, err := os.Create(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := dump.Export(db.DB, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "archive written to %s\n", args[0])
+	return nil
+}
+
+// runImport implements `import`: it brings the configured database up to
+// the current schema and loads the archive into it.
+func runImport(args []string) error {
+	if len(args) != 1 {
+		return errors.New("import expects the archive file")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	if !db.Configured() {
+		return errors.New("set DATABASE_URL or SQLITE_PATH to the database to import into")
+	}
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	if err := migrations.Up(db.DB); err != nil {
+		return err
+	}
+	counts, err := dump.Import(db.DB, f)
+	if err != nil {
+		return err
+	}
+	tables := make([]string, 0, len(counts))
+	for t := range counts {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	for _, t := range tables {
+		fmt.Printf("%-18s %d\n", t, counts[t])
+	}
+	return nil
+}
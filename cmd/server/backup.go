@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/db"
+)
+
+// runBackup implements the `backup` subcommand: it copies the SQLite database
+// at SQLITE_PATH to the given file (or a timestamped file in the database's
+// directory) without stopping a running server.
+func runBackup(args []string) error {
+	path := os.Getenv("SQLITE_PATH")
+	if path == "" {
+		return errors.New("backup needs SQLITE_PATH set to the database file")
+	}
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	if !db.IsSQLite() {
+		return errors.New("backup only supports SQLite; use pg_dump for Postgres")
+	}
+
+	dest := ""
+	if len(args) > 0 {
+		dest = args[0]
+	}
+	if dest == "" {
+		dest = filepath.Join(filepath.Dir(path), fmt.Sprintf("donkey-backup-%s.db", time.Now().Format("20060102-150405")))
+	}
+	if err := db.Backup(dest); err != nil {
+		return err
+	}
+	fmt.Printf("backup written to %s\n", dest)
+	return nil
+}
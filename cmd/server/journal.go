@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kairodrad/donkey/internal/db"
+	"github.com/kairodrad/donkey/internal/journal"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+const journalUsage = `usage: server journal verify [gameId...]
+
+Replays each game's event journal and reports where the tables disagree with
+it. Without game IDs every started game that has not been archived is checked.`
+
+// runJournal implements the `journal` subcommand.
+func runJournal(args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		return errors.New(journalUsage)
+	}
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	gameIDs := args[1:]
+	if len(gameIDs) == 0 {
+		// Archived games keep their journal but not the tables to compare with
+		if err := db.DB.Model(&model.Game{}).
+			Where("status <> ?", "waiting").
+			Where("id NOT IN (?)", db.DB.Model(&model.GameArchive{}).Select("game_id")).
+			Order("created_at").Pluck("id", &gameIDs).Error; err != nil {
+			return fmt.Errorf("failed to list games: %w", err)
+		}
+	}
+
+	repo := store.NewGorm(db.DB)
+	failed := 0
+	for _, gameID := range gameIDs {
+		diffs, err := journal.Verify(repo, gameID)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("%s  error: %v\n", gameID, err)
+		case len(diffs) > 0:
+			failed++
+			fmt.Printf("%s  %d differences\n", gameID, len(diffs))
+			for _, d := range diffs {
+				fmt.Printf("    %s\n", d)
+			}
+		default:
+			fmt.Printf("%s  ok\n", gameID)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d games do not match their journal", failed, len(gameIDs))
+	}
+	return nil
+}
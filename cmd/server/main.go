@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/kairodrad/donkey/internal/server"
 )
@@ -11,6 +12,36 @@ import (
 // @description API for the Donkey card game
 // @BasePath /
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "backup":
+			if err := runBackup(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "journal":
+			if err := runJournal(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "stats":
+			if err := runStats(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "retention":
+			if err := runRetention(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	r := server.New()
 	if err := r.Run(); err != nil {
 		log.Fatal(err)
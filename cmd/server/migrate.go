@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kairodrad/donkey/internal/db"
+	"github.com/kairodrad/donkey/internal/db/migrations"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up          apply all pending migrations (default)
+  down [n]    revert the last n applied migrations (default 1)
+  status      list migrations and whether they are applied`
+
+// runMigrate implements the `migrate` subcommand against DATABASE_URL.
+func runMigrate(args []string) error {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	switch cmd {
+	case "up":
+		if err := migrations.Up(db.DB); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return errors.New("down expects a positive number of steps")
+			}
+			steps = n
+		}
+		if err := migrations.Down(db.DB, steps); err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrations.List(db.DB)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if st.Applied {
+				applied = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-24s %s\n", st.Version, st.Name, applied)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	current, err := migrations.Current(db.DB)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema at version %d\n", current)
+	return nil
+}
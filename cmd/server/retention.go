@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/db"
+	"github.com/kairodrad/donkey/internal/retention"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+const retentionUsage = `usage: server retention run
+
+Applies the retention policy from the RETENTION_* variables once and prints
+what it removed.`
+
+// runRetention implements the `retention` subcommand.
+func runRetention(args []string) error {
+	if len(args) != 1 || args[0] != "run" {
+		return errors.New(retentionUsage)
+	}
+	policy, err := retention.PolicyFromEnv()
+	if err != nil {
+		return err
+	}
+	if !db.Configured() {
+		return errors.New("set DATABASE_URL or SQLITE_PATH to the database to clean up")
+	}
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	report := retention.Run(store.NewGorm(db.DB), policy, time.Now())
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	if err := out.Encode(report); err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("retention finished with %d errors", len(report.Errors))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kairodrad/donkey/internal/db"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/rating"
+	"github.com/kairodrad/donkey/internal/stats"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+const statsUsage = `usage: server stats rebuild [gameId...]
+
+Tallies the players' statistics of each completed game again, from its archive
+once it has been compacted. Without game IDs every completed game is tallied.
+A rated game whose ratings were never updated is rated then; ratings already
+moved by a game are left as they are.`
+
+// runStats implements the `stats` subcommand.
+func runStats(args []string) error {
+	if len(args) == 0 || args[0] != "rebuild" {
+		return errors.New(statsUsage)
+	}
+	if err := db.Open(); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	gameIDs := args[1:]
+	if len(gameIDs) == 0 {
+		if err := db.DB.Model(&model.Game{}).
+			Where("status = ?", "completed").
+			Order("completed_at").Pluck("id", &gameIDs).Error; err != nil {
+			return fmt.Errorf("failed to list games: %w", err)
+		}
+	}
+
+	repo := store.NewGorm(db.DB)
+	failed := 0
+	for _, gameID := range gameIDs {
+		if err := stats.Record(repo, gameID); err != nil {
+			failed++
+			fmt.Printf("%s  error: %v\n", gameID, err)
+			continue
+		}
+		if _, err := rating.Update(repo, gameID); err != nil {
+			failed++
+			fmt.Printf("%s  error: %v\n", gameID, err)
+			continue
+		}
+		fmt.Printf("%s  ok\n", gameID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d games could not be tallied", failed, len(gameIDs))
+	}
+	return nil
+}
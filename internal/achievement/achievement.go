@@ -0,0 +1,139 @@
+// Package achievement awards badges for feats in completed games. Every badge
+// is a Rule in the registry, and Evaluate runs all of them over a game as it
+// ends. A user earns each badge once; bots earn none.
+package achievement
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Game is a completed game as the rules see it
+type Game struct {
+	ID      string
+	LoserID string
+	Players []model.GamePlayer // in join order, with their users and final letters
+	Rounds  []Round
+}
+
+// Round is a completed round of a Game
+type Round struct {
+	Number      int
+	LoserID     string
+	FinishOrder []string     // user IDs, first out of cards first and the loser last
+	Turns       []model.Turn // in turn order, with their played cards
+}
+
+// Rule awards one badge
+type Rule struct {
+	Badge       string // stored in model.Achievement, e.g. "triple_cut"
+	Name        string
+	Description string
+	// Earned reports whether player earned the badge in g
+	Earned func(g *Game, player model.GamePlayer) bool
+}
+
+var rules []Rule
+
+// Register adds a rule to the registry. It panics on a badge registered twice.
+func Register(r Rule) {
+	if _, ok := Lookup(r.Badge); ok {
+		panic(fmt.Sprintf("achievement: badge %s registered twice", r.Badge))
+	}
+	rules = append(rules, r)
+}
+
+// Rules returns every registered rule in the order registered
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// Lookup returns the rule of a badge
+func Lookup(badge string) (Rule, bool) {
+	for _, r := range rules {
+		if r.Badge == badge {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Load builds the rules' view of a completed game from its rows
+func Load(repo store.Store, gameID string) (*Game, error) {
+	game, err := repo.Games().Get(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game: %w", err)
+	}
+	g := &Game{ID: game.ID}
+	if game.LoserID != nil {
+		g.LoserID = *game.LoserID
+	}
+	if g.Players, err = repo.Games().Players(gameID); err != nil {
+		return nil, fmt.Errorf("failed to load game players: %w", err)
+	}
+
+	rounds, err := repo.Rounds().List(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rounds: %w", err)
+	}
+	for _, r := range rounds {
+		if r.Status != "completed" {
+			continue
+		}
+		round := Round{Number: r.RoundNumber}
+		if r.LoserID != nil {
+			round.LoserID = *r.LoserID
+		}
+		players, err := repo.Rounds().Players(r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load round players: %w", err)
+		}
+		for _, rp := range archive.FinishOrder(players) {
+			round.FinishOrder = append(round.FinishOrder, rp.UserID)
+		}
+		if round.Turns, err = repo.Turns().List(r.ID); err != nil {
+			return nil, fmt.Errorf("failed to load turns: %w", err)
+		}
+		g.Rounds = append(g.Rounds, round)
+	}
+	return g, nil
+}
+
+// Evaluate awards the human players of a completed game every badge they
+// earned in it and did not hold yet, and returns the new ones
+func Evaluate(repo store.Store, gameID string) ([]model.Achievement, error) {
+	g, err := Load(repo, gameID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var earned []model.Achievement
+	for _, player := range g.Players {
+		if player.User.IsBot {
+			continue
+		}
+		held, err := repo.Achievements().List(player.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load achievements: %w", err)
+		}
+		has := make(map[string]bool, len(held))
+		for _, a := range held {
+			has[a.Badge] = true
+		}
+		for _, rule := range rules {
+			if has[rule.Badge] || !rule.Earned(g, player) {
+				continue
+			}
+			a := model.Achievement{UserID: player.UserID, Badge: rule.Badge, GameID: gameID, EarnedAt: now}
+			if err := repo.Achievements().Award(&a); err != nil {
+				return nil, fmt.Errorf("failed to award %s: %w", rule.Badge, err)
+			}
+			earned = append(earned, a)
+		}
+	}
+	return earned, nil
+}
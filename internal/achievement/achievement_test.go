@@ -0,0 +1,89 @@
+package achievement_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/achievement"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func earned(t *testing.T, g *achievement.Game, userID string) []string {
+	t.Helper()
+	var player model.GamePlayer
+	for _, gp := range g.Players {
+		if gp.UserID == userID {
+			player = gp
+		}
+	}
+	require.Equal(t, userID, player.UserID)
+	var badges []string
+	for _, rule := range achievement.Rules() {
+		if rule.Earned(g, player) {
+			badges = append(badges, rule.Badge)
+		}
+	}
+	return badges
+}
+
+func cut(cutter string) model.Turn {
+	return model.Turn{Status: "cut", CutPlayerID: &cutter}
+}
+
+func TestRules(t *testing.T) {
+	g := &achievement.Game{
+		LoserID: "bob",
+		Players: []model.GamePlayer{
+			{UserID: "ann", DonkeyLetters: ""},
+			{UserID: "bob", DonkeyLetters: "DONKEY"},
+			{UserID: "cat", DonkeyLetters: "DONKE"},
+			{UserID: "bot", DonkeyLetters: "DO", User: model.User{IsBot: true, BotDifficulty: "difficult"}},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		g.Rounds = append(g.Rounds, achievement.Round{Number: i + 1, FinishOrder: []string{"ann", "cat", "bot", "bob"}})
+	}
+	g.Rounds[2].Turns = []model.Turn{cut("cat"), cut("cat"), {Status: "completed"}, cut("cat")}
+	g.Rounds[3].Turns = []model.Turn{cut("bob"), cut("bob")}
+
+	assert.Equal(t, []string{"clean_game", "five_in_a_row", "bot_slayer"}, earned(t, g, "ann"))
+	assert.Equal(t, []string{"triple_cut", "survivor"}, earned(t, g, "cat"))
+	assert.Empty(t, earned(t, g, "bob"))
+
+	// The streak has to be unbroken
+	g.Rounds[2].FinishOrder = []string{"cat", "ann", "bot", "bob"}
+	assert.NotContains(t, earned(t, g, "ann"), "five_in_a_row")
+}
+
+func TestEvaluateAwardsEachBadgeOnce(t *testing.T) {
+	repo := store.NewMemory()
+	require.NoError(t, repo.Users().Create(&model.User{ID: "ann", Name: "Ann"}))
+	require.NoError(t, repo.Users().Create(&model.User{ID: "bot", Name: "Dusty", IsBot: true, BotDifficulty: "easy"}))
+	game := func() string {
+		now := time.Now()
+		loser := "bot"
+		g := model.Game{ID: model.NewID(), Status: "completed", CompletedAt: &now, LoserID: &loser}
+		require.NoError(t, repo.Games().Create(&g))
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: "ann", JoinOrder: 0}))
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: "bot", JoinOrder: 1, DonkeyLetters: "DONKEY"}))
+		return g.ID
+	}
+
+	first := game()
+	awarded, err := achievement.Evaluate(repo, first)
+	require.NoError(t, err)
+	require.Len(t, awarded, 1, "bots earn no badges")
+	assert.Equal(t, model.Achievement{UserID: "ann", Badge: "clean_game", GameID: first, EarnedAt: awarded[0].EarnedAt}, awarded[0])
+
+	awarded, err = achievement.Evaluate(repo, game())
+	require.NoError(t, err)
+	assert.Empty(t, awarded)
+	held, err := repo.Achievements().List("ann")
+	require.NoError(t, err)
+	require.Len(t, held, 1)
+	assert.Equal(t, first, held[0].GameID)
+}
@@ -0,0 +1,72 @@
+package achievement
+
+import "github.com/kairodrad/donkey/internal/model"
+
+func init() {
+	Register(Rule{
+		Badge:       "clean_game",
+		Name:        "Spotless",
+		Description: "Finish a game without a single letter",
+		Earned: func(g *Game, player model.GamePlayer) bool {
+			return player.DonkeyLetters == ""
+		},
+	})
+	Register(Rule{
+		Badge:       "triple_cut",
+		Name:        "Triple Cut",
+		Description: "Cut three times in one round",
+		Earned: func(g *Game, player model.GamePlayer) bool {
+			for _, round := range g.Rounds {
+				cuts := 0
+				for _, turn := range round.Turns {
+					if turn.Status == "cut" && turn.CutPlayerID != nil && *turn.CutPlayerID == player.UserID {
+						cuts++
+					}
+				}
+				if cuts >= 3 {
+					return true
+				}
+			}
+			return false
+		},
+	})
+	Register(Rule{
+		Badge:       "five_in_a_row",
+		Name:        "On a Roll",
+		Description: "Be first out of cards five rounds in a row",
+		Earned: func(g *Game, player model.GamePlayer) bool {
+			streak := 0
+			for _, round := range g.Rounds {
+				if len(round.FinishOrder) == 0 || round.FinishOrder[0] != player.UserID {
+					streak = 0
+					continue
+				}
+				if streak++; streak == 5 {
+					return true
+				}
+			}
+			return false
+		},
+	})
+	Register(Rule{
+		Badge:       "survivor",
+		Name:        "Hanging On",
+		Description: "Sit on DONKE and still not end up the DONKEY",
+		Earned: func(g *Game, player model.GamePlayer) bool {
+			return player.DonkeyLetters == "DONKE" && player.UserID != g.LoserID
+		},
+	})
+	Register(Rule{
+		Badge:       "bot_slayer",
+		Name:        "Bot Slayer",
+		Description: "Finish a game with fewer letters than a difficult bot",
+		Earned: func(g *Game, player model.GamePlayer) bool {
+			for _, other := range g.Players {
+				if other.User.IsBot && other.User.BotDifficulty == "difficult" && len(other.DonkeyLetters) > len(player.DonkeyLetters) {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// PlayersOnly serves a game handler only to the game's players, named by the
+// userId query parameter. Anyone else follows a game through its share link,
+// where Shared hands on to the same handler.
+func (h *Handlers) PlayersOnly(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("userId")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing userId"})
+			return
+		}
+		if !h.requirePlayer(c, c.Param("gameId"), userID) {
+			return
+		}
+		next(c)
+	}
+}
+
+// requirePlayer reports whether userID plays in a game, answering the request
+// itself when they do not
+func (h *Handlers) requirePlayer(c *gin.Context, gameID, userID string) bool {
+	_, err := h.repo.Games().Player(gameID, userID)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if _, err := h.repo.Games().Get(gameID); err == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only players can view this game"})
+		return false
+	}
+
+	// Imported games have an archive and no game row
+	record, err := archive.Load(h.repo, gameID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	for _, p := range record.Players {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "only players can view this game"})
+	return false
+}
+
+// viaShareLink reports whether a request came in through a share token, whose
+// responses never name the game: its ID is all the game routes need
+func viaShareLink(c *gin.Context) bool {
+	return c.Param("token") != ""
+}
+
+// shownGameID returns the game ID a response may carry
+func shownGameID(c *gin.Context, gameID string) string {
+	if viaShareLink(c) {
+		return ""
+	}
+	return gameID
+}
+
+// finishedCacheControl is the Cache-Control of an image of a finished game.
+// Through a share link it is checked on every use, so revoking the link
+// takes effect at once; the game's players may keep it.
+func finishedCacheControl(c *gin.Context) string {
+	if viaShareLink(c) {
+		return "private, no-cache"
+	}
+	return "private, max-age=86400"
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/achievement"
+)
+
+// Badge is an achievement a user has earned
+type Badge struct {
+	Badge       string    `json:"badge"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	GameID      string    `json:"gameId"` // the game it was earned in
+	EarnedAt    time.Time `json:"earnedAt"`
+}
+
+// UserAchievementsHandler lists the badges a user has earned.
+//
+// @Summary      User achievements
+// @Tags         user
+// @Produce      json
+// @Param        id  path  string  true  "user id"
+// @Success      200  {array}  Badge
+// @Failure      404  {object}  map[string]string
+// @Router       /api/user/{id}/achievements [get]
+func (h *Handlers) UserAchievementsHandler(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.repo.Users().Get(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	earned, err := h.repo.Achievements().List(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	badges := make([]Badge, 0, len(earned))
+	for _, a := range earned {
+		rule, ok := achievement.Lookup(a.Badge)
+		if !ok {
+			// A badge whose rule was retired
+			rule = achievement.Rule{Badge: a.Badge, Name: a.Badge}
+		}
+		badges = append(badges, Badge{
+			Badge:       a.Badge,
+			Name:        rule.Name,
+			Description: rule.Description,
+			GameID:      a.GameID,
+			EarnedAt:    a.EarnedAt,
+		})
+	}
+	c.JSON(http.StatusOK, badges)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"image/gif"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/render"
+)
+
+// RoundAnimationHandler returns a round of a finished game as an animated
+// GIF.
+//
+// @Summary      Round animation
+// @Description  A round played out card by card around the table, with cuts highlighted and pickups shown
+// @Tags         game
+// @Produce      gif
+// @Param        gameId  path   string  true  "Game ID"
+// @Param        round   query  int     true  "Round number"
+// @Param        userId  query  string  true  "Player asking"
+// @Success      200  {file}    binary
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/game/{gameId}/replay/animation.gif [get]
+func (h *Handlers) RoundAnimationHandler(c *gin.Context) {
+	roundNumber, err := strconv.Atoi(c.Query("round"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid round"})
+		return
+	}
+	gameID := c.Param("gameId")
+	key := gameID + "/" + strconv.Itoa(roundNumber)
+	data, ok := h.animations.get(key)
+	if !ok {
+		if data, ok = h.renderRound(c, gameID, roundNumber); !ok {
+			return
+		}
+		h.animations.put(key, data)
+	}
+	c.Header("Cache-Control", finishedCacheControl(c))
+	c.Data(http.StatusOK, "image/gif", data)
+}
+
+// renderRound draws and encodes the animation of a finished game's round,
+// answering the request itself when it cannot
+func (h *Handlers) renderRound(c *gin.Context, gameID string, roundNumber int) ([]byte, bool) {
+	record, ok := h.loadFinishedGame(c, gameID)
+	if !ok {
+		return nil, false
+	}
+	for _, round := range record.Rounds {
+		if round.RoundNumber != roundNumber {
+			continue
+		}
+		animation, err := render.RoundAnimation(record.Players, round)
+		if errors.Is(err, render.ErrNoTricks) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, animation); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		return buf.Bytes(), true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "game has no round " + strconv.Itoa(roundNumber)})
+	return nil, false
+}
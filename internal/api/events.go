@@ -11,29 +11,42 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/kairodrad/donkey/internal/db"
 	"github.com/kairodrad/donkey/internal/model"
 )
 
 type event struct {
-	Type string                `json:"type"`
-	Log  *model.GameSessionLog `json:"log,omitempty"`
+	Type     string                `json:"type"`
+	Log      *model.GameSessionLog `json:"log,omitempty"`
+	Presence *presenceEvent        `json:"presence,omitempty"`
+	Private  *privateEvent         `json:"private,omitempty"`
+	Version  uint64                `json:"version"` // sequence number, see eventSeqs
+
+	to string // recipient of a private event, empty for the whole table
+	at time.Time
+}
+
+// privateEvent carries data meant for a single player only, such as the
+// cards they picked up from a cut or the cards they may legally play.
+type privateEvent struct {
+	Kind string      `json:"kind"` // "hand_dealt", "cards_received", "your_turn"
+	Data interface{} `json:"data,omitempty"`
 }
 
 type broker struct {
-	mu   sync.Mutex
-	subs map[string]map[chan event]struct{}
+	mu      sync.Mutex
+	subs    map[string]map[chan event]string // gameID -> channel -> userID
+	history map[string]*eventHistory         // gameID -> recent events, for long polling
 }
 
-var b = broker{subs: make(map[string]map[chan event]struct{})}
+var b = broker{subs: make(map[string]map[chan event]string), history: make(map[string]*eventHistory)}
 
-func (br *broker) subscribe(gameID string) chan event {
+func (br *broker) subscribe(gameID, userID string) chan event {
 	ch := make(chan event, 8)
 	br.mu.Lock()
 	if br.subs[gameID] == nil {
-		br.subs[gameID] = make(map[chan event]struct{})
+		br.subs[gameID] = make(map[chan event]string)
 	}
-	br.subs[gameID][ch] = struct{}{}
+	br.subs[gameID][ch] = userID
 	br.mu.Unlock()
 	return ch
 }
@@ -50,25 +63,49 @@ func (br *broker) unsubscribe(gameID string, ch chan event) {
 	close(ch)
 }
 
+// publish delivers an event to every stream of a game.
 func (br *broker) publish(gameID string, ev event) {
+	br.deliver(gameID, "", ev)
+}
+
+// publishTo delivers an event only to the streams opened by userID.
+func (br *broker) publishTo(gameID, userID string, ev event) {
+	br.deliver(gameID, userID, ev)
+}
+
+// deliver stamps the event with its sequence number, records it for long
+// polling and fans it out to matching subscribers. The number is taken under
+// the broker lock so history stays ordered. Only events every viewer sees in
+// the game state move its state version: a private event or a presence
+// change leaves the shared view, and every viewer's ETag, as it is.
+func (br *broker) deliver(gameID, to string, ev event) {
 	br.mu.Lock()
-	m := br.subs[gameID]
-	for ch := range m {
+	defer br.mu.Unlock()
+	ev.Version = eventSeqs.bump(gameID)
+	if to == "" && ev.Type != "presence" {
+		stateVersions.bump(gameID)
+	}
+	ev.to = to
+	ev.at = time.Now()
+	br.record(gameID, ev)
+	for ch, uid := range br.subs[gameID] {
+		if to != "" && uid != to {
+			continue
+		}
 		select {
 		case ch <- ev:
 		default:
 		}
 	}
-	br.mu.Unlock()
 }
 
-func logAndSend(gameID, userID, typ, message string) {
+func (h *Handlers) logAndSend(gameID, userID, typ, message string) {
 	var userIDPtr *string
 	if userID != "" {
 		userIDPtr = &userID
 	}
 	entry := model.GameSessionLog{ID: model.NewID(), GameID: gameID, UserID: userIDPtr, Type: typ, Message: message, CreatedAt: time.Now()}
-	db.DB.Create(&entry)
+	h.repo.Logs().Create(&entry)
 	b.publish(gameID, event{Type: "log", Log: &entry})
 }
 
@@ -81,34 +118,18 @@ func PublishState(gameID string) {
 	publishState(gameID)
 }
 
-// PublishLog publishes a structured log event with eventData (used by game manager)
-func PublishLog(gameID, userID, logType, message string, eventData interface{}) {
-	var userIDPtr *string
-	if userID != "" {
-		userIDPtr = &userID
-	}
-	
-	entry := model.GameSessionLog{
-		ID:        model.NewID(), 
-		GameID:    gameID, 
-		UserID:    userIDPtr, 
-		Type:      logType, 
-		Message:   message, 
-		CreatedAt: time.Now(),
-	}
-	
-	// Add structured eventData if provided
-	if eventData != nil {
-		if b, err := json.Marshal(eventData); err == nil {
-			entry.EventData = string(b)
-		}
-	}
-	
-	db.DB.Create(&entry)
-	b.publish(gameID, event{Type: "log", Log: &entry})
+// PublishLog publishes a log entry the game manager has already stored
+func PublishLog(entry *model.GameSessionLog) {
+	b.publish(entry.GameID, event{Type: "log", Log: entry})
+}
+
+// PublishPrivate publishes an event to a single player's streams (used by game manager)
+func PublishPrivate(gameID, userID, kind string, data interface{}) {
+	b.publishTo(gameID, userID, event{Type: "private", Private: &privateEvent{Kind: kind, Data: data}})
 }
 
-// StreamHandler provides a long-lived stream of events for a game.
+// StreamHandler provides a long-lived stream of events for a game to one of
+// its players, private events included.
 //
 // @Summary      Stream game updates
 // @Description  Streams session and state change events for a game
@@ -117,23 +138,30 @@ func PublishLog(gameID, userID, logType, message string, eventData interface{})
 // @Param        gameId  path  string  true  "Game ID"
 // @Param        userId  path  string  true  "User ID"
 // @Success      200  {string}  string  "event stream"
+// @Failure      403  {object}  map[string]string
 // @Router       /api/game/{gameId}/stream/{userId} [get]
-func StreamHandler(c *gin.Context) {
+func (h *Handlers) StreamHandler(c *gin.Context) {
 	gameID := c.Param("gameId")
 	userID := c.Param("userId")
 	if gameID == "" || userID == "" {
 		c.Status(http.StatusBadRequest)
 		return
 	}
-	ch := b.subscribe(gameID)
+	if !h.requirePlayer(c, gameID, userID) {
+		return
+	}
+	ch := b.subscribe(gameID, userID)
 	defer b.unsubscribe(gameID, ch)
 
-	var user model.User
-	db.DB.First(&user, "id = ?", userID)
-	var gameModel model.Game
-	db.DB.First(&gameModel, "id = ?", gameID)
-	db.DB.Model(&model.GamePlayer{}).Where("game_id = ? AND user_id = ?", gameID, userID).Update("is_connected", true)
-	logAndSend(gameID, userID, "status", user.Name+": connected to the game")
+	h.connectPlayer(gameID, userID, h.presence.connect(gameID, userID))
+	h.touchPlayer(gameID, userID)
+
+	// Send a first heartbeat right away so the stream opens even when no
+	// connect log was published for this tab.
+	c.SSEvent("heartbeat", time.Now().Unix())
+	c.Writer.Flush()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
 
 	c.Stream(func(w io.Writer) bool {
 		select {
@@ -141,22 +169,22 @@ func StreamHandler(c *gin.Context) {
 			data, _ := json.Marshal(ev)
 			c.SSEvent("message", string(data))
 			return true
+		case t := <-heartbeat.C:
+			c.SSEvent("heartbeat", t.Unix())
+			return true
 		case <-c.Request.Context().Done():
 			return false
 		}
 	})
 
-	db.DB.Model(&model.GamePlayer{}).Where("game_id = ? AND user_id = ?", gameID, userID).Update("is_connected", false)
-	logAndSend(gameID, userID, "status", user.Name+": disconnected from the game")
-	if gameModel.RequesterID == userID {
-		db.DB.Model(&model.Game{}).Where("id = ?", gameID).Update("status", "abandoned")
-		logAndSend(gameID, userID, "status", "Game was terminated because the creator disconnected")
-		publishState(gameID)
+	// Other tabs and polls for the same user keep them connected.
+	if h.presence.disconnect(gameID, userID) {
+		h.disconnectPlayer(gameID, userID)
 	}
 }
 
 // ChatHandler records a chat message.
-func ChatHandler(c *gin.Context) {
+func (h *Handlers) ChatHandler(c *gin.Context) {
 	var req struct {
 		GameID  string `json:"gameId"`
 		UserID  string `json:"userId"`
@@ -176,34 +204,39 @@ func ChatHandler(c *gin.Context) {
 		msg = string(runes[:128])
 	}
 	msg = html.EscapeString(msg)
-	var user model.User
-	db.DB.First(&user, "id = ?", req.UserID)
-	logAndSend(req.GameID, req.UserID, "chat", user.Name+": "+msg)
+	user := h.userOrEmpty(req.UserID)
+	h.touchPlayer(req.GameID, req.UserID)
+	h.logAndSend(req.GameID, req.UserID, "chat", user.Name+": "+msg)
 	c.Status(http.StatusOK)
 }
 
-// LogsHandler returns existing session logs for a game.
+// LogsHandler returns existing session logs for a game to one of its
+// players.
 //
 // @Summary      List session logs
 // @Description  Retrieves chat and status logs for a game in reverse chronological order
 // @Tags         events
 // @Produce      json
-// @Param        gameId  path  string  true  "Game ID"
+// @Param        gameId  path   string  true  "Game ID"
+// @Param        userId  query  string  true  "Player asking"
 // @Success      200  {array}  model.GameSessionLog
+// @Failure      403  {object}  map[string]string
 // @Router       /api/game/{gameId}/logs [get]
-func LogsHandler(c *gin.Context) {
+func (h *Handlers) LogsHandler(c *gin.Context) {
 	gameID := c.Param("gameId")
 	if gameID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing"})
 		return
 	}
-	var logs []model.GameSessionLog
-	db.DB.Where("game_id = ?", gameID).Order("created_at desc").Find(&logs)
+	logs, _ := h.repo.Logs().Recent(gameID, 0)
+	if logs == nil {
+		logs = []model.GameSessionLog{}
+	}
 	c.JSON(http.StatusOK, logs)
 }
 
 // LegacyAbandonHandler is the old abandon handler (deprecated, use AbandonGameHandler in game.go)
-func LegacyAbandonHandler(c *gin.Context) {
+func (h *Handlers) LegacyAbandonHandler(c *gin.Context) {
 	var req struct {
 		GameID string `json:"gameId"`
 		UserID string `json:"userId"`
@@ -212,8 +245,8 @@ func LegacyAbandonHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid"})
 		return
 	}
-	var gameModel model.Game
-	if err := db.DB.First(&gameModel, "id = ?", req.GameID).Error; err != nil {
+	gameModel, err := h.repo.Games().Get(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
 		return
 	}
@@ -224,10 +257,9 @@ func LegacyAbandonHandler(c *gin.Context) {
 	gameModel.Status = "abandoned"
 	now := time.Now()
 	gameModel.CompletedAt = &now
-	db.DB.Save(&gameModel)
-	var user model.User
-	db.DB.First(&user, "id = ?", req.UserID)
-	logAndSend(gameModel.ID, req.UserID, "status", "Game was abandoned by "+user.Name)
+	h.repo.Games().Save(gameModel)
+	user := h.userOrEmpty(req.UserID)
+	h.logAndSend(gameModel.ID, req.UserID, "status", "Game was abandoned by "+user.Name)
 	publishState(gameModel.ID)
 	c.Status(http.StatusOK)
 }
@@ -0,0 +1,205 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamStaysConnectedWhileAnotherTabIsOpen(t *testing.T) {
+	ts := httptest.NewServer(server.New())
+	defer ts.Close()
+	client := ts.Client()
+	reg := func(name string) map[string]string {
+		resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"`+name+`"}`))
+		var u map[string]string
+		json.NewDecoder(resp.Body).Decode(&u)
+		return u
+	}
+	host := reg("Host")
+	guest := reg("Guest")
+	resp, _ := client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host["id"]+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+	client.Post(ts.URL+"/api/game/join", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+guest["id"]+`"}`))
+
+	open := func() context.CancelFunc {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/game/"+gameID+"/stream/"+guest["id"], nil)
+		streamResp, err := client.Do(req)
+		require.NoError(t, err)
+		go func() {
+			<-ctx.Done()
+			streamResp.Body.Close()
+		}()
+		return cancel
+	}
+	guestConnected := func() bool {
+		stateResp, _ := client.Get(ts.URL + "/api/game/" + gameID + "/state/" + host["id"])
+		var state struct {
+			Players []struct {
+				ID          string `json:"id"`
+				IsConnected bool   `json:"isConnected"`
+			} `json:"players"`
+		}
+		json.NewDecoder(stateResp.Body).Decode(&state)
+		for _, p := range state.Players {
+			if p.ID == guest["id"] {
+				return p.IsConnected
+			}
+		}
+		return false
+	}
+
+	closeFirst := open()
+	closeSecond := open()
+
+	closeFirst()
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, guestConnected(), "guest should stay connected through the second tab")
+
+	closeSecond()
+	assert.Eventually(t, func() bool { return !guestConnected() }, 2*time.Second, 50*time.Millisecond)
+}
+
+func TestPrivateEventsOnlyReachTheirOwner(t *testing.T) {
+	ts := httptest.NewServer(server.New())
+	defer ts.Close()
+	client := ts.Client()
+	reg := func(name string) map[string]string {
+		resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"`+name+`"}`))
+		var u map[string]string
+		json.NewDecoder(resp.Body).Decode(&u)
+		return u
+	}
+	host := reg("Host")
+	guest := reg("Guest")
+	resp, _ := client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host["id"]+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+	client.Post(ts.URL+"/api/game/join", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+guest["id"]+`"}`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := func(userID string) <-chan string {
+		req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/game/"+gameID+"/stream/"+userID, nil)
+		streamResp, err := client.Do(req)
+		require.NoError(t, err)
+		out := make(chan string, 64)
+		go func() {
+			defer streamResp.Body.Close()
+			scanner := bufio.NewScanner(streamResp.Body)
+			for scanner.Scan() {
+				out <- scanner.Text()
+			}
+		}()
+		return out
+	}
+	waitFor := func(ch <-chan string, substr string, d time.Duration) bool {
+		deadline := time.After(d)
+		for {
+			select {
+			case line := <-ch:
+				if strings.Contains(line, substr) {
+					return true
+				}
+			case <-deadline:
+				return false
+			}
+		}
+	}
+	hostLines := lines(host["id"])
+	guestLines := lines(guest["id"])
+
+	api.PublishPrivate(gameID, guest["id"], "cards_received", map[string]interface{}{"cards": []string{"AS"}})
+
+	assert.True(t, waitFor(guestLines, `"kind":"cards_received"`, 2*time.Second))
+	assert.False(t, waitFor(hostLines, `"kind":"cards_received"`, 300*time.Millisecond))
+}
+
+func TestPollReturnsEventsAfterCursor(t *testing.T) {
+	ts := httptest.NewServer(server.New())
+	defer ts.Close()
+	client := ts.Client()
+	resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"Host"}`))
+	var host map[string]string
+	json.NewDecoder(resp.Body).Decode(&host)
+	resp, _ = client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host["id"]+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+
+	type pollResponse struct {
+		Events []struct {
+			Type    string `json:"type"`
+			Version uint64 `json:"version"`
+		} `json:"events"`
+		Cursor uint64 `json:"cursor"`
+		Resync bool   `json:"resync"`
+	}
+	poll := func(since uint64) pollResponse {
+		resp, err := client.Get(ts.URL + "/api/game/" + gameID + "/poll?userId=" + host["id"] + "&timeout=2&since=" + strconv.FormatUint(since, 10))
+		require.NoError(t, err)
+		var out pollResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+
+	first := poll(0)
+	require.NotEmpty(t, first.Events, "game creation events should be returned immediately")
+	assert.False(t, first.Resync)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		client.Post(ts.URL+"/api/game/chat", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+host["id"]+`","message":"hi"}`))
+	}()
+	second := poll(first.Cursor)
+	require.NotEmpty(t, second.Events, "poll should wake up for the chat message")
+	assert.Equal(t, "log", second.Events[0].Type)
+	assert.Greater(t, second.Events[0].Version, first.Cursor)
+}
+
+func TestOnlyPlayersFollowAGame(t *testing.T) {
+	ts := httptest.NewServer(server.New())
+	defer ts.Close()
+	client := ts.Client()
+	reg := func(name string) map[string]string {
+		resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"`+name+`"}`))
+		var u map[string]string
+		json.NewDecoder(resp.Body).Decode(&u)
+		return u
+	}
+	host := reg("Host")
+	outsider := reg("Outsider")
+	resp, _ := client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host["id"]+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+
+	resp, err := client.Get(ts.URL + "/api/game/" + gameID + "/stream/" + outsider["id"])
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, err = client.Get(ts.URL + "/api/game/" + gameID + "/poll?userId=" + outsider["id"] + "&timeout=0")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, err = client.Get(ts.URL + "/api/game/" + gameID + "/poll?userId=" + host["id"] + "&timeout=0")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
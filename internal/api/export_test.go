@@ -0,0 +1,21 @@
+package api
+
+import "time"
+
+// IdleTimeout is how long a player may be idle before they are reported away
+const IdleTimeout = idleTimeout
+
+// SweepIdlePlayers runs the idle sweep once, as of now
+func (h *Handlers) SweepIdlePlayers(now time.Time) {
+	h.sweepIdlePlayers(now)
+}
+
+// SetReconnectGrace sets how long a disconnected player has to come back
+func (h *Handlers) SetReconnectGrace(d time.Duration) {
+	h.reconnectGrace = d
+}
+
+// ExpirePolls disconnects the clients whose polls lapsed before now
+func (h *Handlers) ExpirePolls(now time.Time) {
+	h.expirePolls(now)
+}
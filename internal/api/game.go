@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,9 +9,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/kairodrad/donkey/internal/db"
 	"github.com/kairodrad/donkey/internal/game"
 	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+	"github.com/kairodrad/donkey/internal/webhook"
 )
 
 // RegisterRequest is the request body for user registration.
@@ -19,7 +21,7 @@ type RegisterRequest struct {
 }
 
 // RegisterHandler registers a new user.
-func RegisterHandler(c *gin.Context) {
+func (h *Handlers) RegisterHandler(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid name"})
@@ -38,7 +40,7 @@ func RegisterHandler(c *gin.Context) {
         IsBot:     false,
         CreatedAt: time.Now(),
 	}
-	if err := db.DB.Create(&user).Error; err != nil {
+	if err := h.repo.Users().Create(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -70,10 +72,11 @@ type CreateGameRequest struct {
 	RequesterID string `json:"requesterId"`
 	MaxPlayers  int    `json:"maxPlayers,omitempty"`
 	MinPlayers  int    `json:"minPlayers,omitempty"`
+	Rated       *bool  `json:"rated,omitempty"` // false for a casual game; rated by default
 }
 
 // CreateGameHandler creates a new game
-func CreateGameHandler(c *gin.Context) {
+func (h *Handlers) CreateGameHandler(c *gin.Context) {
 	var req CreateGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil || req.RequesterID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid requester"})
@@ -81,8 +84,8 @@ func CreateGameHandler(c *gin.Context) {
 	}
 
 	// Validate user exists
-	var user model.User
-	if err := db.DB.First(&user, "id = ?", req.RequesterID).Error; err != nil {
+	user, err := h.repo.Users().Get(req.RequesterID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
@@ -105,7 +108,7 @@ func CreateGameHandler(c *gin.Context) {
 		CreatedAt:   time.Now(),
 	}
 
-	if err := db.DB.Create(&gameModel).Error; err != nil {
+	if err := h.repo.Games().Create(&gameModel); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -118,9 +121,10 @@ func CreateGameHandler(c *gin.Context) {
 		MaxBots:             6,
 		TurnTimeoutSeconds:  30,
 		PauseOnDisconnect:   true,
+		Rated:               req.Rated == nil || *req.Rated,
 	}
 
-	if err := db.DB.Create(&settings).Error; err != nil {
+	if err := h.repo.Games().CreateSettings(&settings); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -136,21 +140,28 @@ func CreateGameHandler(c *gin.Context) {
 		LastSeenAt:    time.Now(),
 	}
 
-	if err := db.DB.Create(&gamePlayer).Error; err != nil {
+	if err := h.repo.Games().AddPlayer(&gamePlayer); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Log game creation
 	logMessage := fmt.Sprintf("Game created by %s", user.Name)
-	logAndSend(gameModel.ID, req.RequesterID, "game_event", logMessage)
+	h.logAndSend(gameModel.ID, req.RequesterID, "game_event", logMessage)
 	publishState(gameModel.ID)
+	webhook.Dispatch(h.repo, gameModel.ID, webhook.GameCreated, map[string]interface{}{
+		"requesterId":   user.ID,
+		"requesterName": user.Name,
+		"maxPlayers":    gameModel.MaxPlayers,
+		"minPlayers":    gameModel.MinPlayers,
+	})
 
 	c.JSON(http.StatusOK, gin.H{
 		"gameId":     gameModel.ID,
 		"status":     gameModel.Status,
 		"maxPlayers": gameModel.MaxPlayers,
 		"minPlayers": gameModel.MinPlayers,
+		"rated":      settings.Rated,
 	})
 }
 
@@ -161,7 +172,7 @@ type JoinGameRequest struct {
 }
 
 // JoinGameHandler adds a user to a game
-func JoinGameHandler(c *gin.Context) {
+func (h *Handlers) JoinGameHandler(c *gin.Context) {
 	var req JoinGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil || req.GameID == "" || req.UserID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid join request"})
@@ -169,15 +180,15 @@ func JoinGameHandler(c *gin.Context) {
 	}
 
 	// Validate user exists
-	var user model.User
-	if err := db.DB.First(&user, "id = ?", req.UserID).Error; err != nil {
+	user, err := h.repo.Users().Get(req.UserID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
 
 	// Validate game exists and can be joined
-	var gameModel model.Game
-	if err := db.DB.First(&gameModel, "id = ?", req.GameID).Error; err != nil {
+	gameModel, err := h.repo.Games().Get(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
 		return
 	}
@@ -188,20 +199,20 @@ func JoinGameHandler(c *gin.Context) {
 	}
 
 	// Check if already in game
-	var existing model.GamePlayer
-	if err := db.DB.Where("game_id = ? AND user_id = ?", req.GameID, req.UserID).First(&existing).Error; err == nil {
+	if _, err := h.repo.Games().Player(req.GameID, req.UserID); err == nil {
 		c.JSON(http.StatusOK, gin.H{"gameId": req.GameID, "status": "already_joined"})
 		return
 	}
 
 	// Check player count
-	var playerCount int64
-	if err := db.DB.Model(&model.GamePlayer{}).Where("game_id = ?", req.GameID).Count(&playerCount).Error; err != nil {
+	players, err := h.repo.Games().Players(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count players"})
 		return
 	}
+	playerCount := len(players)
 
-	if int(playerCount) >= gameModel.MaxPlayers {
+	if playerCount >= gameModel.MaxPlayers {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "game is full"})
 		return
 	}
@@ -210,31 +221,35 @@ func JoinGameHandler(c *gin.Context) {
 	gamePlayer := model.GamePlayer{
 		GameID:        req.GameID,
 		UserID:        req.UserID,
-		JoinOrder:     int(playerCount),
+		JoinOrder:     playerCount,
 		IsConnected:   true,
 		DonkeyLetters: "",
 		JoinedAt:      time.Now(),
 		LastSeenAt:    time.Now(),
 	}
 
-	if err := db.DB.Create(&gamePlayer).Error; err != nil {
+	if err := h.repo.Games().AddPlayer(&gamePlayer); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Log player join
 	logMessage := fmt.Sprintf("%s joined the game", user.Name)
-	logAndSend(req.GameID, req.UserID, "game_event", logMessage)
+	h.logAndSend(req.GameID, req.UserID, "game_event", logMessage)
+	webhook.Dispatch(h.repo, req.GameID, webhook.PlayerJoined, map[string]interface{}{
+		"playerId":   user.ID,
+		"playerName": user.Name,
+		"isBot":      false,
+	})
 
 	// Check if should auto-start
-	var settings model.GameSettings
-	if err := db.DB.First(&settings, "game_id = ?", req.GameID).Error; err == nil {
-		if settings.AutoStartAt8Players && int(playerCount)+1 >= 8 {
-			gm := game.NewGameManager(req.GameID)
+	if settings, err := h.repo.Games().Settings(req.GameID); err == nil {
+		if settings.AutoStartAt8Players && playerCount+1 >= 8 {
+			gm := game.NewGameManager(h.repo, req.GameID)
 			if err := gm.StartGame(); err != nil {
-				logAndSend(req.GameID, req.UserID, "game_event", "Failed to auto-start game: "+err.Error())
+				h.logAndSend(req.GameID, req.UserID, "game_event", "Failed to auto-start game: "+err.Error())
 			} else {
-				logAndSend(req.GameID, req.UserID, "game_event", "Game auto-started with 8 players!")
+				h.logAndSend(req.GameID, req.UserID, "game_event", "Game auto-started with 8 players!")
 			}
 		}
 	}
@@ -251,7 +266,7 @@ type AddBotRequest struct {
 }
 
 // AddBotHandler adds a bot player to the game
-func AddBotHandler(c *gin.Context) {
+func (h *Handlers) AddBotHandler(c *gin.Context) {
 	var req AddBotRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
@@ -259,8 +274,8 @@ func AddBotHandler(c *gin.Context) {
 	}
 
 	// Validate requester
-	var gameModel model.Game
-	if err := db.DB.First(&gameModel, "id = ?", req.GameID).Error; err != nil {
+	gameModel, err := h.repo.Games().Get(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
 		return
 	}
@@ -276,7 +291,7 @@ func AddBotHandler(c *gin.Context) {
 	}
 
 	// Add bot using game manager
-	gm := game.NewGameManager(req.GameID)
+	gm := game.NewGameManager(h.repo, req.GameID)
 	botUser, err := gm.AddBotPlayer(req.Difficulty)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -298,7 +313,7 @@ type StartGameRequest struct {
 }
 
 // StartGameHandler starts a game
-func StartGameHandler(c *gin.Context) {
+func (h *Handlers) StartGameHandler(c *gin.Context) {
 	var req StartGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
@@ -306,8 +321,8 @@ func StartGameHandler(c *gin.Context) {
 	}
 
 	// Validate requester
-	var gameModel model.Game
-	if err := db.DB.First(&gameModel, "id = ?", req.GameID).Error; err != nil {
+	gameModel, err := h.repo.Games().Get(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
 		return
 	}
@@ -323,19 +338,19 @@ func StartGameHandler(c *gin.Context) {
 	}
 
 	// Check minimum players
-	var playerCount int64
-	if err := db.DB.Model(&model.GamePlayer{}).Where("game_id = ?", req.GameID).Count(&playerCount).Error; err != nil {
+	players, err := h.repo.Games().Players(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count players"})
 		return
 	}
 
-	if int(playerCount) < gameModel.MinPlayers {
+	if len(players) < gameModel.MinPlayers {
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("need at least %d players", gameModel.MinPlayers)})
 		return
 	}
 
 	// Start game using game manager
-	gm := game.NewGameManager(req.GameID)
+	gm := game.NewGameManager(h.repo, req.GameID)
 	if err := gm.StartGame(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -353,7 +368,7 @@ type PlayCardRequest struct {
 }
 
 // PlayCardHandler handles a player playing a card
-func PlayCardHandler(c *gin.Context) {
+func (h *Handlers) PlayCardHandler(c *gin.Context) {
 	var req PlayCardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
@@ -361,8 +376,8 @@ func PlayCardHandler(c *gin.Context) {
 	}
 
 	// Validate game is active
-	var gameModel model.Game
-	if err := db.DB.First(&gameModel, "id = ?", req.GameID).Error; err != nil {
+	gameModel, err := h.repo.Games().Get(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
 		return
 	}
@@ -373,15 +388,20 @@ func PlayCardHandler(c *gin.Context) {
 	}
 
 	// Validate player is in game
-	var gamePlayer model.GamePlayer
-	if err := db.DB.Where("game_id = ? AND user_id = ?", req.GameID, req.UserID).First(&gamePlayer).Error; err != nil {
+	if _, err := h.repo.Games().Player(req.GameID, req.UserID); err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "player not in game"})
 		return
 	}
+	h.touchPlayer(req.GameID, req.UserID)
 
 	// Play card using game manager
-	gm := game.NewGameManager(req.GameID)
+	gm := game.NewGameManager(h.repo, req.GameID)
 	if err := gm.PlayCard(req.UserID, req.CardID); err != nil {
+		// Another play landed on the turn first
+		if errors.Is(err, store.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "turn has already moved on"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -390,8 +410,10 @@ func PlayCardHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "card_played"})
 }
 
-// GameStateHandler returns complete game state for a user
-func GameStateHandler(c *gin.Context) {
+// GameStateHandler returns complete game state for one of the game's players.
+// The response carries the game's state version as its ETag and honours
+// If-None-Match.
+func (h *Handlers) GameStateHandler(c *gin.Context) {
 	gameID := c.Param("gameId")
 	userID := c.Param("userId")
 
@@ -399,25 +421,45 @@ func GameStateHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing parameters"})
 		return
 	}
+	if !h.requirePlayer(c, gameID, userID) {
+		return
+	}
+	h.serveGameState(c, gameID, userID)
+}
+
+// serveGameState answers a request for the state of a game as seen by
+// userID, who holds no cards if they are not one of its players
+func (h *Handlers) serveGameState(c *gin.Context, gameID, userID string) {
+	// Answer unchanged state without touching the database
+	version := stateVersions.current(gameID)
+	etag := stateETag(version)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 
-	state, err := buildGameState(gameID, userID)
+	state, err := h.buildGameState(gameID, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	state.Version = version
+	state.Game.ID = shownGameID(c, state.Game.ID)
 
 	c.JSON(http.StatusOK, state)
 }
 
 // AdminStateHandler returns full game state for debugging
-func AdminStateHandler(c *gin.Context) {
+func (h *Handlers) AdminStateHandler(c *gin.Context) {
 	gameID := c.Param("gameId")
 	if gameID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing gameId"})
 		return
 	}
 
-	state, err := buildAdminGameState(gameID)
+	state, err := h.buildAdminGameState(gameID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -427,7 +469,7 @@ func AdminStateHandler(c *gin.Context) {
 }
 
 // GetGameListHandler returns list of games for a user
-func GetGameListHandler(c *gin.Context) {
+func (h *Handlers) GetGameListHandler(c *gin.Context) {
 	userID := c.Query("userId")
 	status := c.Query("status")
 
@@ -436,16 +478,8 @@ func GetGameListHandler(c *gin.Context) {
 		return
 	}
 
-	query := db.DB.Model(&model.Game{}).
-		Joins("JOIN game_players ON games.id = game_players.game_id").
-		Where("game_players.user_id = ?", userID)
-
-	if status != "" {
-		query = query.Where("games.status = ?", status)
-	}
-
-	var games []model.Game
-	if err := query.Order("games.created_at DESC").Find(&games).Error; err != nil {
+	games, err := h.repo.Games().ListForUser(userID, status)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -465,26 +499,26 @@ func GetGameListHandler(c *gin.Context) {
 	var result []GameListItem
 	for _, game := range games {
 		// Count players and bots
-		var totalPlayers, botPlayers int64
-		db.DB.Model(&model.GamePlayer{}).Where("game_id = ?", game.ID).Count(&totalPlayers)
-		db.DB.Table("game_players").
-			Joins("JOIN users ON game_players.user_id = users.id").
-			Where("game_players.game_id = ? AND users.is_bot = true", game.ID).
-			Count(&botPlayers)
+		players, _ := h.repo.Games().Players(game.ID)
+		botPlayers := 0
+		for _, gp := range players {
+			if gp.User.IsBot {
+				botPlayers++
+			}
+		}
 
 		// Get last activity
-		var lastLog model.GameSessionLog
 		lastActivity := game.CreatedAt
-		if err := db.DB.Where("game_id = ?", game.ID).Order("created_at DESC").First(&lastLog).Error; err == nil {
-			lastActivity = lastLog.CreatedAt
+		if logs, err := h.repo.Logs().Recent(game.ID, 1); err == nil && len(logs) > 0 {
+			lastActivity = logs[0].CreatedAt
 		}
 
 		item := GameListItem{
 			ID:           game.ID,
 			Status:       game.Status,
 			RequesterID:  game.RequesterID,
-			PlayerCount:  int(totalPlayers),
-			BotCount:     int(botPlayers),
+			PlayerCount:  len(players),
+			BotCount:     botPlayers,
 			CreatedAt:    game.CreatedAt,
 			StartedAt:    game.StartedAt,
 			CompletedAt:  game.CompletedAt,
@@ -503,7 +537,7 @@ type AbandonGameRequest struct {
 }
 
 // AbandonGameHandler abandons a game (only requester can do this)
-func AbandonGameHandler(c *gin.Context) {
+func (h *Handlers) AbandonGameHandler(c *gin.Context) {
 	var req AbandonGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
@@ -511,8 +545,8 @@ func AbandonGameHandler(c *gin.Context) {
 	}
 
 	// Validate requester
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", req.GameID).Error; err != nil {
+	game, err := h.repo.Games().Get(req.GameID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
 		return
 	}
@@ -532,13 +566,18 @@ func AbandonGameHandler(c *gin.Context) {
 	now := time.Now()
 	game.CompletedAt = &now
 
-	if err := db.DB.Save(&game).Error; err != nil {
+	if err := h.repo.Games().Save(game); err != nil {
+		// The game moved on, perhaps ending, since it was read
+		if errors.Is(err, store.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "game has changed, try again"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Log abandonment
-	logAndSend(req.GameID, req.UserID, "game_event", "Game abandoned by creator")
+	h.logAndSend(req.GameID, req.UserID, "game_event", "Game abandoned by creator")
 	publishState(req.GameID)
 
 	c.JSON(http.StatusOK, gin.H{"status": "abandoned"})
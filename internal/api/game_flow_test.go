@@ -65,7 +65,7 @@ func TestRegisterThenJoinWithGameID(t *testing.T) {
 	streamResp, _ := client.Do(req)
 	streamResp.Body.Close()
 
-	logsResp, _ := client.Get(ts.URL + "/api/game/" + g["gameId"] + "/logs")
+	logsResp, _ := client.Get(ts.URL + "/api/game/" + g["gameId"] + "/logs?userId=" + join["id"])
 	var logs []struct{ Message string }
 	json.NewDecoder(logsResp.Body).Decode(&logs)
 	found := false
@@ -175,7 +175,7 @@ func TestFinalizeDealsAndLogs(t *testing.T) {
 		assert.Equal(t, 26, len(state.Players[0].Cards))
 		assert.Equal(t, 0, len(state.Players[1].Cards))
 	}
-	logsResp, _ := client.Get(ts.URL + "/api/game/" + g["gameId"] + "/logs")
+	logsResp, _ := client.Get(ts.URL + "/api/game/" + g["gameId"] + "/logs?userId=" + u1["id"])
 	var logs []struct {
 		Message string `json:"message"`
 	}
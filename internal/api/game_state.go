@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/kairodrad/donkey/internal/db"
 	"github.com/kairodrad/donkey/internal/model"
 )
 
@@ -17,10 +16,11 @@ type GameStateResponse struct {
 	MyCards      []CardInfo       `json:"myCards,omitempty"`
 	InPlayCards  []PlayedCardInfo `json:"inPlayCards,omitempty"`
 	RecentLogs   []LogInfo        `json:"recentLogs"`
+	Version      uint64           `json:"version"`
 }
 
 type GameInfo struct {
-	ID          string     `json:"id"`
+	ID          string     `json:"id,omitempty"` // not through a share link
 	Status      string     `json:"status"`
 	RequesterID string     `json:"requesterId"`
 	MaxPlayers  int        `json:"maxPlayers"`
@@ -93,17 +93,29 @@ type LogInfo struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
-// buildGameState builds the complete game state response
-func buildGameState(gameID, userID string) (*GameStateResponse, error) {
+// buildGameState returns the complete game state as seen by userID. It is
+// served from the game's materialized view, so only the first request after
+// a change reads the store.
+func (h *Handlers) buildGameState(gameID, userID string) (*GameStateResponse, error) {
+	view, err := h.views.get(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return view.forViewer(userID), nil
+}
+
+// loadGameView reads a game from the store into a view at version. The
+// state it holds has no MyCards; every player's hand is kept aside in hands.
+func (h *Handlers) loadGameView(gameID string, version uint64) (*gameView, error) {
 	// Load game
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", gameID).Error; err != nil {
+	game, err := h.repo.Games().Get(gameID)
+	if err != nil {
 		return nil, fmt.Errorf("game not found: %w", err)
 	}
 
 	// Load game players
-	var gamePlayers []model.GamePlayer
-	if err := db.DB.Preload("User").Where("game_id = ?", gameID).Order("join_order").Find(&gamePlayers).Error; err != nil {
+	gamePlayers, err := h.repo.Games().Players(gameID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to load game players: %w", err)
 	}
 
@@ -135,24 +147,17 @@ func buildGameState(gameID, userID string) (*GameStateResponse, error) {
 			LoserID:     game.LoserID,
 		},
 		Players: players,
+		Version: version,
 	}
+	hands := make(map[string][]CardInfo)
 
 	// If game is active, load round and turn info
 	if game.Status == "active" {
 		// Load current round
-		var round model.Round
-		if err := db.DB.Where("game_id = ? AND status IN ('dealing', 'active')", gameID).
-			Order("round_number DESC").First(&round).Error; err == nil {
-
-			// Count discard pile
-			var discardCount int64
-			db.DB.Model(&model.Card{}).Where("round_id = ? AND location = 'discard'", round.ID).Count(&discardCount)
+		if round, err := h.repo.Rounds().Current(gameID); err == nil {
 
-			// Load actual discarded cards
-			var discardedCards []model.Card
-			db.DB.Where("round_id = ? AND location = 'discard'", round.ID).
-				Order("updated_at ASC"). // Order by when they were discarded
-				Find(&discardedCards)
+			// Load discarded cards in the order they were played
+			discardedCards, _ := h.repo.Cards().Discarded(round.ID)
 
 			// Convert to CardInfo
 			var discardPile []CardInfo
@@ -171,13 +176,13 @@ func buildGameState(gameID, userID string) (*GameStateResponse, error) {
 				StartedAt:    round.StartedAt,
 				CompletedAt:  round.CompletedAt,
 				LoserID:      round.LoserID,
-				DiscardCount: int(discardCount),
+				DiscardCount: len(discardedCards),
 				DiscardPile:  discardPile,
 			}
 
 			// Update player info with round data
-			var roundPlayers []model.RoundPlayer
-			if err := db.DB.Where("round_id = ?", round.ID).Find(&roundPlayers).Error; err == nil {
+			roundPlayers, err := h.repo.Rounds().Players(round.ID)
+			if err == nil {
 				roundPlayerMap := make(map[string]model.RoundPlayer)
 				for _, rp := range roundPlayers {
 					roundPlayerMap[rp.UserID] = rp
@@ -195,15 +200,10 @@ func buildGameState(gameID, userID string) (*GameStateResponse, error) {
             // Load latest turn for this round (active, cut, or completed). This ensures
             // we show the just-finished turn's in-play cards during the 3s pause,
             // and avoids accidentally showing an older CUT turn.
-            var turn model.Turn
-            if err := db.DB.Where("round_id = ?", round.ID).
-                Order("turn_number DESC").
-                Preload("PlayedCards.Card").
-                Preload("PlayedCards.Player").
-                First(&turn).Error; err == nil {
+            if turn, err := h.repo.Turns().Latest(round.ID); err == nil {
 
 				// Determine expected player
-				expectedPlayerID, _ := getExpectedPlayerIDForTurn(&turn)
+				expectedPlayerID, _ := getExpectedPlayerIDForTurn(turn, roundPlayers)
 
 				response.CurrentTurn = &TurnInfo{
 					ID:               turn.ID,
@@ -240,13 +240,9 @@ func buildGameState(gameID, userID string) (*GameStateResponse, error) {
 				response.InPlayCards = inPlayCards
 			}
 
-			// Load user's cards
-			var userCards []model.Card
-			if err := db.DB.Where("round_id = ? AND owner_id = ? AND location = 'hand'", round.ID, userID).
-				Order("sort_order").Find(&userCards).Error; err == nil {
-
-				var myCards []CardInfo
-				for _, card := range userCards {
+			// Load every player's hand; each viewer only ever gets their own
+			if heldCards, err := h.repo.Cards().InHand(round.ID); err == nil {
+				for _, card := range heldCards {
 					cardInfo := CardInfo{
 						ID:        card.ID,
 						Suit:      card.Suit,
@@ -255,16 +251,14 @@ func buildGameState(gameID, userID string) (*GameStateResponse, error) {
 						SortOrder: card.SortOrder,
 						Code:      card.CardCode(),
 					}
-					myCards = append(myCards, cardInfo)
+					hands[*card.OwnerID] = append(hands[*card.OwnerID], cardInfo)
 				}
-				response.MyCards = myCards
 			}
 		}
 	}
 
 	// Load recent logs
-	var logs []model.GameSessionLog
-	if err := db.DB.Where("game_id = ?", gameID).Order("created_at DESC").Limit(20).Find(&logs).Error; err == nil {
+	if logs, err := h.repo.Logs().Recent(gameID, 20); err == nil {
 		var recentLogs []LogInfo
 		for _, log := range logs {
 			logInfo := LogInfo{
@@ -279,13 +273,13 @@ func buildGameState(gameID, userID string) (*GameStateResponse, error) {
 		response.RecentLogs = recentLogs
 	}
 
-	return response, nil
+	return &gameView{version: version, state: *response, hands: hands}, nil
 }
 
 // buildAdminGameState builds the admin game state with all player cards visible
-func buildAdminGameState(gameID string) (*GameStateResponse, error) {
+func (h *Handlers) buildAdminGameState(gameID string) (*GameStateResponse, error) {
 	// Build regular state first
-	state, err := buildGameState(gameID, "")
+	state, err := h.buildGameState(gameID, "")
 	if err != nil {
 		return nil, err
 	}
@@ -295,9 +289,7 @@ func buildAdminGameState(gameID string) (*GameStateResponse, error) {
 		roundID := state.CurrentRound.ID
 
 		// Load all players' cards
-		var allCards []model.Card
-		if err := db.DB.Where("round_id = ? AND location = 'hand'", roundID).
-			Order("owner_id, sort_order").Find(&allCards).Error; err == nil {
+		if allCards, err := h.repo.Cards().InHand(roundID); err == nil {
 
 			// Group cards by player
 			playerCards := make(map[string][]model.Card)
@@ -338,15 +330,9 @@ func buildAdminGameState(gameID string) (*GameStateResponse, error) {
 	return state, nil
 }
 
-// Helper function to determine expected player for a turn (duplicated from enhanced_game.go, should be consolidated)
-func getExpectedPlayerIDForTurn(turn *model.Turn) (string, error) {
-    // Load all players in this round to maintain seating order
-    var allPlayers []model.RoundPlayer
-    if err := db.DB.Where("round_id = ?", turn.RoundID).
-        Order("position asc").Find(&allPlayers).Error; err != nil {
-        return "", fmt.Errorf("failed to load round players: %w", err)
-    }
-
+// Helper function to determine expected player for a turn from the round's
+// players in seating order (duplicated from enhanced_game.go, should be consolidated)
+func getExpectedPlayerIDForTurn(turn *model.Turn, allPlayers []model.RoundPlayer) (string, error) {
     if len(allPlayers) == 0 {
         return "", fmt.Errorf("no players in round")
     }
@@ -0,0 +1,212 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameStateHonoursIfNoneMatch(t *testing.T) {
+	ts := httptest.NewServer(server.New())
+	defer ts.Close()
+	client := ts.Client()
+	resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"Host"}`))
+	var host map[string]string
+	json.NewDecoder(resp.Body).Decode(&host)
+	resp, _ = client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host["id"]+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+	stateURL := ts.URL + "/api/game/" + gameID + "/state/" + host["id"]
+
+	first, _ := client.Get(stateURL)
+	etag := first.Header.Get("ETag")
+	if !assert.NotEmpty(t, etag) {
+		return
+	}
+
+	req, _ := http.NewRequest("GET", stateURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	unchanged, _ := client.Do(req)
+	assert.Equal(t, http.StatusNotModified, unchanged.StatusCode)
+
+	// A private event leaves the shared state alone
+	api.PublishPrivate(gameID, host["id"], "your_turn", nil)
+	req, _ = http.NewRequest("GET", stateURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	unchanged, _ = client.Do(req)
+	assert.Equal(t, http.StatusNotModified, unchanged.StatusCode)
+
+	client.Post(ts.URL+"/api/game/chat", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+host["id"]+`","message":"hi"}`))
+
+	req, _ = http.NewRequest("GET", stateURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	changed, _ := client.Do(req)
+	assert.Equal(t, http.StatusOK, changed.StatusCode)
+	assert.NotEqual(t, etag, changed.Header.Get("ETag"))
+}
+
+func TestGamePlaysOnItsOwnMemoryStore(t *testing.T) {
+	ts := httptest.NewServer(server.NewWithStore(store.NewMemory()))
+	defer ts.Close()
+	client := ts.Client()
+	resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"Host"}`))
+	var host map[string]string
+	json.NewDecoder(resp.Body).Decode(&host)
+	resp, _ = client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host["id"]+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+	resp, _ = client.Post(ts.URL+"/api/game/add-bot", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+host["id"]+`","difficulty":"easy"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = client.Post(ts.URL+"/api/game/start", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+host["id"]+`"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = client.Get(ts.URL + "/api/game/" + gameID + "/state/" + host["id"])
+	var state struct {
+		Game struct {
+			Status string `json:"status"`
+		} `json:"game"`
+		CurrentRound *struct {
+			RoundNumber int `json:"roundNumber"`
+		} `json:"currentRound"`
+		MyCards []struct{} `json:"myCards"`
+	}
+	json.NewDecoder(resp.Body).Decode(&state)
+	assert.Equal(t, "active", state.Game.Status)
+	if assert.NotNil(t, state.CurrentRound) {
+		assert.Equal(t, 1, state.CurrentRound.RoundNumber)
+	}
+	assert.Len(t, state.MyCards, 26)
+
+	// Nothing from other tests' servers is visible
+	resp, _ = client.Get(ts.URL + "/api/users")
+	var users []map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&users)
+	assert.Len(t, users, 2)
+}
+
+func TestServersInOneProcessKeepTheirOwnStores(t *testing.T) {
+	first, second := store.NewMemory(), store.NewMemory()
+	tsFirst := httptest.NewServer(server.NewWithStore(first))
+	defer tsFirst.Close()
+	tsSecond := httptest.NewServer(server.NewWithStore(second))
+	defer tsSecond.Close()
+	require.NoError(t, first.Users().Create(&model.User{ID: model.NewID(), Name: "Ann", CreatedAt: time.Now()}))
+	users, err := first.Users().List()
+	require.NoError(t, err)
+	ann := users[0].ID
+
+	resp, err := tsFirst.Client().Get(tsFirst.URL + "/api/user/" + ann)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, err = tsSecond.Client().Get(tsSecond.URL + "/api/user/" + ann)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "the second server has a store of its own")
+
+	// Webhooks live in the server's store too
+	resp, err = tsFirst.Client().Post(tsFirst.URL+"/api/webhooks", "application/json",
+		bytes.NewBufferString(`{"userId":"`+ann+`","url":"https://example.com/hook"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	hooks, err := first.Webhooks().ListForOwner(ann)
+	require.NoError(t, err)
+	assert.Len(t, hooks, 1)
+	hooks, err = second.Webhooks().ListForOwner(ann)
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+}
+
+// countingStore counts the game lookups made through it
+type countingStore struct {
+	store.Store
+	gets *int64
+}
+
+func (s countingStore) Games() store.Games { return countingGames{s.Store.Games(), s.gets} }
+func (s countingStore) Archives() store.Archives {
+	return countingArchives{s.Store.Archives(), s.gets}
+}
+
+type countingGames struct {
+	store.Games
+	gets *int64
+}
+
+func (g countingGames) Get(id string) (*model.Game, error) {
+	atomic.AddInt64(g.gets, 1)
+	return g.Games.Get(id)
+}
+
+type countingArchives struct {
+	store.Archives
+	gets *int64
+}
+
+func (a countingArchives) Get(gameID string) (*model.GameArchive, error) {
+	atomic.AddInt64(a.gets, 1)
+	return a.Archives.Get(gameID)
+}
+
+func TestGameStateIsServedFromViewWithOwnHandOnly(t *testing.T) {
+	var gets int64
+	ts := httptest.NewServer(server.NewWithStore(countingStore{store.NewMemory(), &gets}))
+	defer ts.Close()
+	client := ts.Client()
+	reg := func(name string) string {
+		resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"`+name+`"}`))
+		var u map[string]string
+		json.NewDecoder(resp.Body).Decode(&u)
+		return u["id"]
+	}
+	host, guest := reg("Host"), reg("Guest")
+	resp, _ := client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID := g["gameId"].(string)
+	client.Post(ts.URL+"/api/game/join", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+guest+`"}`))
+	resp, _ = client.Post(ts.URL+"/api/game/start", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+host+`"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	// Let the first turn settle; only a human can lead the Ace of Spades
+	time.Sleep(200 * time.Millisecond)
+
+	hand := func(userID string) map[string]bool {
+		resp, _ := client.Get(ts.URL + "/api/game/" + gameID + "/state/" + userID)
+		var state struct {
+			MyCards []struct {
+				ID string `json:"id"`
+			} `json:"myCards"`
+		}
+		json.NewDecoder(resp.Body).Decode(&state)
+		ids := make(map[string]bool)
+		for _, c := range state.MyCards {
+			ids[c.ID] = true
+		}
+		return ids
+	}
+
+	atomic.StoreInt64(&gets, 0)
+	hostHand, guestHand := hand(host), hand(guest)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, hostHand, hand(host))
+		assert.Equal(t, guestHand, hand(guest))
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&gets), "eight requests share one load")
+
+	assert.Len(t, hostHand, 26)
+	assert.Len(t, guestHand, 26)
+	for id := range hostHand {
+		assert.False(t, guestHand[id], "a player never sees another player's cards")
+	}
+}
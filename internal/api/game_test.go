@@ -0,0 +1,179 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTwoPlayerGame starts a game between two humans and returns the game,
+// the player holding the Ace of Spades and that card's ID
+func startTwoPlayerGame(t *testing.T, ts *httptest.Server) (gameID, leader, aceID string) {
+	t.Helper()
+	client := ts.Client()
+	reg := func(name string) string {
+		resp, _ := client.Post(ts.URL+"/api/register", "application/json", bytes.NewBufferString(`{"name":"`+name+`"}`))
+		var u map[string]string
+		json.NewDecoder(resp.Body).Decode(&u)
+		return u["id"]
+	}
+	host, guest := reg("Host"), reg("Guest")
+	resp, _ := client.Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(`{"requesterId":"`+host+`"}`))
+	var g map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&g)
+	gameID = g["gameId"].(string)
+	client.Post(ts.URL+"/api/game/join", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+guest+`"}`))
+	resp, _ = client.Post(ts.URL+"/api/game/start", "application/json", bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+host+`"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	time.Sleep(200 * time.Millisecond)
+
+	for _, userID := range []string{host, guest} {
+		resp, _ := client.Get(ts.URL + "/api/game/" + gameID + "/state/" + userID)
+		var state struct {
+			MyCards []struct {
+				ID   string `json:"id"`
+				Suit string `json:"suit"`
+				Rank string `json:"rank"`
+			} `json:"myCards"`
+		}
+		json.NewDecoder(resp.Body).Decode(&state)
+		for _, c := range state.MyCards {
+			if c.Suit == "spades" && c.Rank == "A" {
+				return gameID, userID, c.ID
+			}
+		}
+	}
+	t.Fatal("nobody holds the Ace of Spades")
+	return
+}
+
+func playCard(ts *httptest.Server, gameID, userID, cardID string) int {
+	resp, err := ts.Client().Post(ts.URL+"/api/game/play-card", "application/json",
+		bytes.NewBufferString(`{"gameId":"`+gameID+`","userId":"`+userID+`","cardId":"`+cardID+`"}`))
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// interferingStore lets another writer save a turn just before the next
+// turn save, the way a second server instance might
+type interferingStore struct {
+	store.Store
+	armed *int32
+}
+
+func (s interferingStore) Turns() store.Turns {
+	return interferingTurns{s.Store.Turns(), s.armed}
+}
+
+func (s interferingStore) Transaction(fn func(tx store.Store) error) error {
+	return s.Store.Transaction(func(tx store.Store) error {
+		return fn(interferingStore{tx, s.armed})
+	})
+}
+
+type interferingTurns struct {
+	store.Turns
+	armed *int32
+}
+
+func (r interferingTurns) Save(turn *model.Turn) error {
+	if atomic.CompareAndSwapInt32(r.armed, 1, 0) {
+		other, err := r.Turns.Get(turn.ID)
+		if err != nil {
+			return err
+		}
+		if err := r.Turns.Save(other); err != nil {
+			return err
+		}
+	}
+	return r.Turns.Save(turn)
+}
+
+func TestPlayCardOnStaleTurnConflicts(t *testing.T) {
+	var armed int32
+	ts := httptest.NewServer(server.NewWithStore(interferingStore{store.NewMemory(), &armed}))
+	defer ts.Close()
+	gameID, leader, aceID := startTwoPlayerGame(t, ts)
+
+	atomic.StoreInt32(&armed, 1)
+	assert.Equal(t, http.StatusConflict, playCard(ts, gameID, leader, aceID))
+	assert.Equal(t, http.StatusOK, playCard(ts, gameID, leader, aceID), "a retry on the fresh turn goes through")
+}
+
+func TestRacingPlaysLandOnce(t *testing.T) {
+	ts := httptest.NewServer(server.NewWithStore(store.NewMemory()))
+	defer ts.Close()
+	gameID, leader, aceID := startTwoPlayerGame(t, ts)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = playCard(ts, gameID, leader, aceID)
+		}(i)
+	}
+	wg.Wait()
+
+	played := 0
+	for _, code := range codes {
+		if code == http.StatusOK {
+			played++
+			continue
+		}
+		assert.Contains(t, []int{http.StatusConflict, http.StatusBadRequest}, code)
+	}
+	assert.Equal(t, 1, played, "exactly one of the racing plays lands")
+
+	resp, _ := ts.Client().Get(ts.URL + "/api/game/" + gameID + "/state/" + leader)
+	var state struct {
+		InPlayCards []struct{} `json:"inPlayCards"`
+	}
+	json.NewDecoder(resp.Body).Decode(&state)
+	assert.Len(t, state.InPlayCards, 1)
+}
+
+// staleStore reads every game as it was one save ago
+type staleStore struct{ store.Store }
+
+func (s staleStore) Games() store.Games { return staleGames{s.Store.Games()} }
+
+type staleGames struct{ store.Games }
+
+func (g staleGames) Get(id string) (*model.Game, error) {
+	game, err := g.Games.Get(id)
+	if err == nil {
+		game.Version--
+	}
+	return game, err
+}
+
+func TestAbandonOnStaleGameConflicts(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(staleStore{repo}))
+	defer ts.Close()
+	require.NoError(t, repo.Games().Create(&model.Game{ID: "g1", RequesterID: "ann", Status: "waiting", CreatedAt: time.Now()}))
+
+	resp, err := ts.Client().Post(ts.URL+"/api/game/abandon", "application/json", bytes.NewBufferString(`{"gameId":"g1","userId":"ann"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	game, err := repo.Games().Get("g1")
+	require.NoError(t, err)
+	assert.Equal(t, "waiting", game.Status)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GameHistoryHandler returns the full history of a finished game, whether it
+// has been archived or not.
+//
+// @Summary      Game history
+// @Description  Players, deals, tricks and letters of every round of a completed game
+// @Tags         game
+// @Produce      json
+// @Param        gameId  path   string  true  "Game ID"
+// @Param        userId  query  string  true  "Player asking"
+// @Success      200  {object}  archive.Record
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/game/{gameId}/history [get]
+func (h *Handlers) GameHistoryHandler(c *gin.Context) {
+	record, ok := h.loadFinishedGame(c, c.Param("gameId"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
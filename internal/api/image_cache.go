@@ -0,0 +1,53 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// imageTTL is how long a rendered image nobody asks for is kept.
+const imageTTL = time.Hour
+
+// imageCache keeps images rendered from finished games, which never change,
+// so each is drawn and encoded once however often it is asked for
+type imageCache struct {
+	mu     sync.Mutex
+	images map[string]*imageEntry
+}
+
+type imageEntry struct {
+	data   []byte
+	usedAt time.Time
+}
+
+func newImageCache() *imageCache {
+	return &imageCache{images: make(map[string]*imageEntry)}
+}
+
+func (ic *imageCache) get(key string) ([]byte, bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	entry := ic.images[key]
+	if entry == nil {
+		return nil, false
+	}
+	entry.usedAt = time.Now()
+	return entry.data, true
+}
+
+func (ic *imageCache) put(key string, data []byte) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	ic.images[key] = &imageEntry{data: data, usedAt: time.Now()}
+}
+
+// prune forgets the images not requested since cutoff.
+func (ic *imageCache) prune(cutoff time.Time) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	for key, entry := range ic.images {
+		if entry.usedAt.Before(cutoff) {
+			delete(ic.images, key)
+		}
+	}
+}
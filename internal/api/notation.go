@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/notation"
+)
+
+// maxNotationSize bounds an imported game; a long game is a few hundred KB
+const maxNotationSize = 2 << 20
+
+// GameNotationHandler exports a finished game in Donkey game notation.
+//
+// @Summary      Export game notation
+// @Description  A finished game as plain-text notation that can be shared and imported
+// @Tags         game
+// @Produce      plain
+// @Param        gameId  path   string  true  "Game ID"
+// @Param        userId  query  string  true  "Player asking"
+// @Success      200  {string}  string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/game/{gameId}/notation [get]
+func (h *Handlers) GameNotationHandler(c *gin.Context) {
+	record, ok := h.loadFinishedGame(c, c.Param("gameId"))
+	if !ok {
+		return
+	}
+	g, err := notation.FromRecord(record)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+record.GameID+`.donkey"`)
+	c.Status(http.StatusOK)
+	if err := notation.Write(c.Writer, g); err != nil {
+		c.Error(err)
+	}
+}
+
+// ImportNotationHandler imports a game written in Donkey game notation as a
+// read-only game that can be replayed.
+//
+// @Summary      Import game notation
+// @Description  Checks a game in notation and stores it for replay under a new game ID
+// @Tags         game
+// @Accept       plain
+// @Produce      json
+// @Success      201  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Router       /api/notation/import [post]
+func (h *Handlers) ImportNotationHandler(c *gin.Context) {
+	g, err := notation.Parse(http.MaxBytesReader(c.Writer, c.Request.Body, maxNotationSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "game is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := notation.Check(g); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	gameID, err := notation.Import(h.repo, g)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"gameId": gameID})
+}
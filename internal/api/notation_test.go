@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+const notatedGame = `[Game "g1"]
+[Ruleset "standard"]
+[Date "2026-10-18T20:15:00Z"]
+[Player "ann" "Ann"]
+[Player "bob" "Bob"]
+[Loser "ann"]
+
+Round 1
+[Seats "bob ann"]
+[Hand "bob" "2D 3D 4D 5D 6D 7D 8D 9D 10D JD QD KD AD 2C 3C 4C 5C 6C 7C 8C 9C 10C JC QC KC AC"]
+[Hand "ann" "2S 3S 4S 5S 6S 7S 8S 9S 10S JS QS KS AS 2H 3H 4H 5H 6H 7H 8H 9H 10H JH QH KH AH"]
+1. AS 2D*
+[Letter "ann" "D"]
+`
+
+func postNotation(t *testing.T, ts *httptest.Server, body string) (int, string) {
+	t.Helper()
+	resp, err := ts.Client().Post(ts.URL+"/api/notation/import", "text/plain", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+func TestNotationImportAndExport(t *testing.T) {
+	ts := httptest.NewServer(server.NewWithStore(store.NewMemory()))
+	defer ts.Close()
+
+	status, body := postNotation(t, ts, notatedGame)
+	require.Equal(t, http.StatusCreated, status, body)
+	gameID := strings.Split(body, `"`)[3]
+
+	// The imported game replays like any finished game
+	var replay api.ReplayResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/game/"+gameID+"/replay?userId=ann", &replay))
+	require.Len(t, replay.Rounds, 1)
+	assert.Equal(t, "cut", replay.Rounds[0].Tricks[0].Outcome)
+
+	resp, err := ts.Client().Get(ts.URL + "/api/game/" + gameID + "/notation?userId=bob")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	exported, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, strings.Replace(notatedGame, `"g1"`, `"`+gameID+`"`, 1), string(exported))
+
+	status, body = postNotation(t, ts, strings.Replace(notatedGame, "2D*", "2D", 1))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "not marked")
+	status, _ = postNotation(t, ts, "[Ruleset \"other\"]")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// historySize is how many recent events are kept per game for long polling.
+	historySize = 256
+	// historyTTL is how long a quiet game's event history is kept.
+	historyTTL = time.Hour
+	// defaultPollTimeout is how long a poll waits for new events by default.
+	defaultPollTimeout = 25 * time.Second
+	// maxPollTimeout caps the timeout a client may ask for.
+	maxPollTimeout = 60 * time.Second
+)
+
+// eventHistory is a bounded, ordered log of a game's recent events.
+type eventHistory struct {
+	events  []event
+	trimmed uint64 // version of the newest event dropped from the log
+}
+
+// record appends an event to the game's history. Callers hold br.mu.
+func (br *broker) record(gameID string, ev event) {
+	h := br.history[gameID]
+	if h == nil {
+		h = &eventHistory{}
+		br.history[gameID] = h
+	}
+	h.events = append(h.events, ev)
+	if over := len(h.events) - historySize; over > 0 {
+		h.trimmed = h.events[over-1].Version
+		h.events = append([]event(nil), h.events[over:]...)
+	}
+}
+
+// since returns the events after seq that userID may see, the cursor to poll
+// from next and whether events were lost because seq is older than the
+// retained history (the client should refetch the full state).
+func (br *broker) since(gameID, userID string, seq uint64) ([]event, uint64, bool) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	h := br.history[gameID]
+	if h == nil {
+		return nil, seq, false
+	}
+	cursor := seq
+	var events []event
+	for _, ev := range h.events {
+		if ev.Version <= seq {
+			continue
+		}
+		cursor = ev.Version
+		if ev.to != "" && ev.to != userID {
+			continue
+		}
+		events = append(events, ev)
+	}
+	return events, cursor, seq < h.trimmed
+}
+
+// pruneHistory drops the history of games with no events since cutoff.
+func (br *broker) pruneHistory(cutoff time.Time) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	for gameID, h := range br.history {
+		if len(h.events) == 0 || h.events[len(h.events)-1].at.Before(cutoff) {
+			delete(br.history, gameID)
+		}
+	}
+}
+
+// PollHandler is a long-poll fallback for clients whose network buffers the
+// event stream. It returns the events published after `since`, waiting until
+// at least one exists or the timeout passes. Event versions are the same
+// sequence numbers carried on stream events, so a client can switch between
+// the stream and polling without missing anything. A client that keeps
+// polling is connected to the game just like an open stream; it is
+// disconnected once it stops polling for longer than its timeout. Only the
+// game's players may poll, as the events include their private ones.
+//
+// @Summary      Long-poll game updates
+// @Description  Blocks until events newer than `since` exist or the timeout passes. `resync` is set when older events were dropped and the full state must be refetched.
+// @Tags         events
+// @Produce      json
+// @Param        gameId   path   string  true   "Game ID"
+// @Param        userId   query  string  true   "User ID"
+// @Param        since    query  int     false  "Last seen event version"
+// @Param        timeout  query  int     false  "Seconds to wait (default 25, max 60)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /api/game/{gameId}/poll [get]
+func (h *Handlers) PollHandler(c *gin.Context) {
+	gameID := c.Param("gameId")
+	userID := c.Query("userId")
+	if gameID == "" || userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing parameters"})
+		return
+	}
+	if !h.requirePlayer(c, gameID, userID) {
+		return
+	}
+	since, err := strconv.ParseUint(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since"})
+		return
+	}
+	timeout := defaultPollTimeout
+	if raw := c.Query("timeout"); raw != "" {
+		secs, err := strconv.Atoi(raw)
+		if err != nil || secs < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timeout"})
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+		if timeout > maxPollTimeout {
+			timeout = maxPollTimeout
+		}
+	}
+
+	if opened, first := h.presence.lease(gameID, userID, time.Now().Add(timeout+pollLeaseGrace)); opened {
+		h.connectPlayer(gameID, userID, first)
+		h.touchPlayer(gameID, userID)
+	}
+
+	// Subscribe before reading history so nothing published in between is missed
+	ch := b.subscribe(gameID, userID)
+	defer b.unsubscribe(gameID, ch)
+
+	events, cursor, resync := b.since(gameID, userID, since)
+	if len(events) == 0 && !resync {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-ch:
+			events, cursor, resync = b.since(gameID, userID, since)
+		case <-timer.C:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+
+	if events == nil {
+		events = []event{}
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "cursor": cursor, "resync": resync})
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// heartbeatInterval is how often an open stream receives a keep-alive event.
+	heartbeatInterval = 15 * time.Second
+	// idleTimeout is how long a player may go without activity before they
+	// are reported as away (see GAME.md: 5 minutes idle).
+	idleTimeout = 5 * time.Minute
+	// idleSweepInterval is how often idle players and lapsed polls are
+	// checked for.
+	idleSweepInterval = 30 * time.Second
+	// pollLeaseGrace is how long past a poll's timeout a polling client still
+	// counts as connected without polling again.
+	pollLeaseGrace = 15 * time.Second
+	// reconnectGrace is how long a player who lost their last connection has
+	// to come back before they are reported gone, so that reloading the page
+	// or switching between the stream and polling is not leaving the game.
+	reconnectGrace = 15 * time.Second
+)
+
+// presenceEvent describes a change in a player's presence at the table.
+type presenceEvent struct {
+	UserID string `json:"userId"`
+	Status string `json:"status"` // "away", "back"
+}
+
+// presenceTracker reference-counts the connections of each game's users, so
+// that a player with several tabs open is only marked disconnected once the
+// last one closes. An open stream is a connection, and so is a client that
+// keeps polling: its first poll opens a lease that each later poll extends.
+// The tracker also remembers who has been announced at the table and who has
+// been reported away.
+type presenceTracker struct {
+	mu      sync.Mutex
+	conns   map[string]map[string]int       // gameID -> userID -> open streams and poll leases
+	leases  map[string]map[string]time.Time // gameID -> userID -> when the poll lease runs out
+	present map[string]map[string]bool      // gameID -> userID -> announced as connected
+	away    map[string]map[string]bool      // gameID -> userID -> reported away
+}
+
+// gameUser identifies a user at one game's table
+type gameUser struct{ gameID, userID string }
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{
+		conns:   make(map[string]map[string]int),
+		leases:  make(map[string]map[string]time.Time),
+		present: make(map[string]map[string]bool),
+		away:    make(map[string]map[string]bool),
+	}
+}
+
+// connect registers a new stream and reports whether it is the user's first
+// connection.
+func (p *presenceTracker) connect(gameID, userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connectLocked(gameID, userID)
+}
+
+func (p *presenceTracker) connectLocked(gameID, userID string) bool {
+	if p.conns[gameID] == nil {
+		p.conns[gameID] = make(map[string]int)
+	}
+	p.conns[gameID][userID]++
+	return p.conns[gameID][userID] == 1
+}
+
+// disconnect releases a stream and reports whether it was the user's last
+// connection.
+func (p *presenceTracker) disconnect(gameID, userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.disconnectLocked(gameID, userID)
+}
+
+func (p *presenceTracker) disconnectLocked(gameID, userID string) bool {
+	m := p.conns[gameID]
+	if m == nil || m[userID] == 0 {
+		return false
+	}
+	m[userID]--
+	if m[userID] > 0 {
+		return false
+	}
+	delete(m, userID)
+	if len(m) == 0 {
+		delete(p.conns, gameID)
+	}
+	return true
+}
+
+// lease opens or extends a user's poll lease until the given time. A new
+// lease is a connection; lease reports whether it opened one and whether
+// that was the user's first.
+func (p *presenceTracker) lease(gameID, userID string, until time.Time) (opened, first bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if expires, ok := p.leases[gameID][userID]; ok {
+		if until.After(expires) {
+			p.leases[gameID][userID] = until
+		}
+		return false, false
+	}
+	if p.leases[gameID] == nil {
+		p.leases[gameID] = make(map[string]time.Time)
+	}
+	p.leases[gameID][userID] = until
+	return true, p.connectLocked(gameID, userID)
+}
+
+// expireLeases closes the poll leases that ran out before now and returns
+// the users they left without any connection.
+func (p *presenceTracker) expireLeases(now time.Time) []gameUser {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var gone []gameUser
+	for gameID, leases := range p.leases {
+		for userID, expires := range leases {
+			if !expires.Before(now) {
+				continue
+			}
+			delete(leases, userID)
+			if p.disconnectLocked(gameID, userID) {
+				gone = append(gone, gameUser{gameID, userID})
+			}
+		}
+		if len(leases) == 0 {
+			delete(p.leases, gameID)
+		}
+	}
+	return gone
+}
+
+// arrive records that a user has been announced at the table and reports
+// whether they were not already.
+func (p *presenceTracker) arrive(gameID, userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.present[gameID][userID] {
+		return false
+	}
+	if p.present[gameID] == nil {
+		p.present[gameID] = make(map[string]bool)
+	}
+	p.present[gameID][userID] = true
+	return true
+}
+
+// leave reports whether a user announced at the table has no connection
+// left, and if so forgets them, so that they are announced gone only once.
+func (p *presenceTracker) leave(gameID, userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns[gameID][userID] > 0 || !p.present[gameID][userID] {
+		return false
+	}
+	delete(p.present[gameID], userID)
+	if len(p.present[gameID]) == 0 {
+		delete(p.present, gameID)
+	}
+	return true
+}
+
+// setAway records the away flag and reports whether it changed.
+func (p *presenceTracker) setAway(gameID, userID string, away bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.away[gameID][userID] == away {
+		return false
+	}
+	if away {
+		if p.away[gameID] == nil {
+			p.away[gameID] = make(map[string]bool)
+		}
+		p.away[gameID][userID] = true
+		return true
+	}
+	delete(p.away[gameID], userID)
+	if len(p.away[gameID]) == 0 {
+		delete(p.away, gameID)
+	}
+	return true
+}
+
+// publishPresence broadcasts a presence change to everyone at the table.
+func publishPresence(gameID, userID, status string) {
+	b.publish(gameID, event{Type: "presence", Presence: &presenceEvent{UserID: userID, Status: status}})
+}
+
+// touchPlayer records activity for a player: it refreshes LastSeenAt and, if
+// the player had been reported away, announces that they are back. The new
+// LastSeenAt shows in the game state with its next change.
+func (h *Handlers) touchPlayer(gameID, userID string) {
+	h.repo.Games().Touch(gameID, userID, time.Now())
+	if h.presence.setAway(gameID, userID, false) {
+		publishPresence(gameID, userID, "back")
+	}
+}
+
+// connectPlayer handles a new stream or poll lease of a player. Their first
+// connection marks them connected, and announces them unless they had never
+// been reported gone.
+func (h *Handlers) connectPlayer(gameID, userID string, first bool) {
+	if !first {
+		return
+	}
+	h.repo.Games().SetConnected(gameID, userID, true)
+	if h.presence.arrive(gameID, userID) {
+		h.logAndSend(gameID, userID, "status", h.userOrEmpty(userID).Name+": connected to the game")
+	}
+}
+
+// disconnectPlayer handles a player losing their last connection. They are
+// marked disconnected at once but only reported gone once reconnectGrace has
+// passed without them coming back.
+func (h *Handlers) disconnectPlayer(gameID, userID string) {
+	h.repo.Games().SetConnected(gameID, userID, false)
+	publishState(gameID)
+	time.AfterFunc(h.reconnectGrace, func() { h.leaveIfGone(gameID, userID) })
+}
+
+// leaveIfGone reports a player who still has no connection as gone and away.
+// A running game whose creator is gone is abandoned.
+func (h *Handlers) leaveIfGone(gameID, userID string) {
+	if !h.presence.leave(gameID, userID) {
+		return
+	}
+	h.logAndSend(gameID, userID, "status", h.userOrEmpty(userID).Name+": disconnected from the game")
+	h.markAway(gameID, userID)
+	g, err := h.repo.Games().Get(gameID)
+	if err != nil || g.RequesterID != userID || g.Status == "completed" || g.Status == "abandoned" {
+		return
+	}
+	h.repo.Games().SetStatus(gameID, "abandoned")
+	h.logAndSend(gameID, userID, "status", "Game was terminated because the creator disconnected")
+	publishState(gameID)
+}
+
+// markAway announces that a player has gone away, once.
+func (h *Handlers) markAway(gameID, userID string) {
+	if h.presence.setAway(gameID, userID, true) {
+		publishPresence(gameID, userID, "away")
+	}
+}
+
+// StartPresenceMonitor starts the background sweep that disconnects clients
+// that stopped polling, reports players who have been idle longer than
+// idleTimeout as away and forgets the long-poll history, state views and
+// scoreboards of quiet games. It is safe to call more than once.
+func (h *Handlers) StartPresenceMonitor() {
+	h.startPresenceOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(idleSweepInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				h.expirePolls(now)
+				h.sweepIdlePlayers(now)
+				b.pruneHistory(now.Add(-historyTTL))
+				h.views.prune(now.Add(-viewTTL))
+				h.scoreboards.prune(now.Add(-imageTTL))
+				h.animations.prune(now.Add(-imageTTL))
+			}
+		}()
+	})
+}
+
+// expirePolls disconnects the players whose poll lease ran out before now
+// and who have no other connection.
+func (h *Handlers) expirePolls(now time.Time) {
+	for _, gone := range h.presence.expireLeases(now) {
+		h.disconnectPlayer(gone.gameID, gone.userID)
+	}
+}
+
+// sweepIdlePlayers marks human players in running games as away when they
+// have not been seen since idleTimeout before now.
+func (h *Handlers) sweepIdlePlayers(now time.Time) {
+	idle, err := h.repo.Games().IdlePlayers(now.Add(-idleTimeout))
+	if err != nil {
+		return
+	}
+	for _, gp := range idle {
+		h.markAway(gp.GameID, gp.UserID)
+	}
+}
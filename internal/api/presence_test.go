@@ -0,0 +1,159 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+type presenceChange struct {
+	UserID string `json:"userId"`
+	Status string `json:"status"`
+}
+
+func TestIdlePlayersAreReportedAwayOnceAndBackOnActivity(t *testing.T) {
+	repo := store.NewMemory()
+	h := api.NewHandlers(repo)
+	r := gin.New()
+	r.GET("/api/game/:gameId/poll", h.PollHandler)
+	r.POST("/api/game/chat", h.ChatHandler)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	now := time.Now()
+	ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+	bob := model.User{ID: model.NewID(), Name: "Bob", CreatedAt: now}
+	bot := model.User{ID: model.NewID(), Name: "Bot", IsBot: true, BotDifficulty: "easy", CreatedAt: now}
+	g := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "active", CreatedAt: now}
+	require.NoError(t, repo.Games().Create(&g))
+	for i, p := range []struct {
+		user *model.User
+		seen time.Time
+	}{
+		{&ann, now.Add(-api.IdleTimeout - time.Minute)},
+		{&bob, now},
+		{&bot, now.Add(-time.Hour)},
+	} {
+		require.NoError(t, repo.Users().Create(p.user))
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: p.user.ID, JoinOrder: i, JoinedAt: now, LastSeenAt: p.seen}))
+	}
+
+	// Bob watches the table; each call returns the presence changes since the last
+	var cursor uint64
+	changes := func() []presenceChange {
+		resp, err := ts.Client().Get(ts.URL + "/api/game/" + g.ID + "/poll?userId=" + bob.ID + "&timeout=0&since=" + strconv.FormatUint(cursor, 10))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var out struct {
+			Events []struct {
+				Type     string          `json:"type"`
+				Presence *presenceChange `json:"presence"`
+			} `json:"events"`
+			Cursor uint64 `json:"cursor"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		cursor = out.Cursor
+		var found []presenceChange
+		for _, ev := range out.Events {
+			if ev.Type == "presence" {
+				found = append(found, *ev.Presence)
+			}
+		}
+		return found
+	}
+
+	h.SweepIdlePlayers(now)
+	assert.Equal(t, []presenceChange{{ann.ID, "away"}}, changes(), "bots are never idle")
+	h.SweepIdlePlayers(now.Add(time.Minute))
+	assert.Empty(t, changes(), "a player is reported away once")
+
+	resp, err := ts.Client().Post(ts.URL+"/api/game/chat", "application/json",
+		bytes.NewBufferString(`{"gameId":"`+g.ID+`","userId":"`+ann.ID+`","message":"back"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []presenceChange{{ann.ID, "back"}}, changes())
+
+	h.SweepIdlePlayers(time.Now())
+	assert.Empty(t, changes(), "activity keeps a player at the table")
+	h.SweepIdlePlayers(time.Now().Add(api.IdleTimeout + time.Minute))
+	assert.ElementsMatch(t, []presenceChange{{ann.ID, "away"}, {bob.ID, "away"}}, changes())
+}
+
+func TestSwitchingToPollingKeepsTheCreatorAtTheTable(t *testing.T) {
+	repo := store.NewMemory()
+	h := api.NewHandlers(repo)
+	h.SetReconnectGrace(100 * time.Millisecond)
+	r := gin.New()
+	r.GET("/api/game/:gameId/stream/:userId", h.StreamHandler)
+	r.GET("/api/game/:gameId/poll", h.PollHandler)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	now := time.Now()
+	ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+	require.NoError(t, repo.Users().Create(&ann))
+	g := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "active", CreatedAt: now}
+	require.NoError(t, repo.Games().Create(&g))
+	require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: ann.ID, JoinedAt: now, LastSeenAt: now}))
+
+	connected := func() bool {
+		gp, err := repo.Games().Player(g.ID, ann.ID)
+		require.NoError(t, err)
+		return gp.IsConnected
+	}
+	statusLogs := func() []string {
+		logs, err := repo.Logs().Recent(g.ID, 0)
+		require.NoError(t, err)
+		var messages []string
+		for _, entry := range logs {
+			messages = append(messages, entry.Message)
+		}
+		return messages
+	}
+	poll := func() {
+		resp, err := ts.Client().Get(ts.URL + "/api/game/" + g.ID + "/poll?userId=" + ann.ID + "&timeout=0")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// The stream never delivers, so the client closes it and polls instead
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/game/"+g.ID+"/stream/"+ann.ID, nil)
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	require.True(t, connected())
+	cancel()
+	resp.Body.Close()
+	assert.Eventually(t, func() bool { return !connected() }, time.Second, 10*time.Millisecond)
+	poll()
+	assert.True(t, connected(), "the first poll connects")
+	poll()
+
+	time.Sleep(300 * time.Millisecond)
+	stored, err := repo.Games().Get(g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "active", stored.Status, "switching transports is not leaving")
+	assert.Equal(t, []string{"Ann: connected to the game"}, statusLogs())
+
+	// Once the client stops polling it is gone
+	h.ExpirePolls(time.Now().Add(time.Hour))
+	assert.False(t, connected())
+	assert.Eventually(t, func() bool {
+		stored, err := repo.Games().Get(g.ID)
+		return err == nil && stored.Status == "abandoned"
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, statusLogs(), "Ann: disconnected from the game")
+}
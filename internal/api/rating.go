@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/rating"
+)
+
+// RatingResponse is a rating with how it got there
+type RatingResponse struct {
+	SubjectID   string               `json:"subjectId"` // the user ID, or "bot:<difficulty>" for bots
+	Rating      float64              `json:"rating"`
+	Games       int                  `json:"games"`
+	Provisional bool                 `json:"provisional"`
+	History     []model.RatingChange `json:"history"` // oldest first
+}
+
+// LeaderboardEntry is one line of the leaderboard
+type LeaderboardEntry struct {
+	Rank        int     `json:"rank"`
+	SubjectID   string  `json:"subjectId"`
+	Name        string  `json:"name"`
+	IsBot       bool    `json:"isBot"`
+	Rating      float64 `json:"rating"`
+	Games       int     `json:"games"`
+	Provisional bool    `json:"provisional"`
+}
+
+// UserRatingHandler returns a user's skill rating and its history. A bot's
+// rating is the one shared by every bot of its difficulty.
+//
+// @Summary      User rating
+// @Tags         user
+// @Produce      json
+// @Param        id  path  string  true  "user id"
+// @Success      200  {object}  RatingResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /api/user/{id}/rating [get]
+func (h *Handlers) UserRatingHandler(c *gin.Context) {
+	user, err := h.repo.Users().Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	r, err := rating.Get(h.repo, rating.Subject(*user))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	history, err := h.repo.Ratings().History(r.SubjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if history == nil {
+		history = []model.RatingChange{}
+	}
+	c.JSON(http.StatusOK, RatingResponse{
+		SubjectID:   r.SubjectID,
+		Rating:      r.Rating,
+		Games:       r.Games,
+		Provisional: rating.Provisional(*r),
+		History:     history,
+	})
+}
+
+// LeaderboardHandler ranks players and bot difficulties by rating.
+// Provisional ratings are left out unless asked for.
+//
+// @Summary      Leaderboard
+// @Tags         user
+// @Produce      json
+// @Param        limit        query  int   false  "entries to return (default 50, at most 500)"
+// @Param        provisional  query  bool  false  "include provisional ratings"
+// @Success      200  {array}  LeaderboardEntry
+// @Failure      400  {object}  map[string]string
+// @Router       /api/leaderboard [get]
+func (h *Handlers) LeaderboardHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+	minGames := rating.ProvisionalGames
+	if c.Query("provisional") == "true" {
+		minGames = 0
+	}
+	ratings, err := h.repo.Ratings().Top(limit, minGames)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(ratings))
+	for i, r := range ratings {
+		entry := LeaderboardEntry{
+			Rank:        i + 1,
+			SubjectID:   r.SubjectID,
+			Rating:      r.Rating,
+			Games:       r.Games,
+			Provisional: rating.Provisional(r),
+		}
+		if difficulty, ok := strings.CutPrefix(r.SubjectID, "bot:"); ok {
+			entry.Name, entry.IsBot = "Bots ("+difficulty+")", true
+		} else if user, err := h.repo.Users().Get(r.SubjectID); err == nil {
+			entry.Name = user.Name
+		}
+		entries = append(entries, entry)
+	}
+	c.JSON(http.StatusOK, entries)
+}
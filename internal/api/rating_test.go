@@ -0,0 +1,75 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func TestLeaderboardAndRatings(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+
+	now := time.Now()
+	require.NoError(t, repo.Users().Create(&model.User{ID: "ann", Name: "Ann", CreatedAt: now}))
+	require.NoError(t, repo.Users().Create(&model.User{ID: "new", Name: "Newcomer", CreatedAt: now}))
+	require.NoError(t, repo.Users().Create(&model.User{ID: "b1", Name: "Dusty", IsBot: true, BotDifficulty: "difficult", CreatedAt: now}))
+	require.NoError(t, repo.Ratings().Save(&model.Rating{SubjectID: "ann", Rating: 1580, Games: 12}))
+	require.NoError(t, repo.Ratings().Save(&model.Rating{SubjectID: "bot:difficult", Rating: 1620, Games: 30}))
+	require.NoError(t, repo.Ratings().Save(&model.Rating{SubjectID: "new", Rating: 1700, Games: 2}))
+	require.NoError(t, repo.Ratings().AddChange(&model.RatingChange{ID: model.NewID(), SubjectID: "ann", GameID: "g1", Before: 1570, After: 1580}))
+
+	var board []api.LeaderboardEntry
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/leaderboard", &board))
+	require.Len(t, board, 2, "provisional ratings are left out")
+	assert.Equal(t, api.LeaderboardEntry{Rank: 1, SubjectID: "bot:difficult", Name: "Bots (difficult)", IsBot: true, Rating: 1620, Games: 30}, board[0])
+	assert.Equal(t, "Ann", board[1].Name)
+
+	board = nil
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/leaderboard?provisional=true&limit=1", &board))
+	require.Len(t, board, 1)
+	assert.True(t, board[0].Provisional)
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, "/api/leaderboard?limit=0", nil))
+
+	var r api.RatingResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/ann/rating", &r))
+	assert.Equal(t, 1580.0, r.Rating)
+	assert.False(t, r.Provisional)
+	require.Len(t, r.History, 1)
+
+	r = api.RatingResponse{}
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/b1/rating", &r))
+	assert.Equal(t, "bot:difficult", r.SubjectID, "bots share their difficulty's rating")
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/user/missing/rating", nil))
+}
+
+func TestCasualGamesAreNotRated(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	require.NoError(t, repo.Users().Create(&model.User{ID: "ann", Name: "Ann", CreatedAt: time.Now()}))
+
+	for body, rated := range map[string]bool{`{"requesterId":"ann"}`: true, `{"requesterId":"ann","rated":false}`: false} {
+		resp, err := ts.Client().Post(ts.URL+"/api/game/create", "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		var created map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		resp.Body.Close()
+		assert.Equal(t, rated, created["rated"])
+		settings, err := repo.Games().Settings(created["gameId"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, rated, settings.Rated)
+	}
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// ReplayResponse is a finished game told round by round
+type ReplayResponse struct {
+	GameID  string        `json:"gameId,omitempty"` // not through a share link
+	LoserID *string       `json:"loserId,omitempty"`
+	Players []PlayerInfo  `json:"players"`
+	Rounds  []ReplayRound `json:"rounds"`
+}
+
+// ReplayRound is one round of a replay: the deal and every trick
+type ReplayRound struct {
+	RoundNumber int                   `json:"roundNumber"`
+	Seats       []string              `json:"seats"` // user IDs in seating order
+	Deal        map[string][]CardInfo `json:"deal"`  // userID -> cards in the order dealt
+	Tricks      []ReplayTrick         `json:"tricks"`
+	LoserID     *string               `json:"loserId,omitempty"`
+	Letter      string                `json:"letter,omitempty"`
+}
+
+// ReplayTrick is a trick and how it ended
+type ReplayTrick struct {
+	TurnNumber    int          `json:"turnNumber"`
+	StartPlayerID string       `json:"startPlayerId"`
+	LeadSuit      string       `json:"leadSuit,omitempty"`
+	Plays         []ReplayPlay `json:"plays"`
+	// Outcome is "discarded" when everyone followed suit and "cut" when
+	// someone could not; the winner of a cut picks up every card played
+	Outcome     string `json:"outcome"`
+	WinnerID    string `json:"winnerId,omitempty"`
+	CutPlayerID string `json:"cutPlayerId,omitempty"`
+}
+
+// ReplayPlay is a card played in a trick
+type ReplayPlay struct {
+	PlayerID string   `json:"playerId"`
+	Card     CardInfo `json:"card"`
+}
+
+// deck maps each card code to its card. Cards in a replay are identified by
+// their code, since an archived game no longer has card rows.
+var deck = func() map[string]CardInfo {
+	cards := make(map[string]CardInfo)
+	for _, c := range model.CreateStandardDeck("") {
+		code := c.CardCode()
+		cards[code] = CardInfo{ID: code, Suit: c.Suit, Rank: c.Rank, Value: c.Value, SortOrder: c.SortOrder, Code: code}
+	}
+	return cards
+}()
+
+// loadFinishedGame loads the history of a game for replay, answering the
+// request itself when it cannot be replayed
+func (h *Handlers) loadFinishedGame(c *gin.Context, gameID string) (*archive.Record, bool) {
+	record, err := archive.Load(h.repo, gameID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	// Every hand is visible in a replay, so a game being played has none
+	if record.Status != "completed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "game is not finished"})
+		return nil, false
+	}
+	return record, true
+}
+
+// ReplayHandler returns every round of a finished game with its deal and
+// tricks in order.
+//
+// @Summary      Game replay
+// @Description  Each round's initial deal and ordered tricks with their cut or discard outcome
+// @Tags         game
+// @Produce      json
+// @Param        gameId  path   string  true  "Game ID"
+// @Param        userId  query  string  true  "Player asking"
+// @Success      200  {object}  ReplayResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/game/{gameId}/replay [get]
+func (h *Handlers) ReplayHandler(c *gin.Context) {
+	record, ok := h.loadFinishedGame(c, c.Param("gameId"))
+	if !ok {
+		return
+	}
+
+	response := ReplayResponse{GameID: shownGameID(c, record.GameID), LoserID: record.LoserID, Players: replayPlayers(record)}
+	for _, r := range record.Rounds {
+		round := ReplayRound{
+			RoundNumber: r.RoundNumber,
+			Seats:       r.Seats,
+			Deal:        make(map[string][]CardInfo, len(r.Deal)),
+			Tricks:      make([]ReplayTrick, 0, len(r.Tricks)),
+			LoserID:     r.LoserID,
+			Letter:      r.Letter,
+		}
+		for userID, codes := range r.Deal {
+			for _, code := range codes {
+				round.Deal[userID] = append(round.Deal[userID], deck[code])
+			}
+		}
+		for _, t := range r.Tricks {
+			trick := ReplayTrick{
+				TurnNumber:    t.Number,
+				StartPlayerID: t.StartPlayerID,
+				LeadSuit:      t.LeadSuit,
+				Plays:         make([]ReplayPlay, 0, len(t.Plays)),
+				Outcome:       trickOutcome(t.Status),
+				WinnerID:      t.WinnerID,
+				CutPlayerID:   t.CutPlayerID,
+			}
+			for _, p := range t.Plays {
+				trick.Plays = append(trick.Plays, ReplayPlay{PlayerID: p.PlayerID, Card: deck[p.Card]})
+			}
+			round.Tricks = append(round.Tricks, trick)
+		}
+		response.Rounds = append(response.Rounds, round)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func trickOutcome(status string) string {
+	if status == "completed" {
+		return "discarded"
+	}
+	return status
+}
+
+// ReplayStepHandler rebuilds the game state as it stood at one point of a
+// finished game.
+//
+// @Summary      Game state at a replay step
+// @Description  The state after `play` cards of trick `turn` of round `round` were played. play=0 is the start of the trick; the last play shows the trick's outcome before its cards are collected or discarded. The state includes the hand of the player asking.
+// @Tags         game
+// @Produce      json
+// @Param        gameId  path   string  true   "Game ID"
+// @Param        round   query  int     false  "Round number (default 1)"
+// @Param        turn    query  int     false  "Turn number within the round (default 1)"
+// @Param        play    query  int     false  "Cards played so far in the turn (default 0)"
+// @Param        userId  query  string  true   "Player asking, whose hand is shown"
+// @Success      200  {object}  GameStateResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/game/{gameId}/replay/step [get]
+func (h *Handlers) ReplayStepHandler(c *gin.Context) {
+	round, turn, play := 1, 1, 0
+	for name, dst := range map[string]*int{"round": &round, "turn": &turn, "play": &play} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+				return
+			}
+			*dst = n
+		}
+	}
+
+	record, ok := h.loadFinishedGame(c, c.Param("gameId"))
+	if !ok {
+		return
+	}
+	state, err := h.replayState(record, round, turn, play, c.Query("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	state.Game.ID = shownGameID(c, state.Game.ID)
+	c.JSON(http.StatusOK, state)
+}
+
+// replayPlayers lists a game's players as they were when it ended
+func replayPlayers(record *archive.Record) []PlayerInfo {
+	players := make([]PlayerInfo, 0, len(record.Players))
+	for _, p := range record.Players {
+		players = append(players, PlayerInfo{
+			ID:            p.UserID,
+			Name:          p.Name,
+			IsBot:         p.IsBot,
+			BotDifficulty: p.BotDifficulty,
+			IsConnected:   true,
+			DonkeyLetters: p.DonkeyLetters,
+			JoinOrder:     p.JoinOrder,
+		})
+	}
+	return players
+}
+
+// replayState plays a round's deal forward to the given point and describes
+// the table the way GameStateHandler would have at that moment
+func (h *Handlers) replayState(record *archive.Record, roundNumber, turnNumber, play int, viewerID string) (*GameStateResponse, error) {
+	var round *model.ArchivedRound
+	letters := make(map[string]string)
+	for i := range record.Rounds {
+		if record.Rounds[i].RoundNumber == roundNumber {
+			round = &record.Rounds[i]
+			break
+		}
+		if r := record.Rounds[i]; r.LoserID != nil {
+			letters[*r.LoserID] += r.Letter
+		}
+	}
+	if round == nil {
+		return nil, fmt.Errorf("game has no round %d", roundNumber)
+	}
+	if round.Deal == nil {
+		return nil, fmt.Errorf("the deal of round %d was not recorded", roundNumber)
+	}
+	if turnNumber < 1 || turnNumber > len(round.Tricks) {
+		return nil, fmt.Errorf("round %d has turns 1 to %d", roundNumber, len(round.Tricks))
+	}
+	trick := round.Tricks[turnNumber-1]
+	if play < 0 || play > len(trick.Plays) {
+		return nil, fmt.Errorf("turn %d has plays 0 to %d", turnNumber, len(trick.Plays))
+	}
+
+	hands := make(map[string][]string, len(round.Deal))
+	for userID, codes := range round.Deal {
+		hands[userID] = append([]string(nil), codes...)
+	}
+	finished := make(map[string]bool)
+	var discards []CardInfo
+	playCard := func(p model.ArchivedPlay) {
+		hand := hands[p.PlayerID]
+		for i, code := range hand {
+			if code == p.Card {
+				hands[p.PlayerID] = append(hand[:i], hand[i+1:]...)
+				break
+			}
+		}
+		if len(hands[p.PlayerID]) == 0 {
+			finished[p.PlayerID] = true
+		}
+	}
+	for _, t := range round.Tricks[:turnNumber-1] {
+		for _, p := range t.Plays {
+			playCard(p)
+			if t.Status == "cut" {
+				hands[t.WinnerID] = append(hands[t.WinnerID], p.Card)
+			} else {
+				discards = append(discards, deck[p.Card])
+			}
+		}
+	}
+	var inPlay []PlayedCardInfo
+	for i, p := range trick.Plays[:play] {
+		playCard(p)
+		inPlay = append(inPlay, PlayedCardInfo{ID: p.Card, Card: deck[p.Card], PlayerID: p.PlayerID, PlayOrder: i + 1})
+	}
+
+	state := &GameStateResponse{
+		Game: GameInfo{
+			ID:          record.GameID,
+			Status:      "active",
+			RequesterID: record.RequesterID,
+			StartedAt:   record.StartedAt,
+		},
+		CurrentRound: &RoundInfo{
+			RoundNumber:  round.RoundNumber,
+			Status:       "active",
+			StartedAt:    round.StartedAt,
+			DiscardCount: len(discards),
+			DiscardPile:  discards,
+		},
+		CurrentTurn: &TurnInfo{
+			TurnNumber:    trick.Number,
+			StartPlayerID: trick.StartPlayerID,
+			Status:        "active",
+		},
+		InPlayCards: inPlay,
+		RecentLogs:  []LogInfo{},
+	}
+	if game, err := h.repo.Games().Get(record.GameID); err == nil {
+		state.Game.MaxPlayers, state.Game.MinPlayers = game.MaxPlayers, game.MinPlayers
+	}
+	if play > 0 {
+		state.CurrentTurn.LeadSuit = &trick.LeadSuit
+	}
+	if play == len(trick.Plays) && trick.Status != "active" {
+		state.CurrentTurn.Status = trick.Status
+		state.CurrentTurn.WinnerID = nonEmpty(trick.WinnerID)
+		state.CurrentTurn.CutPlayerID = nonEmpty(trick.CutPlayerID)
+	}
+
+	seats := make([]model.RoundPlayer, len(round.Seats))
+	for i, userID := range round.Seats {
+		seats[i] = model.RoundPlayer{UserID: userID, Position: i, IsFinished: finished[userID], CardsInHand: len(hands[userID])}
+	}
+	if state.CurrentTurn.Status == "active" {
+		turn := &model.Turn{StartPlayerID: trick.StartPlayerID}
+		for _, p := range trick.Plays[:play] {
+			turn.PlayedCards = append(turn.PlayedCards, model.PlayedCard{PlayerID: p.PlayerID})
+		}
+		if expected, err := getExpectedPlayerIDForTurn(turn, seats); err == nil {
+			state.CurrentTurn.ExpectedPlayerID = &expected
+		}
+	}
+
+	state.Players = replayPlayers(record)
+	for i := range state.Players {
+		p := &state.Players[i]
+		p.DonkeyLetters = letters[p.ID]
+		for j := range seats {
+			if seats[j].UserID == p.ID {
+				p.Position = &seats[j].Position
+				p.CardsInHand, p.IsFinished = seats[j].CardsInHand, seats[j].IsFinished
+			}
+		}
+	}
+	for _, code := range hands[viewerID] {
+		state.MyCards = append(state.MyCards, deck[code])
+	}
+	sort.Slice(state.MyCards, func(i, j int) bool { return state.MyCards[i].SortOrder < state.MyCards[j].SortOrder })
+	return state, nil
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"encoding/json"
+	"image/gif"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// archivedGame stores a finished, archived game of one short round between
+// ann and bob
+func archivedGame(t *testing.T, repo store.Store) string {
+	t.Helper()
+	now := time.Now()
+	g := model.Game{ID: model.NewID(), RequesterID: "ann", Status: "completed", MaxPlayers: 8, MinPlayers: 2, CreatedAt: now}
+	require.NoError(t, repo.Games().Create(&g))
+	for i, id := range []string{"ann", "bob"} {
+		require.NoError(t, repo.Users().Create(&model.User{ID: id, Name: id, CreatedAt: now}))
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: id, JoinOrder: i}))
+	}
+	players, _ := json.Marshal([]model.ArchivedPlayer{
+		{UserID: "ann", Name: "Ann", JoinOrder: 0, DonkeyLetters: "D"},
+		{UserID: "bob", Name: "Bob", JoinOrder: 1},
+	})
+	ann := "ann"
+	rounds, _ := json.Marshal([]model.ArchivedRound{{
+		RoundNumber: 1, LoserID: &ann, Letter: "D", StartedAt: now.Add(-90 * time.Second), CompletedAt: &now,
+		Seats: []string{"bob", "ann"}, FinishOrder: []string{"bob", "ann"},
+		Deal: map[string][]string{"ann": {"AS", "2H"}, "bob": {"KS", "3D"}},
+		Tricks: []model.ArchivedTrick{
+			{Number: 1, StartPlayerID: "ann", LeadSuit: "spades", Status: "cut", WinnerID: "ann", CutPlayerID: "bob",
+				Plays: []model.ArchivedPlay{{PlayerID: "ann", Card: "AS"}, {PlayerID: "bob", Card: "3D"}}},
+			{Number: 2, StartPlayerID: "bob", LeadSuit: "spades", Status: "completed", WinnerID: "bob",
+				Plays: []model.ArchivedPlay{{PlayerID: "bob", Card: "KS"}}},
+		},
+	}})
+	require.NoError(t, repo.Archives().Save(&model.GameArchive{
+		GameID: g.ID, RequesterID: "ann", LoserID: &ann, Players: string(players), Rounds: string(rounds),
+		CreatedAt: now, ArchivedAt: now,
+	}))
+	return g.ID
+}
+
+func getJSON(t *testing.T, ts *httptest.Server, path string, out interface{}) int {
+	t.Helper()
+	resp, err := ts.Client().Get(ts.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestReplayOfArchivedGame(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+
+	var replay api.ReplayResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/game/"+gameID+"/replay?userId=ann", &replay))
+	require.Len(t, replay.Rounds, 1)
+	round := replay.Rounds[0]
+	assert.Equal(t, "AS", round.Deal["ann"][0].Code)
+	assert.Equal(t, "spades", round.Deal["ann"][0].Suit)
+	require.Len(t, round.Tricks, 2)
+	assert.Equal(t, "cut", round.Tricks[0].Outcome)
+	assert.Equal(t, "discarded", round.Tricks[1].Outcome)
+
+	// The last play of a trick shows its outcome before the cards move
+	var state api.GameStateResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/game/"+gameID+"/replay/step?round=1&turn=1&play=2&userId=ann", &state))
+	assert.Equal(t, "cut", state.CurrentTurn.Status)
+	assert.Equal(t, "ann", *state.CurrentTurn.WinnerID)
+	assert.Len(t, state.InPlayCards, 2)
+
+	// Ann picked up the cut and it is Bob's lead
+	state = api.GameStateResponse{}
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/game/"+gameID+"/replay/step?round=1&turn=2&userId=ann", &state))
+	assert.Equal(t, "active", state.CurrentTurn.Status)
+	assert.Equal(t, "bob", *state.CurrentTurn.ExpectedPlayerID)
+	var codes []string
+	for _, c := range state.MyCards {
+		codes = append(codes, c.Code)
+	}
+	assert.ElementsMatch(t, []string{"2H", "AS", "3D"}, codes)
+	for _, p := range state.Players {
+		switch p.ID {
+		case "ann":
+			assert.Equal(t, 3, p.CardsInHand)
+			assert.Empty(t, p.DonkeyLetters, "letters are as of the round being replayed")
+		case "bob":
+			assert.Equal(t, 1, p.CardsInHand)
+		}
+	}
+
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, "/api/game/"+gameID+"/replay/step?round=1&turn=3&userId=ann", nil))
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, "/api/game/"+gameID+"/replay/step?round=2&userId=ann", nil))
+
+	resp, err := ts.Client().Get(ts.URL + "/api/game/" + gameID + "/replay/animation.gif?round=1&userId=ann")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	animation, err := gif.DecodeAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.NotEmpty(t, animation.Image)
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, "/api/game/"+gameID+"/replay/animation.gif?round=2&userId=ann", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/game/"+model.NewID()+"/replay?userId=ann", nil))
+}
+
+func TestRoundAnimationIsRenderedOnce(t *testing.T) {
+	var gets int64
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(countingStore{repo, &gets}))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+
+	fetch := func() []byte {
+		resp, err := ts.Client().Get(ts.URL + "/api/game/" + gameID + "/replay/animation.gif?round=1&userId=ann")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return data
+	}
+	first := fetch()
+	loads := atomic.LoadInt64(&gets)
+	assert.Equal(t, first, fetch())
+	assert.Equal(t, loads, atomic.LoadInt64(&gets), "the second request is served from the cache")
+}
+
+func TestReplayOfGameInProgressIsRefused(t *testing.T) {
+	ts := httptest.NewServer(server.NewWithStore(store.NewMemory()))
+	defer ts.Close()
+	gameID, leader, _ := startTwoPlayerGame(t, ts)
+
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, "/api/game/"+gameID+"/replay?userId="+leader, nil))
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, "/api/game/"+gameID+"/replay/step?userId="+leader, nil))
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, "/api/game/"+gameID+"/replay/animation.gif?round=1&userId="+leader, nil))
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, "/api/game/"+gameID+"/history?userId="+leader, nil))
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, "/api/game/"+gameID+"/report?userId="+leader, nil))
+}
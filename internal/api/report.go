@@ -0,0 +1,211 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/archive"
+)
+
+// maxPickups is how many of the biggest pickups a report lists
+const maxPickups = 5
+
+// GameReport sums up a finished game
+type GameReport struct {
+	GameID          string         `json:"gameId,omitempty"` // not through a share link
+	LoserID         *string        `json:"loserId,omitempty"`
+	StartedAt       *time.Time     `json:"startedAt,omitempty"`
+	CompletedAt     *time.Time     `json:"completedAt,omitempty"`
+	DurationSeconds int64          `json:"durationSeconds,omitempty"`
+	Players         []ReportPlayer `json:"players"` // in join order
+	Rounds          []ReportRound  `json:"rounds"`
+	BiggestPickups  []ReportPickup `json:"biggestPickups"` // largest first
+	LongestStreak   *ReportStreak  `json:"longestStreak,omitempty"`
+	Humans          ReportGroup    `json:"humans"`
+	Bots            ReportGroup    `json:"bots"`
+}
+
+// ReportPlayer is how one player did over the game
+type ReportPlayer struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	IsBot         bool    `json:"isBot"`
+	DonkeyLetters string  `json:"donkeyLetters"`
+	RoundsLost    int     `json:"roundsLost"`
+	Cuts          int     `json:"cuts"`
+	Pickups       int     `json:"pickups"` // cut tricks they had to pick up
+	CardsPickedUp int     `json:"cardsPickedUp"`
+	AverageFinish float64 `json:"averageFinish,omitempty"` // 1 is first out of cards
+}
+
+// ReportRound is one round of the game
+type ReportRound struct {
+	RoundNumber     int      `json:"roundNumber"`
+	LoserID         *string  `json:"loserId,omitempty"`
+	Letter          string   `json:"letter,omitempty"`
+	FinishOrder     []string `json:"finishOrder,omitempty"` // first out of cards first, the loser last
+	Tricks          int      `json:"tricks"`
+	Cuts            int      `json:"cuts"`
+	DurationSeconds int64    `json:"durationSeconds,omitempty"`
+}
+
+// ReportPickup is a cut trick and who had to pick it up
+type ReportPickup struct {
+	RoundNumber int    `json:"roundNumber"`
+	TurnNumber  int    `json:"turnNumber"`
+	PlayerID    string `json:"playerId"`
+	CutPlayerID string `json:"cutPlayerId"`
+	Cards       int    `json:"cards"`
+}
+
+// ReportStreak is the longest run of tricks one player won back to back,
+// keeping the lead throughout
+type ReportStreak struct {
+	PlayerID    string `json:"playerId"`
+	RoundNumber int    `json:"roundNumber"`
+	FromTurn    int    `json:"fromTurn"`
+	ToTurn      int    `json:"toTurn"`
+	Tricks      int    `json:"tricks"`
+}
+
+// ReportGroup adds up the humans or the bots of a game
+type ReportGroup struct {
+	Players       int     `json:"players"`
+	Donkeys       int     `json:"donkeys"` // 1 if the game's DONKEY is one of them
+	RoundsLost    int     `json:"roundsLost"`
+	Cuts          int     `json:"cuts"`
+	CardsPickedUp int     `json:"cardsPickedUp"`
+	AverageFinish float64 `json:"averageFinish,omitempty"`
+}
+
+// GameReportHandler returns the post-game report of a finished game.
+//
+// @Summary      Post-game report
+// @Description  Per-round losers, letters and finish order, the biggest pickups, cuts per player, the longest winning streak, round times and humans against bots
+// @Tags         game
+// @Produce      json
+// @Param        gameId  path   string  true  "Game ID"
+// @Param        userId  query  string  true  "Player asking"
+// @Success      200  {object}  GameReport
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/game/{gameId}/report [get]
+func (h *Handlers) GameReportHandler(c *gin.Context) {
+	record, ok := h.loadFinishedGame(c, c.Param("gameId"))
+	if !ok {
+		return
+	}
+	report := buildReport(record)
+	report.GameID = shownGameID(c, report.GameID)
+	c.JSON(http.StatusOK, report)
+}
+
+// buildReport works the report out from a game's history. Archives made
+// before tricks or finish orders were recorded give a report without them.
+func buildReport(record *archive.Record) *GameReport {
+	report := &GameReport{
+		GameID:         record.GameID,
+		LoserID:        record.LoserID,
+		StartedAt:      record.StartedAt,
+		CompletedAt:    record.CompletedAt,
+		BiggestPickups: []ReportPickup{},
+	}
+	if record.StartedAt != nil && record.CompletedAt != nil {
+		report.DurationSeconds = int64(record.CompletedAt.Sub(*record.StartedAt).Seconds())
+	}
+
+	players := make(map[string]*ReportPlayer, len(record.Players))
+	report.Players = make([]ReportPlayer, len(record.Players))
+	for i, p := range record.Players {
+		report.Players[i] = ReportPlayer{ID: p.UserID, Name: p.Name, IsBot: p.IsBot, DonkeyLetters: p.DonkeyLetters}
+		players[p.UserID] = &report.Players[i]
+	}
+	positions := make(map[string][]int)
+
+	for _, r := range record.Rounds {
+		round := ReportRound{RoundNumber: r.RoundNumber, LoserID: r.LoserID, Letter: r.Letter, FinishOrder: r.FinishOrder, Tricks: len(r.Tricks)}
+		if r.CompletedAt != nil {
+			round.DurationSeconds = int64(r.CompletedAt.Sub(r.StartedAt).Seconds())
+		}
+		if r.LoserID != nil && players[*r.LoserID] != nil {
+			players[*r.LoserID].RoundsLost++
+		}
+		for i, userID := range r.FinishOrder {
+			positions[userID] = append(positions[userID], i+1)
+		}
+
+		streak := ReportStreak{RoundNumber: r.RoundNumber}
+		for _, t := range r.Tricks {
+			switch t.Status {
+			case "cut":
+				round.Cuts++
+				if p := players[t.CutPlayerID]; p != nil {
+					p.Cuts++
+				}
+				if p := players[t.WinnerID]; p != nil {
+					p.Pickups++
+					p.CardsPickedUp += len(t.Plays)
+				}
+				report.BiggestPickups = append(report.BiggestPickups, ReportPickup{
+					RoundNumber: r.RoundNumber, TurnNumber: t.Number, PlayerID: t.WinnerID, CutPlayerID: t.CutPlayerID, Cards: len(t.Plays),
+				})
+				streak.Tricks = 0
+			case "completed":
+				if streak.Tricks > 0 && streak.PlayerID == t.WinnerID {
+					streak.Tricks++
+				} else {
+					streak.PlayerID, streak.FromTurn, streak.Tricks = t.WinnerID, t.Number, 1
+				}
+				streak.ToTurn = t.Number
+				if report.LongestStreak == nil || streak.Tricks > report.LongestStreak.Tricks {
+					longest := streak
+					report.LongestStreak = &longest
+				}
+			}
+		}
+		report.Rounds = append(report.Rounds, round)
+	}
+
+	sort.SliceStable(report.BiggestPickups, func(i, j int) bool {
+		return report.BiggestPickups[i].Cards > report.BiggestPickups[j].Cards
+	})
+	if len(report.BiggestPickups) > maxPickups {
+		report.BiggestPickups = report.BiggestPickups[:maxPickups]
+	}
+
+	groupPositions := make(map[bool][]int)
+	for i := range report.Players {
+		p := &report.Players[i]
+		p.AverageFinish = average(positions[p.ID])
+		group := &report.Humans
+		if p.IsBot {
+			group = &report.Bots
+		}
+		group.Players++
+		group.RoundsLost += p.RoundsLost
+		group.Cuts += p.Cuts
+		group.CardsPickedUp += p.CardsPickedUp
+		if record.LoserID != nil && *record.LoserID == p.ID {
+			group.Donkeys++
+		}
+		groupPositions[p.IsBot] = append(groupPositions[p.IsBot], positions[p.ID]...)
+	}
+	report.Humans.AverageFinish = average(groupPositions[false])
+	report.Bots.AverageFinish = average(groupPositions[true])
+	return report
+}
+
+func average(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values))
+}
@@ -0,0 +1,54 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func TestReportOfArchivedGame(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+
+	var report api.GameReport
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/game/"+gameID+"/report?userId=bob", &report))
+	require.Len(t, report.Rounds, 1)
+	round := report.Rounds[0]
+	assert.Equal(t, "ann", *round.LoserID)
+	assert.Equal(t, "D", round.Letter)
+	assert.Equal(t, []string{"bob", "ann"}, round.FinishOrder)
+	assert.Equal(t, 2, round.Tricks)
+	assert.Equal(t, 1, round.Cuts)
+	assert.EqualValues(t, 90, round.DurationSeconds)
+
+	require.Len(t, report.BiggestPickups, 1)
+	assert.Equal(t, api.ReportPickup{RoundNumber: 1, TurnNumber: 1, PlayerID: "ann", CutPlayerID: "bob", Cards: 2}, report.BiggestPickups[0])
+	require.NotNil(t, report.LongestStreak)
+	assert.Equal(t, "bob", report.LongestStreak.PlayerID)
+	assert.Equal(t, 1, report.LongestStreak.Tricks)
+
+	require.Len(t, report.Players, 2)
+	ann, bob := report.Players[0], report.Players[1]
+	assert.Equal(t, 1, ann.RoundsLost)
+	assert.Equal(t, 2, ann.CardsPickedUp)
+	assert.Equal(t, 2.0, ann.AverageFinish)
+	assert.Equal(t, 1, bob.Cuts)
+	assert.Equal(t, 1.0, bob.AverageFinish)
+
+	assert.Equal(t, 2, report.Humans.Players)
+	assert.Equal(t, 1, report.Humans.Donkeys)
+	assert.Equal(t, 1.5, report.Humans.AverageFinish)
+	assert.Zero(t, report.Bots.Players)
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/game/"+model.NewID()+"/report?userId=bob", nil))
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/retention"
+)
+
+// RetentionReportHandler returns what the last retention run removed.
+//
+// @Summary      Last retention report
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  retention.Report
+// @Failure      404  {object}  map[string]string
+// @Router       /api/admin/retention [get]
+func RetentionReportHandler(c *gin.Context) {
+	report := retention.LastReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "retention has not run yet"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/render"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// ShareRequest asks for the share link of a game
+type ShareRequest struct {
+	UserID string `json:"userId"`
+}
+
+// ShareResponse is a game's share link
+type ShareResponse struct {
+	Token         string    `json:"token"`
+	StateURL      string    `json:"stateUrl"`
+	ReplayURL     string    `json:"replayUrl"` // once the game is finished
+	ReportURL     string    `json:"reportUrl"` // once the game is finished
+	ScoreboardURL string    `json:"scoreboardUrl"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+func shareResponse(link *model.ShareLink) ShareResponse {
+	base := "/api/share/" + link.Token
+	return ShareResponse{
+		Token:         link.Token,
+		StateURL:      base + "/state",
+		ReplayURL:     base + "/replay",
+		ReportURL:     base + "/report",
+		ScoreboardURL: base + "/scoreboard.png",
+		CreatedAt:     link.CreatedAt,
+	}
+}
+
+// ShareGameHandler returns the share link of a game, creating it on first
+// use. Only the game's players may share it.
+//
+// @Summary      Share a game
+// @Description  The unguessable link through which people outside the game can follow it and see its replay, report and scoreboard
+// @Tags         game
+// @Accept       json
+// @Produce      json
+// @Param        gameId  path  string        true  "Game ID"
+// @Param        body    body  ShareRequest  true  "Player sharing the game"
+// @Success      200  {object}  ShareResponse
+// @Success      201  {object}  ShareResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/game/{gameId}/share [post]
+func (h *Handlers) ShareGameHandler(c *gin.Context) {
+	var req ShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.UserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	gameID := c.Param("gameId")
+	if _, err := h.repo.Games().Get(gameID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+		return
+	}
+	if _, err := h.repo.Games().Player(gameID, req.UserID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only players can share a game"})
+		return
+	}
+
+	link, err := h.repo.Shares().ForGame(gameID)
+	if err == nil {
+		c.JSON(http.StatusOK, shareResponse(link))
+		return
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	link = &model.ShareLink{Token: model.NewID(), GameID: gameID, CreatedBy: req.UserID, CreatedAt: time.Now()}
+	if err := h.repo.Shares().Create(link); err != nil {
+		// Another player shared the game at the same moment
+		if existing, getErr := h.repo.Shares().ForGame(gameID); getErr == nil {
+			c.JSON(http.StatusOK, shareResponse(existing))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, shareResponse(link))
+}
+
+// RevokeShareHandler deletes the share link of a game, so its token no
+// longer shows the game to anyone. Only the game's owner may revoke it;
+// sharing the game again makes a new token.
+//
+// @Summary      Revoke a game's share link
+// @Description  Stops the current share token of a game from working
+// @Tags         game
+// @Accept       json
+// @Produce      json
+// @Param        gameId  path  string        true  "Game ID"
+// @Param        body    body  ShareRequest  true  "Owner of the game"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/game/{gameId}/share [delete]
+func (h *Handlers) RevokeShareHandler(c *gin.Context) {
+	var req ShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.UserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	gameModel, err := h.repo.Games().Get(c.Param("gameId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+		return
+	}
+	if gameModel.RequesterID != req.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only game creator can revoke the share link"})
+		return
+	}
+	err = h.repo.Shares().Revoke(gameModel.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "game is not shared"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "share_revoked"})
+}
+
+// Shared serves a game handler to people outside the game: it resolves the
+// share token of the request and hands on to next as if the game had been
+// asked for by its ID, in place of PlayersOnly. Handlers that show hands only
+// do so for finished games, and none names the game.
+func (h *Handlers) Shared(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gameID, ok := h.sharedGame(c)
+		if !ok {
+			return
+		}
+		c.Params = append(c.Params, gin.Param{Key: "gameId", Value: gameID})
+		next(c)
+	}
+}
+
+// SharedStateHandler returns the state of a shared game as an outside viewer
+// sees it: everything on the table but nobody's hand. The response carries
+// the game's state version as its ETag and honours If-None-Match.
+//
+// @Summary      Shared game state
+// @Description  The state of a shared game without any player's cards
+// @Tags         game
+// @Produce      json
+// @Param        token  path  string  true  "Share token"
+// @Success      200  {object}  GameStateResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /api/share/{token}/state [get]
+func (h *Handlers) SharedStateHandler(c *gin.Context) {
+	gameID, ok := h.sharedGame(c)
+	if !ok {
+		return
+	}
+	h.serveGameState(c, gameID, "")
+}
+
+// sharedGame resolves the share token of a request to its game's ID,
+// answering the request itself when the token is unknown
+func (h *Handlers) sharedGame(c *gin.Context) (string, bool) {
+	link, err := h.repo.Shares().Get(c.Param("token"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+		return "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return link.GameID, true
+}
+
+// SharedScoreboardHandler returns the scoreboard of a shared, finished game
+// as a PNG image.
+//
+// @Summary      Shared scoreboard image
+// @Description  The final result of a game: its players, their DONKEY letters with the loser highlighted, and the letter each round cost
+// @Tags         game
+// @Produce      png
+// @Param        token  path  string  true  "Share token"
+// @Success      200  {file}    binary
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/share/{token}/scoreboard.png [get]
+func (h *Handlers) SharedScoreboardHandler(c *gin.Context) {
+	gameID, ok := h.sharedGame(c)
+	if !ok {
+		return
+	}
+	data, ok := h.scoreboards.get(gameID)
+	if !ok {
+		record, ok := h.loadFinishedGame(c, gameID)
+		if !ok {
+			return
+		}
+		board, err := render.Scoreboard(record)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, board); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		data = buf.Bytes()
+		h.scoreboards.put(gameID, data)
+	}
+	c.Header("Cache-Control", finishedCacheControl(c))
+	c.Data(http.StatusOK, "image/png", data)
+}
@@ -0,0 +1,149 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func shareGame(t *testing.T, ts *httptest.Server, gameID, userID string) (int, api.ShareResponse) {
+	t.Helper()
+	resp, err := ts.Client().Post(ts.URL+"/api/game/"+gameID+"/share", "application/json", bytes.NewBufferString(`{"userId":"`+userID+`"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var share api.ShareResponse
+	if resp.StatusCode < 300 {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&share))
+	}
+	return resp.StatusCode, share
+}
+
+func TestSharedScoreboard(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+
+	status, share := shareGame(t, ts, gameID, "ann")
+	require.Equal(t, http.StatusCreated, status)
+	assert.Len(t, share.Token, 32)
+	status, again := shareGame(t, ts, gameID, "bob")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, share.Token, again.Token, "a game has one link")
+	status, _ = shareGame(t, ts, gameID, "eve")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	for i := 0; i < 2; i++ { // rendered, then cached
+		resp, err := ts.Client().Get(ts.URL + share.ScoreboardURL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"), "a revoked link stops showing it")
+		img, err := png.Decode(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Positive(t, img.Bounds().Dx())
+	}
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/share/"+model.NewID()+"/scoreboard.png", nil))
+}
+
+func revokeShare(t *testing.T, ts *httptest.Server, gameID, userID string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/game/"+gameID+"/share", bytes.NewBufferString(`{"userId":"`+userID+`"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestSharedReplayAndRevocation(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+	_, share := shareGame(t, ts, gameID, "bob")
+
+	// An outsider sees the finished game with every hand
+	var replay api.ReplayResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts, share.ReplayURL, &replay))
+	assert.Empty(t, replay.GameID, "a share link never names the game")
+	assert.Len(t, replay.Rounds[0].Deal, 2)
+	var report api.GameReport
+	require.Equal(t, http.StatusOK, getJSON(t, ts, share.ReportURL, &report))
+	assert.Empty(t, report.GameID)
+	assert.Equal(t, http.StatusOK, getJSON(t, ts, "/api/share/"+share.Token+"/replay/step?round=1&turn=2", nil))
+
+	assert.Equal(t, http.StatusForbidden, revokeShare(t, ts, gameID, "bob"), "only the owner revokes")
+	assert.Equal(t, http.StatusOK, revokeShare(t, ts, gameID, "ann"))
+	assert.Equal(t, http.StatusNotFound, revokeShare(t, ts, gameID, "ann"))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, share.ReplayURL, nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, share.ScoreboardURL, nil))
+
+	status, again := shareGame(t, ts, gameID, "bob")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.NotEqual(t, share.Token, again.Token)
+}
+
+func TestSharedGameInProgressHidesHands(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID, leader, _ := startTwoPlayerGame(t, ts)
+
+	status, share := shareGame(t, ts, gameID, leader)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, share.ScoreboardURL, nil))
+
+	// While the game is played an outsider follows the table but sees no hand
+	var state api.GameStateResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts, share.StateURL, &state))
+	assert.Len(t, state.Players, 2)
+	assert.Empty(t, state.MyCards)
+	assert.Empty(t, state.Game.ID)
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, share.ReplayURL, nil))
+	assert.Equal(t, http.StatusConflict, getJSON(t, ts, share.ReportURL, nil))
+}
+
+func TestOutsidersOnlyReachAGameThroughItsLink(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+	require.NoError(t, repo.Users().Create(&model.User{ID: "eve", Name: "Eve", CreatedAt: time.Now()}))
+	game := "/api/game/" + gameID
+
+	// The game's own routes answer its players only
+	assert.Equal(t, http.StatusOK, getJSON(t, ts, game+"/state/bob", nil))
+	assert.Equal(t, http.StatusOK, getJSON(t, ts, game+"/replay?userId=bob", nil))
+	for _, path := range []string{
+		"/state/eve", "/logs?userId=eve", "/history?userId=eve", "/replay?userId=eve",
+		"/replay/step?userId=eve", "/replay/animation.gif?round=1&userId=eve",
+		"/report?userId=eve", "/notation?userId=eve",
+	} {
+		assert.Equal(t, http.StatusForbidden, getJSON(t, ts, game+path, nil), path)
+	}
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, game+"/replay", nil))
+
+	// Eve follows the game through its link until the owner revokes it
+	_, share := shareGame(t, ts, gameID, "bob")
+	assert.Equal(t, http.StatusOK, getJSON(t, ts, share.ReplayURL, nil))
+	require.Equal(t, http.StatusOK, revokeShare(t, ts, gameID, "ann"))
+	for _, path := range []string{share.StateURL, share.ReplayURL, share.ReportURL, share.ScoreboardURL} {
+		assert.Equal(t, http.StatusNotFound, getJSON(t, ts, path, nil), path)
+	}
+	assert.Equal(t, http.StatusForbidden, getJSON(t, ts, game+"/replay?userId=eve", nil))
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionCounter holds a counter per game.
+type versionCounter struct {
+	mu       sync.Mutex
+	versions map[string]uint64
+}
+
+// stateVersions is bumped by every published event that changes what the
+// state endpoint shows: state changes and log entries. It is the ETag of the
+// state endpoint so unchanged state can be answered with 304 Not Modified.
+var stateVersions = versionCounter{versions: make(map[string]uint64)}
+
+// eventSeqs numbers every published event, private and presence ones
+// included. Clients receive it in each stream event and poll from it.
+var eventSeqs = versionCounter{versions: make(map[string]uint64)}
+
+// bootID distinguishes versions issued by this process from those of a
+// previous run, since counters start again from zero on restart.
+var bootID = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// bump increments and returns the version for a game.
+func (vc *versionCounter) bump(gameID string) uint64 {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	vc.versions[gameID]++
+	return vc.versions[gameID]
+}
+
+// current returns the latest version for a game.
+func (vc *versionCounter) current(gameID string) uint64 {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return vc.versions[gameID]
+}
+
+// stateETag formats a game version as a strong entity tag.
+func stateETag(version uint64) string {
+	return `"` + bootID + "-" + strconv.FormatUint(version, 10) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
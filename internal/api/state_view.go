@@ -0,0 +1,90 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// viewTTL is how long the view of a game nobody asks about is kept.
+const viewTTL = 10 * time.Minute
+
+// gameView is the materialized state of one game as of a state version. It
+// holds what every player may see plus each player's hand kept apart, so a
+// viewer's response is assembled from memory with only their own cards.
+type gameView struct {
+	version uint64
+	state   GameStateResponse     // shared state, MyCards always empty
+	hands   map[string][]CardInfo // userID -> cards in hand
+}
+
+// forViewer returns the state as seen by userID.
+func (v *gameView) forViewer(userID string) *GameStateResponse {
+	state := v.state
+	state.MyCards = v.hands[userID]
+	return &state
+}
+
+// viewCache keeps the latest view of each game. Views are not updated
+// incrementally: a state change or log entry bumps the game's state version,
+// and the first request after that reloads the whole view from the store
+// once, while every other viewer is served the same copy. Private events,
+// presence changes and a player's activity leave the version alone, so they
+// cost no reload.
+//
+// State versions only move for events published in this process, so the
+// cache is only coherent while one process serves a game, the same limit as
+// the event broker and streams. A change made through another replica
+// sharing the database is not seen until something is published here.
+type viewCache struct {
+	mu    sync.Mutex
+	games map[string]*viewEntry
+	load  func(gameID string, version uint64) (*gameView, error)
+}
+
+type viewEntry struct {
+	mu     sync.Mutex // held while loading so concurrent viewers share one load
+	view   *gameView
+	usedAt time.Time // guarded by viewCache.mu
+}
+
+func newViewCache(load func(gameID string, version uint64) (*gameView, error)) *viewCache {
+	return &viewCache{games: make(map[string]*viewEntry), load: load}
+}
+
+// get returns the view of a game at its current state version.
+func (vc *viewCache) get(gameID string) (*gameView, error) {
+	vc.mu.Lock()
+	entry := vc.games[gameID]
+	if entry == nil {
+		entry = &viewEntry{}
+		vc.games[gameID] = entry
+	}
+	entry.usedAt = time.Now()
+	vc.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	// Read the version before loading: a change that lands mid-load bumps
+	// it past the view's version, so the next request loads again.
+	version := stateVersions.current(gameID)
+	if entry.view != nil && entry.view.version == version {
+		return entry.view, nil
+	}
+	view, err := vc.load(gameID, version)
+	if err != nil {
+		return nil, err
+	}
+	entry.view = view
+	return view, nil
+}
+
+// prune forgets the views of games not requested since cutoff.
+func (vc *viewCache) prune(cutoff time.Time) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	for gameID, entry := range vc.games {
+		if entry.usedAt.Before(cutoff) {
+			delete(vc.games, gameID)
+		}
+	}
+}
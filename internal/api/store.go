@@ -0,0 +1,46 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Handlers serves the API from one store. Every server builds its own, so
+// two servers in one process never see or overwrite each other's games. The
+// event broker is the only state they share: the game package publishes to
+// it directly, and game IDs never collide.
+type Handlers struct {
+	repo        store.Store
+	views       *viewCache
+	scoreboards *imageCache // PNG by game ID
+	animations  *imageCache // GIF by game ID and round number
+	presence    *presenceTracker
+
+	reconnectGrace    time.Duration
+	startPresenceOnce sync.Once
+}
+
+// NewHandlers returns the handlers of a server whose games live in repo
+func NewHandlers(repo store.Store) *Handlers {
+	h := &Handlers{
+		repo:        repo,
+		scoreboards: newImageCache(),
+		animations:  newImageCache(),
+		presence:    newPresenceTracker(),
+
+		reconnectGrace: reconnectGrace,
+	}
+	h.views = newViewCache(h.loadGameView)
+	return h
+}
+
+// userOrEmpty loads a user for display, returning an empty user if missing
+func (h *Handlers) userOrEmpty(userID string) model.User {
+	if user, err := h.repo.Users().Get(userID); err == nil {
+		return *user
+	}
+	return model.User{}
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kairodrad/donkey/internal/db"
-	"github.com/kairodrad/donkey/internal/model"
+
+	"github.com/kairodrad/donkey/internal/stats"
+	"github.com/kairodrad/donkey/internal/store"
 )
 
 // GetUserHandler returns a user by ID.
@@ -17,10 +21,10 @@ import (
 // @Success      200  {object}  model.User
 // @Failure      404  {object}  map[string]string
 // @Router       /api/user/{id} [get]
-func GetUserHandler(c *gin.Context) {
+func (h *Handlers) GetUserHandler(c *gin.Context) {
 	id := c.Param("id")
-	var user model.User
-	if err := db.DB.Preload("Games").First(&user, "id = ?", id).Error; err != nil {
+	user, err := h.repo.Users().GetWithGames(id)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
@@ -34,8 +38,81 @@ func GetUserHandler(c *gin.Context) {
 // @Produce      json
 // @Success      200  {array}  model.User
 // @Router       /api/users [get]
-func ListUsersHandler(c *gin.Context) {
-	var users []model.User
-	db.DB.Preload("Games").Find(&users)
+func (h *Handlers) ListUsersHandler(c *gin.Context) {
+	users, _ := h.repo.Users().List()
 	c.JSON(http.StatusOK, users)
 }
+
+// UserStatsHandler returns a user's statistics over their completed games.
+//
+// @Summary      User statistics
+// @Description  Games and rounds played and lost, average finish position, cuts and pickups over the user's completed games
+// @Tags         user
+// @Produce      json
+// @Param        id        path   string  true   "user id"
+// @Param        from      query  string  false  "only games completed at or after this RFC 3339 time"
+// @Param        to        query  string  false  "only games completed before this RFC 3339 time"
+// @Param        opponent  query  []string  false  "only games this user also played in; repeatable"
+// @Success      200  {object}  stats.Summary
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/user/{id}/stats [get]
+func (h *Handlers) UserStatsHandler(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.repo.Users().Get(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	var filter store.StatsFilter
+	for name, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be an RFC 3339 time"})
+				return
+			}
+			*t = parsed
+		}
+	}
+	for _, opponent := range c.QueryArray("opponent") {
+		filter.Opponents = append(filter.Opponents, strings.Split(opponent, ",")...)
+	}
+
+	rows, err := h.repo.Stats().List(id, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats.Summarize(id, rows))
+}
+
+// HeadToHeadHandler compares two users over the completed games they played
+// together.
+//
+// @Summary      Head-to-head record
+// @Description  Shared games, DONKEYs, rounds lost and cuts between two users
+// @Tags         user
+// @Produce      json
+// @Param        id       path  string  true  "user id"
+// @Param        otherId  path  string  true  "other user id"
+// @Success      200  {object}  stats.HeadToHead
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/user/{id}/vs/{otherId} [get]
+func (h *Handlers) HeadToHeadHandler(c *gin.Context) {
+	id, otherID := c.Param("id"), c.Param("otherId")
+	if id == otherID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a user has no record against themselves"})
+		return
+	}
+	record, err := stats.Versus(h.repo, id, otherID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
@@ -0,0 +1,57 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/api"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/server"
+	"github.com/kairodrad/donkey/internal/stats"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func TestUserStatsHeadToHeadAndBadges(t *testing.T) {
+	repo := store.NewMemory()
+	ts := httptest.NewServer(server.NewWithStore(repo))
+	defer ts.Close()
+	gameID := archivedGame(t, repo)
+	completed := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Stats().Record(gameID, []model.PlayerGameStats{
+		{UserID: "ann", CompletedAt: completed, Lost: true, RoundsPlayed: 1, RoundsLost: 1, FinishPositionSum: 2, TricksCollected: 1, CardsPickedUp: 2},
+		{UserID: "bob", CompletedAt: completed, RoundsPlayed: 1, FinishPositionSum: 1, Cuts: 1},
+	}))
+
+	var summary stats.Summary
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/ann/stats?opponent=bob", &summary))
+	assert.Equal(t, 1, summary.GamesLost)
+	assert.Equal(t, 2.0, summary.AverageCardsPickedUp)
+	summary = stats.Summary{}
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/ann/stats?from=2026-10-02T00:00:00Z", &summary))
+	assert.Zero(t, summary.GamesPlayed)
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, "/api/user/ann/stats?to=yesterday", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/user/nobody/stats", nil))
+
+	var h stats.HeadToHead
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/bob/vs/ann", &h))
+	assert.Equal(t, 1, h.SharedGames)
+	assert.Equal(t, 1, h.Players[0].Cuts, "bob cut and ann picked up")
+	assert.Equal(t, 1, h.Players[1].Donkeys)
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts, "/api/user/ann/vs/ann", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/user/ann/vs/nobody", nil))
+
+	require.NoError(t, repo.Achievements().Award(&model.Achievement{UserID: "bob", Badge: "clean_game", GameID: gameID, EarnedAt: completed}))
+	var badges []api.Badge
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/bob/achievements", &badges))
+	require.Len(t, badges, 1)
+	assert.Equal(t, "Spotless", badges[0].Name)
+	badges = nil
+	require.Equal(t, http.StatusOK, getJSON(t, ts, "/api/user/ann/achievements", &badges))
+	assert.Empty(t, badges)
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts, "/api/user/nobody/achievements", nil))
+}
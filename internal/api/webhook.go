@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/webhook"
+)
+
+// RegisterWebhookRequest represents a webhook registration
+type RegisterWebhookRequest struct {
+	UserID string   `json:"userId"`
+	GameID string   `json:"gameId,omitempty"` // Optional, limits the webhook to one game
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"` // Optional, defaults to all events
+}
+
+// RegisterWebhookHandler registers an outgoing webhook for a user or a game.
+//
+// @Summary      Register webhook
+// @Description  Registers a URL that receives signed JSON POSTs for game lifecycle events. The signing secret is only returned here.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body  RegisterWebhookRequest  true  "Webhook registration"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Router       /api/webhooks [post]
+func (h *Handlers) RegisterWebhookHandler(c *gin.Context) {
+	var req RegisterWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.UserID == "" || req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook"})
+		return
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be http or https"})
+		return
+	}
+
+	for _, e := range req.Events {
+		if !webhook.IsValidEvent(e) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown event: " + e})
+			return
+		}
+	}
+
+	if _, err := h.repo.Users().Get(req.UserID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	hook := model.Webhook{
+		ID:        model.NewID(),
+		OwnerID:   req.UserID,
+		URL:       req.URL,
+		Secret:    model.NewID(),
+		Events:    strings.Join(req.Events, ","),
+		Active:    true,
+		CreatedAt: time.Now(),
+	}
+
+	// Game webhooks can only be added by the game creator
+	if req.GameID != "" {
+		gameModel, err := h.repo.Games().Get(req.GameID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+			return
+		}
+		if gameModel.RequesterID != req.UserID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only game creator can add game webhooks"})
+			return
+		}
+		hook.GameID = &req.GameID
+	}
+
+	if err := h.repo.Webhooks().Create(&hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":     hook.ID,
+		"url":    hook.URL,
+		"gameId": hook.GameID,
+		"events": hook.Events,
+		"secret": hook.Secret,
+	})
+}
+
+// ListWebhooksHandler lists the webhooks owned by a user.
+//
+// @Summary      List webhooks
+// @Tags         webhooks
+// @Produce      json
+// @Param        userId  query  string  true  "Owner user ID"
+// @Success      200  {array}  model.Webhook
+// @Router       /api/webhooks [get]
+func (h *Handlers) ListWebhooksHandler(c *gin.Context) {
+	userID := c.Query("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing userId"})
+		return
+	}
+	hooks, err := h.repo.Webhooks().ListForOwner(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hooks == nil {
+		hooks = []model.Webhook{}
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// DeleteWebhookHandler removes a webhook owned by the user.
+//
+// @Summary      Delete webhook
+// @Tags         webhooks
+// @Param        id      path   string  true  "Webhook ID"
+// @Param        userId  query  string  true  "Owner user ID"
+// @Success      200
+// @Failure      404  {object}  map[string]string
+// @Router       /api/webhooks/{id} [delete]
+func (h *Handlers) DeleteWebhookHandler(c *gin.Context) {
+	hook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.Webhooks().Delete(hook.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// WebhookDeliveriesHandler returns the delivery log of a webhook.
+//
+// @Summary      List webhook deliveries
+// @Description  Returns recent delivery attempts for a webhook, newest first
+// @Tags         webhooks
+// @Produce      json
+// @Param        id      path   string  true  "Webhook ID"
+// @Param        userId  query  string  true  "Owner user ID"
+// @Success      200  {array}  model.WebhookDelivery
+// @Failure      404  {object}  map[string]string
+// @Router       /api/webhooks/{id}/deliveries [get]
+func (h *Handlers) WebhookDeliveriesHandler(c *gin.Context) {
+	hook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+	deliveries, err := h.repo.Webhooks().Deliveries(hook.ID, 100)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []model.WebhookDelivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ownedWebhook loads the webhook in the path, writing a 404 unless it belongs
+// to the userId query parameter.
+func (h *Handlers) ownedWebhook(c *gin.Context) (*model.Webhook, bool) {
+	hook, err := h.repo.Webhooks().Get(c.Param("id"), c.Query("userId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return nil, false
+	}
+	return hook, true
+}
@@ -0,0 +1,184 @@
+// Package archive compacts finished games into a single GameArchive record so
+// their per-card, per-turn detail rows can be removed.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/journal"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+const donkeyWord = "DONKEY"
+
+// Build assembles the archive record for a game from its detailed rows: its
+// players, and for every round the seating, the deal, each trick and the
+// letter it cost. The deal is read from the game's journal.
+func Build(repo store.Store, game model.Game) (*model.GameArchive, error) {
+	gamePlayers, err := repo.Games().Players(game.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game players: %w", err)
+	}
+	players := make([]model.ArchivedPlayer, 0, len(gamePlayers))
+	for _, gp := range gamePlayers {
+		players = append(players, model.ArchivedPlayer{
+			UserID:        gp.UserID,
+			Name:          gp.User.Name,
+			IsBot:         gp.User.IsBot,
+			BotDifficulty: gp.User.BotDifficulty,
+			JoinOrder:     gp.JoinOrder,
+			DonkeyLetters: gp.DonkeyLetters,
+		})
+	}
+
+	deals, err := dealsOf(repo, game.ID)
+	if err != nil {
+		return nil, err
+	}
+	rounds, err := repo.Rounds().List(game.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rounds: %w", err)
+	}
+	lettersSoFar := make(map[string]int)
+	archivedRounds := make([]model.ArchivedRound, 0, len(rounds))
+	for _, r := range rounds {
+		ar := model.ArchivedRound{
+			RoundNumber: r.RoundNumber,
+			LoserID:     r.LoserID,
+			StartedAt:   r.StartedAt,
+			CompletedAt: r.CompletedAt,
+			Seed:        r.Seed,
+			Deal:        deals[r.ID],
+		}
+		if r.LoserID != nil && lettersSoFar[*r.LoserID] < len(donkeyWord) {
+			ar.Letter = string(donkeyWord[lettersSoFar[*r.LoserID]])
+			lettersSoFar[*r.LoserID]++
+		}
+
+		seated, err := repo.Rounds().Players(r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load round players: %w", err)
+		}
+		for _, rp := range seated {
+			ar.Seats = append(ar.Seats, rp.UserID)
+		}
+		if r.Status == "completed" {
+			for _, rp := range FinishOrder(seated) {
+				ar.FinishOrder = append(ar.FinishOrder, rp.UserID)
+			}
+		}
+
+		turns, err := repo.Turns().List(r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load turns: %w", err)
+		}
+		for _, t := range turns {
+			trick := model.ArchivedTrick{
+				Number:        t.TurnNumber,
+				StartPlayerID: t.StartPlayerID,
+				LeadSuit:      deref(t.LeadSuit),
+				Status:        t.Status,
+				WinnerID:      deref(t.WinnerID),
+				CutPlayerID:   deref(t.CutPlayerID),
+				Plays:         make([]model.ArchivedPlay, 0, len(t.PlayedCards)),
+			}
+			for _, pc := range t.PlayedCards {
+				trick.Plays = append(trick.Plays, model.ArchivedPlay{PlayerID: pc.PlayerID, Card: pc.Card.CardCode()})
+			}
+			ar.Tricks = append(ar.Tricks, trick)
+		}
+		archivedRounds = append(archivedRounds, ar)
+	}
+
+	playersJSON, err := json.Marshal(players)
+	if err != nil {
+		return nil, err
+	}
+	roundsJSON, err := json.Marshal(archivedRounds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GameArchive{
+		GameID:      game.ID,
+		RequesterID: game.RequesterID,
+		LoserID:     game.LoserID,
+		Players:     string(playersJSON),
+		Rounds:      string(roundsJSON),
+		CreatedAt:   game.CreatedAt,
+		StartedAt:   game.StartedAt,
+		CompletedAt: game.CompletedAt,
+		ArchivedAt:  time.Now(),
+	}, nil
+}
+
+// FinishOrder sorts a round's players by when they ran out of cards; whoever
+// never did, the round's loser, comes last
+func FinishOrder(players []model.RoundPlayer) []model.RoundPlayer {
+	sorted := append([]model.RoundPlayer(nil), players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].FinishedAt, sorted[j].FinishedAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+	return sorted
+}
+
+// dealsOf reads who was dealt which cards in each round from a game's
+// journal, keyed by round ID
+func dealsOf(repo store.Store, gameID string) (map[string]map[string][]string, error) {
+	events, err := repo.Events().List(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal: %w", err)
+	}
+	deals := make(map[string]map[string][]string)
+	for _, ev := range events {
+		if ev.Type != journal.TypeDeal {
+			continue
+		}
+		payload, err := journal.Decode(ev)
+		if err != nil {
+			return nil, err
+		}
+		deal := payload.(*journal.DealPayload)
+		hands := make(map[string][]string)
+		for _, c := range deal.Cards {
+			card := model.Card{Suit: c.Suit, Rank: c.Rank}
+			hands[c.OwnerID] = append(hands[c.OwnerID], card.CardCode())
+		}
+		deals[deal.RoundID] = hands
+	}
+	return deals, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Compact stores the archive record for a game and then purges its detailed
+// rows. The game row, its players and its settings are kept so game lists
+// still work and a rated game stays rated. It returns the number of rows
+// deleted per table.
+func Compact(repo store.Store, gameID string) (map[string]int64, error) {
+	game, err := repo.Games().Get(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("game not found: %w", err)
+	}
+	record, err := Build(repo, *game)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Archives().Save(record); err != nil {
+		return nil, fmt.Errorf("failed to save archive: %w", err)
+	}
+	return repo.Games().PurgeDetails(gameID)
+}
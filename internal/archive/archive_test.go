@@ -0,0 +1,78 @@
+package archive_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/game"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func TestCompactedHistoryMatchesLiveHistory(t *testing.T) {
+	conn, err := gorm.Open(sqlite.Open("file:"+model.NewID()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := conn.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, migrations.Up(conn))
+	repo := store.NewGorm(conn)
+
+	now := time.Now()
+	ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+	bob := model.User{ID: model.NewID(), Name: "Bob", CreatedAt: now}
+	g := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "waiting", MaxPlayers: 8, MinPlayers: 2, CreatedAt: now}
+	require.NoError(t, repo.Users().Create(&ann))
+	require.NoError(t, repo.Users().Create(&bob))
+	require.NoError(t, repo.Games().Create(&g))
+	for i, u := range []model.User{ann, bob} {
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: u.ID, JoinOrder: i, JoinedAt: now, LastSeenAt: now}))
+	}
+	require.NoError(t, game.NewGameManager(repo, g.ID).StartGame())
+	round, err := repo.Rounds().Current(g.ID)
+	require.NoError(t, err)
+	ace, err := repo.Cards().Find(round.ID, "spades", "A")
+	require.NoError(t, err)
+	require.NoError(t, game.NewGameManager(repo, g.ID).PlayCard(*ace.OwnerID, ace.ID))
+
+	live, err := archive.Load(repo, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "active", live.Status)
+	assert.Nil(t, live.ArchivedAt)
+	require.Len(t, live.Rounds, 1)
+	r := live.Rounds[0]
+	assert.Equal(t, round.Seed, r.Seed)
+	assert.NotZero(t, r.Seed)
+	assert.Equal(t, []string{bob.ID, ann.ID}, r.Seats, "the requester sits last")
+	assert.Len(t, r.Deal[ann.ID], 26)
+	assert.Len(t, r.Deal[bob.ID], 26)
+	require.Len(t, r.Tricks, 1)
+	assert.Equal(t, "spades", r.Tricks[0].LeadSuit)
+	assert.Equal(t, []model.ArchivedPlay{{PlayerID: *ace.OwnerID, Card: "AS"}}, r.Tricks[0].Plays)
+
+	require.NoError(t, repo.Transaction(func(tx store.Store) error {
+		_, err := archive.Compact(tx, g.ID)
+		return err
+	}))
+	var n int64
+	require.NoError(t, conn.Model(&model.Round{}).Where("game_id = ?", g.ID).Count(&n).Error)
+	assert.Zero(t, n)
+	require.NoError(t, conn.Model(&model.GameEvent{}).Where("game_id = ?", g.ID).Count(&n).Error)
+	assert.NotZero(t, n, "the journal outlives compaction")
+
+	archived, err := archive.Load(repo, g.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+	assert.Equal(t, live.Players, archived.Players)
+	assert.Equal(t, live.Rounds, archived.Rounds, "the archive holds everything the detailed rows did")
+
+	_, err = archive.Load(repo, model.NewID())
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Record is the full history of a game: its players and, round by round, the
+// seating, deal, tricks and letters. Load serves it from the archive of a
+// compacted game and builds it from the live rows of any other game, so
+// readers need not care whether a game has been archived.
+type Record struct {
+	GameID      string                 `json:"gameId"`
+	RequesterID string                 `json:"requesterId"`
+	Status      string                 `json:"status"`
+	LoserID     *string                `json:"loserId,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	StartedAt   *time.Time             `json:"startedAt,omitempty"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+	ArchivedAt  *time.Time             `json:"archivedAt,omitempty"` // nil while the detailed rows are kept
+	Players     []model.ArchivedPlayer `json:"players"`
+	Rounds      []model.ArchivedRound  `json:"rounds"`
+}
+
+// Load returns the history of a game, or store.ErrNotFound if the game does
+// not exist
+func Load(repo store.Store, gameID string) (*Record, error) {
+	a, err := repo.Archives().Get(gameID)
+	if err == nil {
+		r, err := decode(a)
+		if err != nil {
+			return nil, err
+		}
+		r.Status, r.ArchivedAt = "completed", &a.ArchivedAt
+		return r, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load archive: %w", err)
+	}
+
+	game, err := repo.Games().Get(gameID)
+	if err != nil {
+		return nil, err
+	}
+	a, err = Build(repo, *game)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decode(a)
+	if err != nil {
+		return nil, err
+	}
+	r.Status = game.Status
+	return r, nil
+}
+
+func decode(a *model.GameArchive) (*Record, error) {
+	r := &Record{
+		GameID:      a.GameID,
+		RequesterID: a.RequesterID,
+		LoserID:     a.LoserID,
+		CreatedAt:   a.CreatedAt,
+		StartedAt:   a.StartedAt,
+		CompletedAt: a.CompletedAt,
+	}
+	if err := json.Unmarshal([]byte(a.Players), &r.Players); err != nil {
+		return nil, fmt.Errorf("failed to decode archived players: %w", err)
+	}
+	if err := json.Unmarshal([]byte(a.Rounds), &r.Rounds); err != nil {
+		return nil, fmt.Errorf("failed to decode archived rounds: %w", err)
+	}
+	return r, nil
+}
@@ -7,25 +7,58 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
 )
 
 var DB *gorm.DB
 
-// Init initializes the database connection. If DATABASE_URL is provided it
-// uses Postgres; otherwise it falls back to an in-memory sqlite database which
-// is useful for tests and CI.
-func Init(models ...interface{}) {
+// Open connects to the database without touching the schema. If DATABASE_URL
+// is provided it uses Postgres; if SQLITE_PATH is provided it uses a durable
+// SQLite file at that path; otherwise it falls back to an in-memory sqlite
+// database which is useful for tests and CI.
+func Open() error {
 	dsn := os.Getenv("DATABASE_URL")
 	var err error
-	if dsn != "" {
+	switch path := os.Getenv("SQLITE_PATH"); {
+	case dsn != "":
 		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	} else {
+	case path != "":
+		DB, err = openSQLiteFile(path)
+	default:
 		DB, err = gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+		if err == nil {
+			err = singleConnection(DB)
+		}
 	}
+	return err
+}
+
+// Configured reports whether DATABASE_URL or SQLITE_PATH names a database.
+// Without either Open starts an empty in-memory one, which commands that
+// read or keep data must refuse.
+func Configured() bool {
+	return os.Getenv("DATABASE_URL") != "" || os.Getenv("SQLITE_PATH") != ""
+}
+
+// singleConnection limits conn to one connection. In a shared-cache database
+// other connections fail with "table is locked" while a transaction is open
+// instead of waiting; with one connection they queue for it.
+func singleConnection(conn *gorm.DB) error {
+	sqlDB, err := conn.DB()
 	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return nil
+}
+
+// Init connects to the database and applies any pending schema migrations.
+func Init() {
+	if err := Open(); err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
-	if err := DB.AutoMigrate(models...); err != nil {
+	if err := migrations.Up(DB); err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 }
@@ -0,0 +1,160 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Baseline schema as it was created by AutoMigrate before migrations existed.
+
+type v1User struct {
+	ID            string `gorm:"primaryKey;size:32"`
+	Name          string `gorm:"size:20;not null"`
+	IsBot         bool   `gorm:"default:false"`
+	BotDifficulty string `gorm:"size:10"`
+	CreatedAt     time.Time
+}
+
+func (v1User) TableName() string { return "users" }
+
+type v1Game struct {
+	ID          string `gorm:"primaryKey;size:32"`
+	RequesterID string
+	Status      string `gorm:"size:20;default:'waiting'"`
+	MaxPlayers  int    `gorm:"default:8"`
+	MinPlayers  int    `gorm:"default:2"`
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	LoserID     *string
+}
+
+func (v1Game) TableName() string { return "games" }
+
+type v1GamePlayer struct {
+	GameID        string `gorm:"primaryKey;size:32"`
+	UserID        string `gorm:"primaryKey;size:32"`
+	JoinOrder     int
+	IsConnected   bool   `gorm:"default:true"`
+	DonkeyLetters string `gorm:"size:6;default:''"`
+	JoinedAt      time.Time
+	LastSeenAt    time.Time
+}
+
+func (v1GamePlayer) TableName() string { return "game_players" }
+
+type v1Round struct {
+	ID          string `gorm:"primaryKey;size:32"`
+	GameID      string `gorm:"size:32;index:idx_rounds_game_id"`
+	RoundNumber int
+	Status      string `gorm:"size:20;default:'setup'"`
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	LoserID     *string
+}
+
+func (v1Round) TableName() string { return "rounds" }
+
+type v1RoundPlayer struct {
+	RoundID     string `gorm:"primaryKey;size:32"`
+	UserID      string `gorm:"primaryKey;size:32"`
+	Position    int
+	IsFinished  bool `gorm:"default:false"`
+	FinishedAt  *time.Time
+	CardsInHand int `gorm:"default:0"`
+}
+
+func (v1RoundPlayer) TableName() string { return "round_players" }
+
+type v1Turn struct {
+	ID            string `gorm:"primaryKey;size:32"`
+	RoundID       string `gorm:"size:32;index:idx_turns_round_id"`
+	TurnNumber    int
+	StartPlayerID string
+	LeadSuit      *string `gorm:"size:10"`
+	Status        string  `gorm:"size:20;default:'active'"`
+	WinnerID      *string
+	CutPlayerID   *string
+	StartedAt     time.Time
+	CompletedAt   *time.Time
+}
+
+func (v1Turn) TableName() string { return "turns" }
+
+type v1Card struct {
+	ID        string `gorm:"primaryKey;size:32"`
+	RoundID   string `gorm:"size:32;index:idx_cards_round_id"`
+	Suit      string `gorm:"size:10;not null"`
+	Rank      string `gorm:"size:5;not null"`
+	Value     int
+	Location  string  `gorm:"size:20;default:'deck'"`
+	OwnerID   *string `gorm:"size:32"`
+	SortOrder int
+}
+
+func (v1Card) TableName() string { return "cards" }
+
+type v1PlayedCard struct {
+	ID        string `gorm:"primaryKey;size:32"`
+	TurnID    string `gorm:"size:32;index:idx_played_cards_turn_id"`
+	CardID    string `gorm:"size:32"`
+	PlayerID  string `gorm:"size:32"`
+	PlayOrder int
+	PlayedAt  time.Time
+}
+
+func (v1PlayedCard) TableName() string { return "played_cards" }
+
+type v1BotMemory struct {
+	ID         string  `gorm:"primaryKey;size:32"`
+	BotUserID  string  `gorm:"size:32;index:idx_bot_memories_bot_user_id"`
+	GameID     string  `gorm:"size:32;index:idx_bot_memories_game_id"`
+	MemoryType string  `gorm:"size:20"`
+	MemoryData string  `gorm:"type:text"`
+	Confidence float64 `gorm:"default:1.0"`
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+}
+
+func (v1BotMemory) TableName() string { return "bot_memories" }
+
+type v1GameSessionLog struct {
+	ID        string  `gorm:"primaryKey;size:32"`
+	GameID    string  `gorm:"size:32;index:idx_game_session_logs_game_id"`
+	UserID    *string `gorm:"size:32"`
+	Type      string  `gorm:"size:20"`
+	Message   string  `gorm:"not null"`
+	EventData string  `gorm:"type:text"`
+	CreatedAt time.Time
+}
+
+func (v1GameSessionLog) TableName() string { return "game_session_logs" }
+
+type v1GameSettings struct {
+	GameID              string `gorm:"primaryKey;size:32"`
+	AutoStartAt8Players bool   `gorm:"default:true"`
+	AllowBots           bool   `gorm:"default:true"`
+	MaxBots             int    `gorm:"default:6"`
+	TurnTimeoutSeconds  int    `gorm:"default:30"`
+	PauseOnDisconnect   bool   `gorm:"default:true"`
+}
+
+func (v1GameSettings) TableName() string { return "game_settings" }
+
+func init() {
+	tables := []interface{}{
+		&v1User{}, &v1Game{}, &v1GamePlayer{}, &v1Round{}, &v1RoundPlayer{}, &v1Turn{},
+		&v1Card{}, &v1PlayedCard{}, &v1BotMemory{}, &v1GameSessionLog{}, &v1GameSettings{},
+	}
+	register(Migration{
+		Version: 1,
+		Name:    "initial",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, tables...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, tables...)
+		},
+	})
+}
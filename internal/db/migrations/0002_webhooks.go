@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type v2Webhook struct {
+	ID        string  `gorm:"primaryKey;size:32"`
+	OwnerID   string  `gorm:"size:32;index:idx_webhooks_owner_id;not null"`
+	GameID    *string `gorm:"size:32;index:idx_webhooks_game_id"`
+	URL       string  `gorm:"size:2048;not null"`
+	Secret    string  `gorm:"size:64;not null"`
+	Events    string  `gorm:"size:255"`
+	Active    bool    `gorm:"default:true"`
+	CreatedAt time.Time
+}
+
+func (v2Webhook) TableName() string { return "webhooks" }
+
+type v2WebhookDelivery struct {
+	ID          string `gorm:"primaryKey;size:32"`
+	WebhookID   string `gorm:"size:32;index:idx_webhook_deliveries_webhook_id"`
+	GameID      string `gorm:"size:32;index:idx_webhook_deliveries_game_id"`
+	Event       string `gorm:"size:30"`
+	Payload     string `gorm:"type:text"`
+	Status      string `gorm:"size:20;default:'pending'"`
+	Attempts    int    `gorm:"default:0"`
+	StatusCode  int
+	LastError   string `gorm:"type:text"`
+	CreatedAt   time.Time
+	DeliveredAt *time.Time
+}
+
+func (v2WebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "webhooks",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &v2Webhook{}, &v2WebhookDelivery{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &v2Webhook{}, &v2WebhookDelivery{})
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type v3GameArchive struct {
+	GameID      string  `gorm:"primaryKey;size:32"`
+	RequesterID string  `gorm:"size:32"`
+	LoserID     *string `gorm:"size:32"`
+	Players     string  `gorm:"type:text"`
+	Rounds      string  `gorm:"type:text"`
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	ArchivedAt  time.Time
+}
+
+func (v3GameArchive) TableName() string { return "game_archives" }
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "game_archives",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &v3GameArchive{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &v3GameArchive{})
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Games, rounds and turns gain a version column for optimistic concurrency;
+// existing rows start at version 0.
+
+type v4Game struct {
+	Version int `gorm:"not null;default:0"`
+}
+
+func (v4Game) TableName() string { return "games" }
+
+type v4Round struct {
+	Version int `gorm:"not null;default:0"`
+}
+
+func (v4Round) TableName() string { return "rounds" }
+
+type v4Turn struct {
+	Version int `gorm:"not null;default:0"`
+}
+
+func (v4Turn) TableName() string { return "turns" }
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "row_versions",
+		Up: func(tx *gorm.DB) error {
+			for _, t := range []interface{}{&v4Game{}, &v4Round{}, &v4Turn{}} {
+				if tx.Migrator().HasColumn(t, "Version") {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(t, "Version"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, t := range []interface{}{&v4Game{}, &v4Round{}, &v4Turn{}} {
+				if err := tx.Migrator().DropColumn(t, "Version"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type v5GameEvent struct {
+	ID        string `gorm:"primaryKey;size:32"`
+	GameID    string `gorm:"size:32;not null;uniqueIndex:idx_game_events_seq"`
+	Seq       int    `gorm:"not null;uniqueIndex:idx_game_events_seq"`
+	Type      string `gorm:"size:20;not null"`
+	Payload   string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+func (v5GameEvent) TableName() string { return "game_events" }
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "game_events",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &v5GameEvent{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &v5GameEvent{})
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Rounds record the seed their deck was shuffled with; rounds dealt before
+// this keep seed 0.
+
+type v6Round struct {
+	Seed int64 `gorm:"not null;default:0"`
+}
+
+func (v6Round) TableName() string { return "rounds" }
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "round_seeds",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&v6Round{}, "Seed") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&v6Round{}, "Seed")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v6Round{}, "Seed")
+		},
+	})
+}
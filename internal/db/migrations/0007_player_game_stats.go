@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Games completed before this are not counted until `server stats rebuild`
+// is run.
+
+type v7PlayerGameStats struct {
+	GameID            string    `gorm:"primaryKey;size:32"`
+	UserID            string    `gorm:"primaryKey;size:32;index"`
+	CompletedAt       time.Time `gorm:"index"`
+	Lost              bool      `gorm:"not null;default:false"`
+	RoundsPlayed      int       `gorm:"not null;default:0"`
+	RoundsLost        int       `gorm:"not null;default:0"`
+	FinishPositionSum int       `gorm:"not null;default:0"`
+	Cuts              int       `gorm:"not null;default:0"`
+	TricksCollected   int       `gorm:"not null;default:0"`
+	CardsPickedUp     int       `gorm:"not null;default:0"`
+}
+
+func (v7PlayerGameStats) TableName() string { return "player_game_stats" }
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "player_game_stats",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &v7PlayerGameStats{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &v7PlayerGameStats{})
+		},
+	})
+}
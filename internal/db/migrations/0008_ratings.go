@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Games are rated unless created as casual; games created before this are
+// rated too.
+
+type v8GameSettings struct {
+	Rated bool `gorm:"not null;default:true"`
+}
+
+func (v8GameSettings) TableName() string { return "game_settings" }
+
+type v8Rating struct {
+	SubjectID string  `gorm:"primaryKey;size:40"`
+	Rating    float64 `gorm:"not null"`
+	Games     int     `gorm:"not null;default:0"`
+	UpdatedAt time.Time
+}
+
+func (v8Rating) TableName() string { return "ratings" }
+
+type v8RatingChange struct {
+	ID        string  `gorm:"primaryKey;size:32"`
+	SubjectID string  `gorm:"size:40;not null;index"`
+	GameID    string  `gorm:"size:32;not null;index"`
+	Before    float64 `gorm:"not null"`
+	After     float64 `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (v8RatingChange) TableName() string { return "rating_changes" }
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "ratings",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&v8GameSettings{}, "Rated") {
+				if err := tx.Migrator().AddColumn(&v8GameSettings{}, "Rated"); err != nil {
+					return err
+				}
+			}
+			return createTables(tx, &v8Rating{}, &v8RatingChange{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropTables(tx, &v8Rating{}, &v8RatingChange{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&v8GameSettings{}, "Rated")
+		},
+	})
+}
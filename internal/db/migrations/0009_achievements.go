@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type v9Achievement struct {
+	UserID   string `gorm:"primaryKey;size:32"`
+	Badge    string `gorm:"primaryKey;size:32"`
+	GameID   string `gorm:"size:32;not null"`
+	EarnedAt time.Time
+}
+
+func (v9Achievement) TableName() string { return "achievements" }
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "achievements",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &v9Achievement{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &v9Achievement{})
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type v10ShareLink struct {
+	Token     string `gorm:"primaryKey;size:32"`
+	GameID    string `gorm:"size:32;not null;uniqueIndex"`
+	CreatedBy string `gorm:"size:32;not null"`
+	CreatedAt time.Time
+}
+
+func (v10ShareLink) TableName() string { return "share_links" }
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "share_links",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &v10ShareLink{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &v10ShareLink{})
+		},
+	})
+}
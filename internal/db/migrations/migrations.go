@@ -0,0 +1,182 @@
+// Package migrations keeps the database schema under version control. Each
+// migration has a unique, increasing version and an Up and Down step; applied
+// versions are recorded in the schema_migrations table.
+//
+// Migrations describe tables with their own frozen structs rather than the
+// live types in internal/model, so that editing a model never changes what an
+// old migration does. Every schema change to a model needs a new migration.
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single, versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Status describes whether a known migration has been applied
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// schemaMigration is a row of the schema_migrations table
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:100"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+var registry []Migration
+
+// register adds a migration to the registry; called from init in each
+// migration file.
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("duplicate migration version %d", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// All returns every known migration in version order
+func All() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// applied returns the recorded migrations keyed by version
+func applied(db *gorm.DB) (map[int]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	done := make(map[int]schemaMigration, len(rows))
+	for _, r := range rows {
+		done[r.Version] = r
+	}
+	return done, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction
+func Up(db *gorm.DB) error {
+	done, err := applied(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range registry {
+		if _, ok := done[m.Version]; ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		}); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// Down reverts the most recently applied migrations, newest first. A steps
+// value of zero or less reverts nothing.
+func Down(db *gorm.DB, steps int) error {
+	done, err := applied(db)
+	if err != nil {
+		return err
+	}
+	for i := len(registry) - 1; i >= 0 && steps > 0; i-- {
+		m := registry[i]
+		if _, ok := done[m.Version]; !ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", m.Version).Error
+		}); err != nil {
+			return fmt.Errorf("reverting migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		steps--
+	}
+	return nil
+}
+
+// Current returns the highest applied version, or zero for an empty database
+func Current(db *gorm.DB) (int, error) {
+	done, err := applied(db)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for v := range done {
+		if v > current {
+			current = v
+		}
+	}
+	return current, nil
+}
+
+// List reports the status of every known migration
+func List(db *gorm.DB) ([]Status, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(registry))
+	for _, m := range registry {
+		st := Status{Version: m.Version, Name: m.Name}
+		if row, ok := done[m.Version]; ok {
+			at := row.AppliedAt
+			st.Applied = true
+			st.AppliedAt = &at
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// createTables creates each table that does not exist yet. Databases created
+// by the old AutoMigrate boot path already have the baseline tables, so the
+// initial migrations adopt them instead of failing.
+func createTables(tx *gorm.DB, tables ...interface{}) error {
+	for _, t := range tables {
+		if tx.Migrator().HasTable(t) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropTables drops each table that exists
+func dropTables(tx *gorm.DB, tables ...interface{}) error {
+	for _, t := range tables {
+		if err := tx.Migrator().DropTable(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package migrations_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// models lists every persisted model; keep in sync with internal/model.
+var models = []interface{}{
+	&model.User{}, &model.Game{}, &model.GamePlayer{}, &model.Round{}, &model.RoundPlayer{},
+	&model.Turn{}, &model.Card{}, &model.PlayedCard{}, &model.BotMemory{}, &model.GameSessionLog{},
+	&model.GameSettings{}, &model.Webhook{}, &model.WebhookDelivery{}, &model.GameArchive{}, &model.GameEvent{},
+	&model.PlayerGameStats{}, &model.Rating{}, &model.RatingChange{}, &model.Achievement{},
+	&model.ShareLink{},
+}
+
+func openSQLite(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	return conn
+}
+
+func columns(t *testing.T, conn *gorm.DB, table string) []string {
+	t.Helper()
+	types, err := conn.Migrator().ColumnTypes(table)
+	require.NoError(t, err)
+	var names []string
+	for _, ct := range types {
+		names = append(names, ct.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestMigrationsRunUpDownAndUpAgain(t *testing.T) {
+	conn := openSQLite(t, "migrations_roundtrip")
+	all := migrations.All()
+	require.NotEmpty(t, all)
+
+	require.NoError(t, migrations.Up(conn))
+	current, err := migrations.Current(conn)
+	require.NoError(t, err)
+	assert.Equal(t, all[len(all)-1].Version, current)
+
+	// Re-running is a no-op
+	require.NoError(t, migrations.Up(conn))
+
+	// Revert one at a time down to an empty schema
+	for i := len(all) - 1; i >= 0; i-- {
+		require.NoError(t, migrations.Down(conn, 1), "reverting %d", all[i].Version)
+		current, err := migrations.Current(conn)
+		require.NoError(t, err)
+		if i > 0 {
+			assert.Equal(t, all[i-1].Version, current)
+		} else {
+			assert.Equal(t, 0, current)
+		}
+	}
+	for _, m := range models {
+		assert.False(t, conn.Migrator().HasTable(m), "%T should be dropped", m)
+	}
+
+	require.NoError(t, migrations.Up(conn))
+	statuses, err := migrations.List(conn)
+	require.NoError(t, err)
+	for _, st := range statuses {
+		assert.True(t, st.Applied, "migration %d should be applied", st.Version)
+	}
+}
+
+func TestMigratedSchemaMatchesModels(t *testing.T) {
+	migrated := openSQLite(t, "migrations_schema")
+	require.NoError(t, migrations.Up(migrated))
+
+	// A database created by the old AutoMigrate boot path is adopted as-is
+	legacy := openSQLite(t, "migrations_legacy")
+	require.NoError(t, legacy.AutoMigrate(models...))
+	require.NoError(t, migrations.Up(legacy))
+
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: legacy}
+		require.NoError(t, stmt.Parse(m))
+		table := stmt.Schema.Table
+		assert.True(t, migrated.Migrator().HasTable(table), "missing table %s", table)
+		assert.Equal(t, columns(t, legacy, table), columns(t, migrated, table), "columns of %s", table)
+	}
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// defaultBusyTimeoutMS is how long a SQLite connection waits on a locked
+// database before giving up. Game goroutines write concurrently, so a
+// generous wait beats surfacing "database is locked" errors.
+const defaultBusyTimeoutMS = 5000
+
+// sqliteFileDSN builds the DSN for a durable SQLite database: WAL journaling
+// so readers never block the writer, a busy timeout for concurrent writers
+// and immediate transactions so writers queue up front instead of failing
+// when a read lock cannot be upgraded.
+func sqliteFileDSN(path string, busyTimeoutMS int) string {
+	q := url.Values{}
+	q.Set("_journal_mode", "WAL")
+	q.Set("_busy_timeout", strconv.Itoa(busyTimeoutMS))
+	q.Set("_synchronous", "NORMAL")
+	q.Set("_txlock", "immediate")
+	return "file:" + path + "?" + q.Encode()
+}
+
+// openSQLiteFile opens (creating if needed) a SQLite database at path.
+// SQLITE_BUSY_TIMEOUT_MS overrides the default busy timeout.
+func openSQLiteFile(path string) (*gorm.DB, error) {
+	busy := defaultBusyTimeoutMS
+	if raw := os.Getenv("SQLITE_BUSY_TIMEOUT_MS"); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid SQLITE_BUSY_TIMEOUT_MS %q", raw)
+		}
+		busy = ms
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+	return gorm.Open(sqlite.Open(sqliteFileDSN(path, busy)), &gorm.Config{})
+}
+
+// IsSQLite reports whether the open database is SQLite
+func IsSQLite() bool {
+	return DB != nil && DB.Dialector.Name() == "sqlite"
+}
+
+// Backup writes a consistent copy of the open SQLite database to dest while
+// the server keeps running. dest must not exist yet.
+func Backup(dest string) error {
+	if !IsSQLite() {
+		return errors.New("online backup is only supported for SQLite; use pg_dump for Postgres")
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup destination %s already exists", dest)
+	}
+	if dir := filepath.Dir(dest); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+	if err := DB.Exec("VACUUM INTO ?", dest).Error; err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSQLiteFileUsesWALAndBacksUpOnline(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("SQLITE_PATH", "")
+	assert.False(t, Configured(), "nothing names a database")
+	t.Setenv("SQLITE_PATH", filepath.Join(dir, "data", "donkey.db"))
+	assert.True(t, Configured())
+	require.NoError(t, Open())
+
+	var mode string
+	require.NoError(t, DB.Raw("PRAGMA journal_mode").Scan(&mode).Error)
+	assert.Equal(t, "wal", mode)
+	var busy int
+	require.NoError(t, DB.Raw("PRAGMA busy_timeout").Scan(&busy).Error)
+	assert.Equal(t, defaultBusyTimeoutMS, busy)
+
+	require.NoError(t, DB.Exec("CREATE TABLE notes (body TEXT)").Error)
+	require.NoError(t, DB.Exec("INSERT INTO notes (body) VALUES ('kept')").Error)
+
+	dest := filepath.Join(dir, "backup.db")
+	require.NoError(t, Backup(dest))
+	assert.Error(t, Backup(dest), "existing backups are not overwritten")
+
+	copyDB, err := gorm.Open(sqlite.Open(dest), &gorm.Config{})
+	require.NoError(t, err)
+	var body string
+	require.NoError(t, copyDB.Raw("SELECT body FROM notes").Scan(&body).Error)
+	assert.Equal(t, "kept", body)
+}
@@ -0,0 +1,186 @@
+// Package dump moves the whole database in and out of a portable JSON
+// archive, so a family's history can be backed up or carried from SQLite to
+// Postgres and back.
+package dump
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// FormatVersion is bumped whenever the layout of Archive changes
+const FormatVersion = 1
+
+// batchSize keeps each insert well below SQLite's bound variable limit
+const batchSize = 100
+
+// Archive is the exported content of a database. Rows are kept verbatim,
+// IDs included, so every relationship survives the trip. Webhooks are left
+// out: their signing secrets never leave the server.
+type Archive struct {
+	Format        int       `json:"format"`
+	SchemaVersion int       `json:"schemaVersion"` // migration the source database was at
+	ExportedAt    time.Time `json:"exportedAt"`
+
+	Users         []model.User            `json:"users"`
+	Games         []model.Game            `json:"games"`
+	GamePlayers   []model.GamePlayer      `json:"gamePlayers"`
+	GameSettings  []model.GameSettings    `json:"gameSettings"`
+	Rounds        []model.Round           `json:"rounds"`
+	RoundPlayers  []model.RoundPlayer     `json:"roundPlayers"`
+	Turns         []model.Turn            `json:"turns"`
+	Cards         []model.Card            `json:"cards"`
+	PlayedCards   []model.PlayedCard      `json:"playedCards"`
+	SessionLogs   []model.GameSessionLog  `json:"sessionLogs"`
+	BotMemories   []model.BotMemory       `json:"botMemories"`
+	Events        []model.GameEvent       `json:"events"`
+	GameArchives  []model.GameArchive     `json:"gameArchives"`
+	PlayerStats   []model.PlayerGameStats `json:"playerGameStats"`
+	Ratings       []model.Rating          `json:"ratings"`
+	RatingChanges []model.RatingChange    `json:"ratingChanges"`
+	Achievements  []model.Achievement     `json:"achievements"`
+	ShareLinks    []model.ShareLink       `json:"shareLinks"`
+}
+
+// table is one exported table and the archive field holding its rows
+type table struct {
+	name string
+	rows interface{} // pointer to a slice of models
+}
+
+// tables lists the archive's tables parents first, so an import never
+// inserts a row before the rows it refers to
+func (a *Archive) tables() []table {
+	return []table{
+		{"users", &a.Users},
+		{"games", &a.Games},
+		{"game_players", &a.GamePlayers},
+		{"game_settings", &a.GameSettings},
+		{"rounds", &a.Rounds},
+		{"round_players", &a.RoundPlayers},
+		{"turns", &a.Turns},
+		{"cards", &a.Cards},
+		{"played_cards", &a.PlayedCards},
+		{"game_session_logs", &a.SessionLogs},
+		{"bot_memories", &a.BotMemories},
+		{"game_events", &a.Events},
+		{"game_archives", &a.GameArchives},
+		{"player_game_stats", &a.PlayerStats},
+		{"ratings", &a.Ratings},
+		{"rating_changes", &a.RatingChanges},
+		{"achievements", &a.Achievements},
+		{"share_links", &a.ShareLinks},
+	}
+}
+
+// Export writes every table of conn to w as an Archive. The tables are read
+// in one transaction so the archive is a consistent snapshot even while a
+// server is writing.
+func Export(conn *gorm.DB, w io.Writer) error {
+	schema, err := migrations.Current(conn)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	a := Archive{Format: FormatVersion, SchemaVersion: schema, ExportedAt: time.Now().UTC()}
+	err = conn.Transaction(func(tx *gorm.DB) error {
+		for _, t := range a.tables() {
+			if err := tx.Find(t.rows).Error; err != nil {
+				return fmt.Errorf("failed to read %s: %w", t.name, err)
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(&a); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// Import reads an Archive from r and inserts it into conn, which must be
+// migrated and hold no users or games yet. Everything is inserted in one
+// transaction, so a failed import leaves the database empty. It returns the
+// number of rows inserted per table.
+func Import(conn *gorm.DB, r io.Reader) (map[string]int, error) {
+	var a Archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if a.Format != FormatVersion {
+		return nil, fmt.Errorf("unsupported archive format %d, expected %d", a.Format, FormatVersion)
+	}
+	known := migrations.All()
+	if latest := known[len(known)-1].Version; a.SchemaVersion > latest {
+		return nil, fmt.Errorf("archive is from schema version %d, newer than this build's %d", a.SchemaVersion, latest)
+	}
+
+	for _, m := range []interface{}{&model.User{}, &model.Game{}} {
+		var n int64
+		if err := conn.Model(m).Count(&n).Error; err != nil {
+			return nil, fmt.Errorf("failed to inspect database: %w", err)
+		}
+		if n > 0 {
+			return nil, errors.New("database is not empty; import needs a fresh database")
+		}
+	}
+
+	inserted := make(map[string]int)
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		for _, t := range a.tables() {
+			n, err := insert(tx, t.rows)
+			if err != nil {
+				return fmt.Errorf("failed to insert %s: %w", t.name, err)
+			}
+			inserted[t.name] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+// insert writes rows, a pointer to a slice of models, column by column.
+// Creating the models directly would swap zero values such as a false
+// IsConnected for the column default.
+func insert(tx *gorm.DB, rows interface{}) (int, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(rows); err != nil {
+		return 0, err
+	}
+	slice := reflect.Indirect(reflect.ValueOf(rows))
+	if slice.Len() == 0 {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	values := make([]map[string]interface{}, slice.Len())
+	for i := range values {
+		row := make(map[string]interface{}, len(stmt.Schema.DBNames))
+		for _, name := range stmt.Schema.DBNames {
+			row[name], _ = stmt.Schema.FieldsByDBName[name].ValueOf(ctx, slice.Index(i))
+		}
+		values[i] = row
+	}
+	if err := tx.Table(stmt.Schema.Table).CreateInBatches(values, batchSize).Error; err != nil {
+		return 0, err
+	}
+	return len(values), nil
+}
@@ -0,0 +1,84 @@
+package dump_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/dump"
+	"github.com/kairodrad/donkey/internal/game"
+	"github.com/kairodrad/donkey/internal/journal"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open("file:"+model.NewID()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := conn.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, migrations.Up(conn))
+	return conn
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := openDB(t)
+	repo := store.NewGorm(src)
+	now := time.Now()
+	ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+	bob := model.User{ID: model.NewID(), Name: "Bob", CreatedAt: now}
+	g := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "waiting", MaxPlayers: 8, MinPlayers: 2, CreatedAt: now}
+	require.NoError(t, repo.Users().Create(&ann))
+	require.NoError(t, repo.Users().Create(&bob))
+	require.NoError(t, repo.Games().Create(&g))
+	for i, u := range []model.User{ann, bob} {
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: u.ID, JoinOrder: i, JoinedAt: now, LastSeenAt: now}))
+	}
+	require.NoError(t, src.Create(&model.GameSettings{GameID: g.ID, MaxBots: 2, TurnTimeoutSeconds: 30}).Error)
+	require.NoError(t, game.NewGameManager(repo, g.ID).StartGame())
+	require.NoError(t, src.Model(&model.GamePlayer{}).Where("user_id = ?", bob.ID).Update("is_connected", false).Error)
+	require.NoError(t, src.Model(&model.GameSettings{}).Where("game_id = ?", g.ID).Update("allow_bots", false).Error)
+
+	var buf bytes.Buffer
+	require.NoError(t, dump.Export(src, &buf))
+	archive := buf.Bytes()
+
+	dst := openDB(t)
+	counts, err := dump.Import(dst, bytes.NewReader(archive))
+	require.NoError(t, err)
+	assert.Equal(t, 2, counts["users"])
+	assert.Equal(t, 52, counts["cards"])
+	assert.Equal(t, 1, counts["game_events"])
+
+	diffs, err := journal.Verify(store.NewGorm(dst), g.ID)
+	require.NoError(t, err)
+	assert.Empty(t, diffs, "the copy matches its own journal")
+
+	var gp model.GamePlayer
+	require.NoError(t, dst.Where("user_id = ?", bob.ID).First(&gp).Error)
+	assert.False(t, gp.IsConnected, "zero values are not replaced by column defaults")
+	var settings model.GameSettings
+	require.NoError(t, dst.First(&settings, "game_id = ?", g.ID).Error)
+	assert.False(t, settings.AllowBots)
+	assert.Equal(t, 2, settings.MaxBots)
+
+	var again bytes.Buffer
+	require.NoError(t, dump.Export(dst, &again))
+	var before, after dump.Archive
+	require.NoError(t, json.Unmarshal(archive, &before))
+	require.NoError(t, json.Unmarshal(again.Bytes(), &after))
+	after.ExportedAt = before.ExportedAt
+	assert.Equal(t, before, after, "a re-export carries the same rows")
+
+	_, err = dump.Import(dst, bytes.NewReader(archive))
+	assert.Error(t, err, "an import never merges into existing data")
+}
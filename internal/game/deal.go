@@ -1,8 +1,8 @@
 package game
 
 import (
-	"github.com/kairodrad/donkey/internal/db"
 	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
 )
 
 // DealCards is deprecated - use GameManager.StartGame() instead
@@ -27,13 +27,13 @@ type StateResponse struct {
 }
 
 // BuildState builds a StateResponse for a given game and user.
-func BuildState(gameID, userID string) (StateResponse, error) {
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", gameID).Error; err != nil {
+func BuildState(repo store.Store, gameID, userID string) (StateResponse, error) {
+	game, err := repo.Games().Get(gameID)
+	if err != nil {
 		return StateResponse{}, err
 	}
-	var players []model.GamePlayer
-	if err := db.DB.Preload("User").Where("game_id = ?", gameID).Order("join_order asc").Find(&players).Error; err != nil {
+	players, err := repo.Games().Players(gameID)
+	if err != nil {
 		return StateResponse{}, err
 	}
 
@@ -42,9 +42,7 @@ func BuildState(gameID, userID string) (StateResponse, error) {
 	// Get current round if game is active
 	var currentRoundID string
 	if game.Status == "active" {
-		var round model.Round
-		if err := db.DB.Where("game_id = ? AND status IN ('dealing', 'active')", gameID).
-			Order("round_number DESC").First(&round).Error; err == nil {
+		if round, err := repo.Rounds().Current(gameID); err == nil {
 			currentRoundID = round.ID
 		}
 	}
@@ -55,20 +53,15 @@ func BuildState(gameID, userID string) (StateResponse, error) {
 		if game.Status == "active" && currentRoundID != "" {
 			if p.UserID == userID {
 				// Get user's cards from current round
-				var userCards []model.Card
-				if err := db.DB.Where("round_id = ? AND owner_id = ? AND location = 'hand'", currentRoundID, userID).
-					Order("sort_order").Find(&userCards).Error; err == nil {
+				if userCards, err := repo.Cards().Hand(currentRoundID, userID); err == nil {
 					for _, c := range userCards {
 						ps.Cards = append(ps.Cards, c.CardCode())
 					}
 				}
 			} else {
 				// Count other player's cards
-				var cardCount int64
-				if err := db.DB.Model(&model.Card{}).
-					Where("round_id = ? AND owner_id = ? AND location = 'hand'", currentRoundID, p.UserID).
-					Count(&cardCount).Error; err == nil {
-					ps.CardCount = int(cardCount)
+				if hand, err := repo.Cards().Hand(currentRoundID, p.UserID); err == nil {
+					ps.CardCount = len(hand)
 				}
 			}
 		}
@@ -78,13 +71,13 @@ func BuildState(gameID, userID string) (StateResponse, error) {
 }
 
 // BuildAdminState returns full state exposing all player cards.
-func BuildAdminState(gameID string) (StateResponse, error) {
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", gameID).Error; err != nil {
+func BuildAdminState(repo store.Store, gameID string) (StateResponse, error) {
+	game, err := repo.Games().Get(gameID)
+	if err != nil {
 		return StateResponse{}, err
 	}
-	var players []model.GamePlayer
-	if err := db.DB.Preload("User").Where("game_id = ?", gameID).Order("join_order asc").Find(&players).Error; err != nil {
+	players, err := repo.Games().Players(gameID)
+	if err != nil {
 		return StateResponse{}, err
 	}
 
@@ -93,9 +86,7 @@ func BuildAdminState(gameID string) (StateResponse, error) {
 	// Get current round if game is active
 	var currentRoundID string
 	if game.Status == "active" {
-		var round model.Round
-		if err := db.DB.Where("game_id = ? AND status IN ('dealing', 'active')", gameID).
-			Order("round_number DESC").First(&round).Error; err == nil {
+		if round, err := repo.Rounds().Current(gameID); err == nil {
 			currentRoundID = round.ID
 		}
 	}
@@ -105,9 +96,7 @@ func BuildAdminState(gameID string) (StateResponse, error) {
 		
 		if game.Status == "active" && currentRoundID != "" {
 			// Get all player's cards for admin view
-			var playerCards []model.Card
-			if err := db.DB.Where("round_id = ? AND owner_id = ? AND location = 'hand'", currentRoundID, p.UserID).
-				Order("sort_order").Find(&playerCards).Error; err == nil {
+			if playerCards, err := repo.Cards().Hand(currentRoundID, p.UserID); err == nil {
 				for _, c := range playerCards {
 					ps.Cards = append(ps.Cards, c.CardCode())
 				}
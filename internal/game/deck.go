@@ -44,6 +44,14 @@ func (c Card) AssetPath() string {
 	return filepath.Join(assetDir, fmt.Sprintf("%s.png", string(c)))
 }
 
+// BackColors lists the colors card backs come in.
+var BackColors = []string{"blue", "green", "gray", "purple", "red", "yellow"}
+
+// BackAssetPath returns the asset path for the card back of the given color.
+func BackAssetPath(color string) string {
+	return filepath.Join(assetDir, fmt.Sprintf("%s_back.png", color))
+}
+
 // VerifyAssets ensures all card assets exist. It fatally logs if any are missing.
 func VerifyAssets() {
 	for _, card := range Deck() {
@@ -52,10 +60,8 @@ func VerifyAssets() {
 		}
 	}
 	// also check backs
-	backs := []string{"blue", "green", "gray", "purple", "red", "yellow"}
-	for _, b := range backs {
-		path := filepath.Join(assetDir, fmt.Sprintf("%s_back.png", b))
-		if _, err := os.Stat(path); err != nil {
+	for _, b := range BackColors {
+		if _, err := os.Stat(BackAssetPath(b)); err != nil {
 			log.Fatalf("missing asset %s_back.png: %v", b, err)
 		}
 	}
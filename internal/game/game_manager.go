@@ -3,19 +3,29 @@ package game
 import (
 	"errors"
 	"fmt"
+	"log"
 	"encoding/json"
 	"math/rand"
+	"sort"
 	"time"
 
-	"github.com/kairodrad/donkey/internal/db"
+	"github.com/kairodrad/donkey/internal/achievement"
+	"github.com/kairodrad/donkey/internal/journal"
 	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/rating"
+	"github.com/kairodrad/donkey/internal/stats"
+	"github.com/kairodrad/donkey/internal/store"
+	"github.com/kairodrad/donkey/internal/webhook"
 )
 
 // StatePublisher is a function type for publishing game state updates
 type StatePublisher func(gameID string)
 
-// LogPublisher is a function type for publishing log events
-type LogPublisher func(gameID, userID, logType, message string, eventData interface{})
+// LogPublisher is a function type for publishing a stored log entry
+type LogPublisher func(entry *model.GameSessionLog)
+
+// PrivatePublisher is a function type for publishing events to a single player
+type PrivatePublisher func(gameID, userID, kind string, data interface{})
 
 // Global state publisher function (set by API package)
 var globalStatePublisher StatePublisher
@@ -23,6 +33,9 @@ var globalStatePublisher StatePublisher
 // Global log publisher function (set by API package)
 var globalLogPublisher LogPublisher
 
+// Global private publisher function (set by API package)
+var globalPrivatePublisher PrivatePublisher
+
 // SetStatePublisher sets the global state publisher function
 func SetStatePublisher(publisher StatePublisher) {
 	globalStatePublisher = publisher
@@ -33,6 +46,11 @@ func SetLogPublisher(publisher LogPublisher) {
 	globalLogPublisher = publisher
 }
 
+// SetPrivatePublisher sets the global private publisher function
+func SetPrivatePublisher(publisher PrivatePublisher) {
+	globalPrivatePublisher = publisher
+}
+
 // publishState publishes game state if publisher is set
 func publishState(gameID string) {
 	if globalStatePublisher != nil {
@@ -40,23 +58,121 @@ func publishState(gameID string) {
 	}
 }
 
+// publishPrivate publishes an event to one player if publisher is set
+func publishPrivate(gameID, userID, kind string, data interface{}) {
+	if globalPrivatePublisher != nil {
+		globalPrivatePublisher(gameID, userID, kind, data)
+	}
+}
+
+// cardPayload converts cards into the compact form sent in private events
+func cardPayload(cards []model.Card) []map[string]interface{} {
+	payload := make([]map[string]interface{}, 0, len(cards))
+	for _, c := range cards {
+		payload = append(payload, map[string]interface{}{
+			"id":        c.ID,
+			"suit":      c.Suit,
+			"rank":      c.Rank,
+			"value":     c.Value,
+			"sortOrder": c.SortOrder,
+			"code":      c.CardCode(),
+		})
+	}
+	return payload
+}
+
 // GameManager handles the overall game lifecycle
 type GameManager struct {
 	GameID string
+	repo   store.Store
+	after  *[]func(gm *GameManager) // work deferred until the transaction commits; nil outside one
 }
 
-// NewGameManager creates a new game manager for the specified game
-func NewGameManager(gameID string) *GameManager {
-	return &GameManager{GameID: gameID}
+// NewGameManager creates a new game manager for the specified game, reading
+// and writing through repo
+func NewGameManager(repo store.Store, gameID string) *GameManager {
+	return &GameManager{GameID: gameID, repo: repo}
+}
+
+// withGameLock runs fn holding the game's lock, so that HTTP handlers and
+// background goroutines mutate a game one at a time, across servers too when
+// the store is on Postgres. Locked sections never take the lock again; the
+// goroutines they start take it for themselves.
+func (gm *GameManager) withGameLock(fn func() error) error {
+	unlock, err := gm.repo.Lock(gm.GameID)
+	if err != nil {
+		return fmt.Errorf("failed to lock game: %w", err)
+	}
+	defer unlock()
+	return fn()
+}
+
+// inTransaction runs fn with a manager whose writes, journal events included,
+// commit together. Work fn defers with afterCommit then runs on gm, and is
+// dropped if the transaction rolls back. Inside a transaction fn joins it.
+func (gm *GameManager) inTransaction(fn func(tx *GameManager) error) error {
+	if gm.after != nil {
+		return fn(gm)
+	}
+	var after []func(*GameManager)
+	err := gm.repo.Transaction(func(repo store.Store) error {
+		after = nil
+		return fn(&GameManager{GameID: gm.GameID, repo: repo, after: &after})
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range after {
+		f(gm)
+	}
+	return nil
+}
+
+// afterCommit defers f until the current transaction has committed, so that
+// nothing is published or started for writes that may still roll back.
+// Outside a transaction f runs at once.
+func (gm *GameManager) afterCommit(f func(gm *GameManager)) {
+	if gm.after == nil {
+		f(gm)
+		return
+	}
+	*gm.after = append(*gm.after, f)
+}
+
+// record appends an event to the game's journal
+func (gm *GameManager) record(eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	ev := model.GameEvent{
+		ID:        model.NewID(),
+		GameID:    gm.GameID,
+		Type:      eventType,
+		Payload:   string(data),
+		CreatedAt: time.Now(),
+	}
+	if err := gm.repo.Events().Append(&ev); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
 }
 
 // StartGame initializes the first round and begins gameplay
 func (gm *GameManager) StartGame() error {
+	return gm.withGameLock(gm.startGame)
+}
+
+func (gm *GameManager) startGame() error {
 	// Load game and validate it can be started
-	var game model.Game
-	if err := db.DB.Preload("GamePlayers.User").First(&game, "id = ?", gm.GameID).Error; err != nil {
+	game, err := gm.repo.Games().Get(gm.GameID)
+	if err != nil {
 		return fmt.Errorf("game not found: %w", err)
 	}
+	gamePlayers, err := gm.repo.Games().Players(gm.GameID)
+	if err != nil {
+		return fmt.Errorf("failed to load players: %w", err)
+	}
 
 	if game.Status != "waiting" {
 		return errors.New("game cannot be started from current status")
@@ -66,21 +182,46 @@ func (gm *GameManager) StartGame() error {
 	game.Status = "active"
 	now := time.Now()
 	game.StartedAt = &now
-	
-	if err := db.DB.Save(&game).Error; err != nil {
-		return fmt.Errorf("failed to update game status: %w", err)
-	}
 
-	// Create the first round
-	if err := gm.StartNewRound(1); err != nil {
-		return fmt.Errorf("failed to start first round: %w", err)
+	players := make([]map[string]interface{}, 0, len(gamePlayers))
+	for _, gp := range gamePlayers {
+		players = append(players, map[string]interface{}{
+			"playerId":   gp.UserID,
+			"playerName": nonEmptyName(gp.User.Name, gp.UserID),
+			"isBot":      gp.User.IsBot,
+		})
 	}
 
-	return nil
+	// The game only becomes active together with its first deal
+	return gm.inTransaction(func(tx *GameManager) error {
+		if err := tx.repo.Games().Save(game); err != nil {
+			return fmt.Errorf("failed to update game status: %w", err)
+		}
+		tx.afterCommit(func(gm *GameManager) {
+			webhook.Dispatch(gm.repo, gm.GameID, webhook.GameStarted, map[string]interface{}{
+				"startedAt": now,
+				"players":   players,
+			})
+		})
+
+		// Create the first round
+		if err := tx.dealRound(1); err != nil {
+			return fmt.Errorf("failed to start first round: %w", err)
+		}
+		return nil
+	})
 }
 
-// StartNewRound creates a new round and deals cards
+// StartNewRound creates a new round and deals cards; the caller holds the
+// game lock
 func (gm *GameManager) StartNewRound(roundNumber int) error {
+	return gm.inTransaction(func(tx *GameManager) error {
+		return tx.dealRound(roundNumber)
+	})
+}
+
+// dealRound creates, deals and records a round; call it in a transaction
+func (gm *GameManager) dealRound(roundNumber int) error {
 	// Create round
 	round := model.Round{
 		ID:          model.NewID(),
@@ -88,21 +229,28 @@ func (gm *GameManager) StartNewRound(roundNumber int) error {
 		RoundNumber: roundNumber,
 		Status:      "setup",
 		StartedAt:   time.Now(),
+		Seed:        time.Now().UnixNano(),
 	}
 
-	if err := db.DB.Create(&round).Error; err != nil {
+	if err := gm.repo.Rounds().Create(&round); err != nil {
 		return fmt.Errorf("failed to create round: %w", err)
 	}
 
-	// Get active players (not finished the game yet) ordered by join time to ensure consistent positioning
-	var gamePlayers []model.GamePlayer
-	if err := db.DB.Preload("User").Where("game_id = ? AND donkey_letters != 'DONKEY'", gm.GameID).Order("joined_at ASC").Find(&gamePlayers).Error; err != nil {
+	// Get active players (not finished the game yet) in join order to ensure consistent positioning
+	allPlayers, err := gm.repo.Games().Players(gm.GameID)
+	if err != nil {
 		return fmt.Errorf("failed to load players: %w", err)
 	}
+	var gamePlayers []model.GamePlayer
+	for _, gp := range allPlayers {
+		if !gp.IsDonkey() {
+			gamePlayers = append(gamePlayers, gp)
+		}
+	}
 
 	// Find requester (game creator) to position them last in turn order
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", gm.GameID).Error; err != nil {
+	game, err := gm.repo.Games().Get(gm.GameID)
+	if err != nil {
 		return fmt.Errorf("failed to load game: %w", err)
 	}
 
@@ -124,6 +272,7 @@ func (gm *GameManager) StartNewRound(roundNumber int) error {
 	}
 
 	// Create round players with positions that match visual display order
+	seats := make([]journal.Seat, 0, len(orderedPlayers))
 	for i, gp := range orderedPlayers {
 		roundPlayer := model.RoundPlayer{
 			RoundID:     round.ID,
@@ -133,23 +282,27 @@ func (gm *GameManager) StartNewRound(roundNumber int) error {
 			IsFinished:  false,
 			CardsInHand: 0,
 		}
-		if err := db.DB.Create(&roundPlayer).Error; err != nil {
+		if err := gm.repo.Rounds().AddPlayer(&roundPlayer); err != nil {
 			return fmt.Errorf("failed to create round player: %w", err)
 		}
+		seats = append(seats, journal.Seat{UserID: gp.UserID, Position: i})
 	}
 
-	// Create and shuffle deck
+	// Create and shuffle deck; the round's seed decides both the shuffle and
+	// who is dealt to first, so the deal can be reproduced
+	rng := rand.New(rand.NewSource(round.Seed))
 	cards := model.CreateStandardDeck(round.ID)
-	gm.shuffleCards(cards)
+	gm.shuffleCards(cards, rng)
 
 	// Deal cards to players
-	if err := gm.dealCardsToPlayers(cards, gamePlayers); err != nil {
+	dealt, err := gm.dealCardsToPlayers(cards, gamePlayers, rng)
+	if err != nil {
 		return fmt.Errorf("failed to deal cards: %w", err)
 	}
 
 	// Update round status
 	round.Status = "dealing"
-	if err := db.DB.Save(&round).Error; err != nil {
+	if err := gm.repo.Rounds().Save(&round); err != nil {
 		return fmt.Errorf("failed to update round status: %w", err)
 	}
 
@@ -160,16 +313,33 @@ func (gm *GameManager) StartNewRound(roundNumber int) error {
 	}
 
 	// Create first turn
-	if err := gm.startFirstTurn(round.ID, startPlayerID); err != nil {
+	turn, err := gm.startFirstTurn(round.ID, startPlayerID)
+	if err != nil {
 		return fmt.Errorf("failed to start first turn: %w", err)
 	}
 
 	// Update round status to active
 	round.Status = "active"
-	if err := db.DB.Save(&round).Error; err != nil {
+	if err := gm.repo.Rounds().Save(&round); err != nil {
 		return fmt.Errorf("failed to activate round: %w", err)
 	}
 
+	deal := journal.DealPayload{
+		RoundID:       round.ID,
+		RoundNumber:   roundNumber,
+		Seed:          round.Seed,
+		Seats:         seats,
+		Cards:         make([]journal.DealtCard, 0, len(dealt)),
+		TurnID:        turn.ID,
+		StartPlayerID: startPlayerID,
+	}
+	for _, c := range dealt {
+		deal.Cards = append(deal.Cards, journal.DealtCard{ID: c.ID, Suit: c.Suit, Rank: c.Rank, OwnerID: *c.OwnerID})
+	}
+	if err := gm.record(journal.TypeDeal, deal); err != nil {
+		return err
+	}
+
 	// Log round start
 	logMessage := fmt.Sprintf("Round %d started. %d players.", roundNumber, len(gamePlayers))
 	if err := gm.logEvent("round_event", logMessage, nil); err != nil {
@@ -180,21 +350,23 @@ func (gm *GameManager) StartNewRound(roundNumber int) error {
 }
 
 // shuffleCards shuffles the deck
-func (gm *GameManager) shuffleCards(cards []model.Card) {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(cards), func(i, j int) {
+func (gm *GameManager) shuffleCards(cards []model.Card, rng *rand.Rand) {
+	rng.Shuffle(len(cards), func(i, j int) {
 		cards[i], cards[j] = cards[j], cards[i]
 	})
 }
 
-// dealCardsToPlayers distributes cards evenly among players
-func (gm *GameManager) dealCardsToPlayers(cards []model.Card, gamePlayers []model.GamePlayer) error {
+// dealCardsToPlayers distributes cards evenly among players and returns the
+// cards as dealt
+func (gm *GameManager) dealCardsToPlayers(cards []model.Card, gamePlayers []model.GamePlayer, rng *rand.Rand) ([]model.Card, error) {
 	if len(gamePlayers) == 0 {
-		return errors.New("no players to deal to")
+		return nil, errors.New("no players to deal to")
 	}
 
 	// Start dealing from a random player (as per rules)
-	startIdx := rand.Intn(len(gamePlayers))
+	startIdx := rng.Intn(len(gamePlayers))
+	hands := make(map[string][]model.Card)
+	dealt := make([]model.Card, 0, len(cards))
 	
 	for i, card := range cards {
 		playerIdx := (startIdx + i) % len(gamePlayers)
@@ -204,27 +376,36 @@ func (gm *GameManager) dealCardsToPlayers(cards []model.Card, gamePlayers []mode
 		card.Location = "hand"
 		card.OwnerID = &playerID
 
-		if err := db.DB.Create(&card).Error; err != nil {
-			return fmt.Errorf("failed to create card: %w", err)
+		if err := gm.repo.Cards().Create(&card); err != nil {
+			return nil, fmt.Errorf("failed to create card: %w", err)
 		}
 
 		// Update player's card count
-		if err := db.DB.Model(&model.RoundPlayer{}).
-			Where("round_id = ? AND user_id = ?", card.RoundID, playerID).
-			UpdateColumn("cards_in_hand", db.DB.Model(&model.RoundPlayer{}).
-				Select("cards_in_hand + 1").
-				Where("round_id = ? AND user_id = ?", card.RoundID, playerID)).Error; err != nil {
-			return fmt.Errorf("failed to update card count: %w", err)
+		if err := gm.repo.Rounds().AdjustCardsInHand(card.RoundID, playerID, 1); err != nil {
+			return nil, fmt.Errorf("failed to update card count: %w", err)
 		}
-	}
+		hands[playerID] = append(hands[playerID], card)
+		dealt = append(dealt, card)
+	}
+
+	// Tell each player privately which cards they were dealt
+	gm.afterCommit(func(gm *GameManager) {
+		for playerID, hand := range hands {
+			sort.Slice(hand, func(i, j int) bool { return hand[i].SortOrder < hand[j].SortOrder })
+			publishPrivate(gm.GameID, playerID, "hand_dealt", map[string]interface{}{
+				"roundId": hand[0].RoundID,
+				"cards":   cardPayload(hand),
+			})
+		}
+	})
 
-	return nil
+	return dealt, nil
 }
 
 // findPlayerWithAceOfSpades finds who has the Ace of Spades to start the round
 func (gm *GameManager) findPlayerWithAceOfSpades(roundID string) (string, error) {
-	var card model.Card
-	if err := db.DB.Where("round_id = ? AND suit = 'spades' AND rank = 'A'", roundID).First(&card).Error; err != nil {
+	card, err := gm.repo.Cards().Find(roundID, "spades", "A")
+	if err != nil {
 		return "", fmt.Errorf("ace of spades not found: %w", err)
 	}
 
@@ -236,7 +417,7 @@ func (gm *GameManager) findPlayerWithAceOfSpades(roundID string) (string, error)
 }
 
 // startFirstTurn creates the first turn of the round
-func (gm *GameManager) startFirstTurn(roundID, startPlayerID string) error {
+func (gm *GameManager) startFirstTurn(roundID, startPlayerID string) (*model.Turn, error) {
 	turn := model.Turn{
 		ID:            model.NewID(),
 		RoundID:       roundID,
@@ -246,30 +427,38 @@ func (gm *GameManager) startFirstTurn(roundID, startPlayerID string) error {
 		StartedAt:     time.Now(),
 	}
 
-	if err := db.DB.Create(&turn).Error; err != nil {
-		return fmt.Errorf("failed to create turn: %w", err)
+	if err := gm.repo.Turns().Create(&turn); err != nil {
+		return nil, fmt.Errorf("failed to create turn: %w", err)
 	}
 
 	// Log turn start
 	logMessage := fmt.Sprintf("Turn 1 started. Player %s has the Ace of Spades.", startPlayerID)
 	if err := gm.logEvent("turn_event", logMessage, nil); err != nil {
-		return fmt.Errorf("failed to log turn start: %w", err)
+		return nil, fmt.Errorf("failed to log turn start: %w", err)
 	}
 
-	// Publish initial active turn so clients can render expected player
-	publishState(gm.GameID)
+	gm.afterCommit(func(gm *GameManager) {
+		// Publish initial active turn so clients can render expected player
+		publishState(gm.GameID)
 
-	// Trigger bot play if the starting player is a bot
-	// Start the turn sequence (handles both human and bot plays)
-	go func() {
-		gm.continueTurnSequence(turn.ID)
-	}()
+		// Trigger bot play if the starting player is a bot
+		// Start the turn sequence (handles both human and bot plays)
+		go func() {
+			gm.continueTurnSequence(turn.ID)
+		}()
+	})
 
-	return nil
+	return &turn, nil
 }
 
 // PlayCard handles a player playing a card
 func (gm *GameManager) PlayCard(userID, cardID string) error {
+	return gm.withGameLock(func() error {
+		return gm.playCard(userID, cardID)
+	})
+}
+
+func (gm *GameManager) playCard(userID, cardID string) error {
 	// Load current game state
 	currentTurn, err := gm.getCurrentTurn()
 	if err != nil {
@@ -296,7 +485,9 @@ func (gm *GameManager) PlayCard(userID, cardID string) error {
 	}
 
 	// Execute the card play
-	if err := gm.executeCardPlay(userID, cardID, currentTurn); err != nil {
+	if err := gm.inTransaction(func(tx *GameManager) error {
+		return tx.executeCardPlay(userID, cardID, currentTurn)
+	}); err != nil {
 		return fmt.Errorf("failed to execute card play: %w", err)
 	}
 
@@ -315,7 +506,7 @@ func (gm *GameManager) PlayCard(userID, cardID string) error {
 		// CUT occurred - handle it immediately and don't continue turn sequence
 		go func() {
 			time.Sleep(3 * time.Second)
-			if err := gm.handleCutTurn(updatedTurn); err != nil {
+			if err := gm.withGameLock(func() error { return gm.handleCutTurn(updatedTurn) }); err != nil {
 				// silently ignore debug output
 			}
 		}()
@@ -333,22 +524,18 @@ func (gm *GameManager) PlayCard(userID, cardID string) error {
 
 // getCurrentTurn gets the active turn
 func (gm *GameManager) getCurrentTurn() (*model.Turn, error) {
-	var turn model.Turn
-	if err := db.DB.Where("round_id IN (SELECT id FROM rounds WHERE game_id = ?) AND status = 'active'", gm.GameID).
-		Preload("PlayedCards.Card").
-		Preload("PlayedCards.Player").
-		First(&turn).Error; err != nil {
+	turn, err := gm.repo.Turns().Active(gm.GameID)
+	if err != nil {
 		return nil, fmt.Errorf("no active turn found: %w", err)
 	}
-	return &turn, nil
+	return turn, nil
 }
 
 // getExpectedPlayerID determines whose turn it is
 func (gm *GameManager) getExpectedPlayerID(turn *model.Turn) (string, error) {
     // Load all players to preserve circular order
-    var allPlayers []model.RoundPlayer
-    if err := db.DB.Where("round_id = ?", turn.RoundID).
-        Order("position asc").Find(&allPlayers).Error; err != nil {
+    allPlayers, err := gm.repo.Rounds().Players(turn.RoundID)
+    if err != nil {
         return "", fmt.Errorf("failed to load round players: %w", err)
     }
 
@@ -411,16 +598,16 @@ func (gm *GameManager) getExpectedPlayerID(turn *model.Turn) (string, error) {
 // validateCardPlay checks if the card play is legal
 func (gm *GameManager) validateCardPlay(userID, cardID string, turn *model.Turn) error {
 	// Check card ownership
-	var card model.Card
-	if err := db.DB.Where("id = ? AND owner_id = ? AND location = 'hand'", cardID, userID).First(&card).Error; err != nil {
+	card, err := gm.repo.Cards().Get(cardID)
+	if err != nil || card.Location != "hand" || card.OwnerID == nil || *card.OwnerID != userID {
 		return errors.New("card not found or not owned by player")
 	}
 
 	// Special rule: First turn of first round must be Ace of Spades only
 	if turn.TurnNumber == 1 && len(turn.PlayedCards) == 0 {
 		// Check if this is first round
-		var round model.Round
-		if err := db.DB.Where("id = ?", turn.RoundID).First(&round).Error; err != nil {
+		round, err := gm.repo.Rounds().Get(turn.RoundID)
+		if err != nil {
 			return fmt.Errorf("failed to load round: %w", err)
 		}
 		
@@ -438,15 +625,15 @@ func (gm *GameManager) validateCardPlay(userID, cardID string, turn *model.Turn)
 		leadSuit := *turn.LeadSuit
 		if card.Suit != leadSuit {
 			// Check if player has any cards of the lead suit
-			var count int64
-			if err := db.DB.Model(&model.Card{}).
-				Where("round_id = ? AND owner_id = ? AND suit = ? AND location = 'hand'", 
-					card.RoundID, userID, leadSuit).Count(&count).Error; err != nil {
+			hand, err := gm.repo.Cards().Hand(card.RoundID, userID)
+			if err != nil {
 				return fmt.Errorf("failed to check suit cards: %w", err)
 			}
 			
-			if count > 0 {
-				return errors.New("must follow suit when possible")
+			for _, c := range hand {
+				if c.Suit == leadSuit {
+					return errors.New("must follow suit when possible")
+				}
 			}
 			// Player is void in lead suit, can cut with any card
 		}
@@ -455,20 +642,39 @@ func (gm *GameManager) validateCardPlay(userID, cardID string, turn *model.Turn)
 	return nil
 }
 
-// executeCardPlay performs the actual card play
+// legalCards returns the cards in a player's hand that may be played on the turn
+func (gm *GameManager) legalCards(userID string, turn *model.Turn) ([]model.Card, error) {
+	hand, err := gm.repo.Cards().Hand(turn.RoundID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hand: %w", err)
+	}
+
+	var legal []model.Card
+	for _, c := range hand {
+		if gm.validateCardPlay(userID, c.ID, turn) == nil {
+			legal = append(legal, c)
+		}
+	}
+	return legal, nil
+}
+
+// executeCardPlay performs and records the actual card play; call it in a
+// transaction
 func (gm *GameManager) executeCardPlay(userID, cardID string, turn *model.Turn) error {
 	// Load the card
-	var card model.Card
-	if err := db.DB.First(&card, "id = ?", cardID).Error; err != nil {
+	card, err := gm.repo.Cards().Get(cardID)
+	if err != nil {
 		return fmt.Errorf("card not found: %w", err)
 	}
 
-	// Set lead suit if this is the first card
+	// Claim the turn before anything else is written. The save only goes
+	// through if nobody has played on the turn since it was read, so of two
+	// racing plays exactly one succeeds and the other gets store.ErrConflict.
 	if turn.LeadSuit == nil {
 		turn.LeadSuit = &card.Suit
-		if err := db.DB.Save(turn).Error; err != nil {
-			return fmt.Errorf("failed to set lead suit: %w", err)
-		}
+	}
+	if err := gm.repo.Turns().Save(turn); err != nil {
+		return fmt.Errorf("failed to claim turn: %w", err)
 	}
 
 	// Create played card record
@@ -479,48 +685,48 @@ func (gm *GameManager) executeCardPlay(userID, cardID string, turn *model.Turn)
 		PlayerID:  userID,
 		PlayOrder: len(turn.PlayedCards) + 1,
 		PlayedAt:  time.Now(),
-		Card:      card,
+		Card:      *card,
 	}
 
-	if err := db.DB.Create(&playedCard).Error; err != nil {
+	if err := gm.repo.Turns().AddPlayedCard(&playedCard); err != nil {
 		return fmt.Errorf("failed to create played card: %w", err)
 	}
 
 	// Update card location
 	card.Location = "in_play"
-	if err := db.DB.Save(&card).Error; err != nil {
+	if err := gm.repo.Cards().Save(card); err != nil {
 		return fmt.Errorf("failed to update card location: %w", err)
 	}
 
 	// Update player's card count
-	if err := db.DB.Model(&model.RoundPlayer{}).
-		Where("round_id = ? AND user_id = ?", card.RoundID, userID).
-		UpdateColumn("cards_in_hand", db.DB.Model(&model.RoundPlayer{}).
-			Select("cards_in_hand - 1").
-			Where("round_id = ? AND user_id = ?", card.RoundID, userID)).Error; err != nil {
+	if err := gm.repo.Rounds().AdjustCardsInHand(card.RoundID, userID, -1); err != nil {
 		return fmt.Errorf("failed to update card count: %w", err)
 	}
 
+	if err := gm.record(journal.TypePlay, journal.PlayPayload{
+		RoundID:   card.RoundID,
+		TurnID:    turn.ID,
+		CardID:    cardID,
+		PlayerID:  userID,
+		PlayOrder: playedCard.PlayOrder,
+	}); err != nil {
+		return err
+	}
+
 	// Check if player finished the round (no more cards)
-	var cardCount int64
-	if err := db.DB.Model(&model.Card{}).
-		Where("round_id = ? AND owner_id = ? AND location = 'hand'", card.RoundID, userID).
-		Count(&cardCount).Error; err != nil {
+	remaining, err := gm.repo.Cards().Hand(card.RoundID, userID)
+	if err != nil {
 		return fmt.Errorf("failed to count remaining cards: %w", err)
 	}
 
-	if cardCount == 0 {
+	if len(remaining) == 0 {
 		// Player finished the round
-		now := time.Now()
-		if err := db.DB.Model(&model.RoundPlayer{}).
-			Where("round_id = ? AND user_id = ?", card.RoundID, userID).
-			Updates(map[string]interface{}{
-				"is_finished": true,
-				"finished_at": &now,
-				"cards_in_hand": 0,
-			}).Error; err != nil {
+		if err := gm.repo.Rounds().FinishPlayer(card.RoundID, userID, time.Now()); err != nil {
 			return fmt.Errorf("failed to mark player finished: %w", err)
 		}
+		if err := gm.record(journal.TypeFinish, journal.FinishPayload{RoundID: card.RoundID, UserID: userID}); err != nil {
+			return err
+		}
 
 		// Log player finishing
 		if err := gm.logEvent("round_event", fmt.Sprintf("Player %s finished the round!", userID), nil); err != nil {
@@ -534,90 +740,105 @@ func (gm *GameManager) executeCardPlay(userID, cardID string, turn *model.Turn)
 // continueTurnSequence handles the sequential turn progression with proper timing
 func (gm *GameManager) continueTurnSequence(turnID string) {
 	for {
-		// Load current turn
-		var turn model.Turn
-		if err := db.DB.Preload("PlayedCards.Card").Preload("PlayedCards.Player").Where("id = ?", turnID).First(&turn).Error; err != nil {
+		var botPlayed bool
+		err := gm.withGameLock(func() error {
+			var err error
+			botPlayed, err = gm.advanceTurn(turnID)
+			return err
+		})
+		if err != nil || !botPlayed {
 			// silently ignore debug output
 			return
 		}
 
-		if turn.Status != "active" {
-			// Turn is completed or cut, stop the sequence
-			return
-		}
+		// Wait 3 seconds before next iteration, without holding the lock
+		time.Sleep(3 * time.Second)
+	}
+}
 
-		// Check if turn was cut - if so, stop sequence immediately
-		if gm.isTurnCut(&turn) {
-			// Turn was cut, stop the sequence - handleCutTurn should have been called already
-			return
-		}
+// advanceTurn takes the next step of an active turn: it completes the turn
+// once every active player has played, asks a human to play or plays for a
+// bot. It reports whether a bot played, after which the sequence goes on.
+func (gm *GameManager) advanceTurn(turnID string) (bool, error) {
+	// Load current turn
+	turn, err := gm.repo.Turns().Get(turnID)
+	if err != nil {
+		return false, err
+	}
 
-		// Check if all active players have played
-		var activePlayers []model.RoundPlayer
-		if err := db.DB.Where("round_id = ? AND is_finished = false", turn.RoundID).Find(&activePlayers).Error; err != nil {
-			// silently ignore debug output
-			return
-		}
+	if turn.Status != "active" {
+		// Turn is completed or cut, stop the sequence
+		return false, nil
+	}
 
-		playedPlayers := make(map[string]bool)
-		for _, pc := range turn.PlayedCards {
-			playedPlayers[pc.PlayerID] = true
-		}
+	// Check if turn was cut - if so, stop sequence immediately
+	if gm.isTurnCut(turn) {
+		// Turn was cut, stop the sequence - handleCutTurn should have been called already
+		return false, nil
+	}
 
-		allPlayed := true
-		for _, ap := range activePlayers {
-			if !playedPlayers[ap.UserID] {
-				allPlayed = false
-				break
-			}
-		}
+	// Check if all active players have played
+	roundPlayers, err := gm.repo.Rounds().Players(turn.RoundID)
+	if err != nil {
+		return false, err
+	}
 
-		if allPlayed {
-			// All players have played, complete the turn
-			if err := gm.completeTurn(&turn); err != nil {
-				// silently ignore debug output
-			}
-			return
-		}
+	playedPlayers := make(map[string]bool)
+	for _, pc := range turn.PlayedCards {
+		playedPlayers[pc.PlayerID] = true
+	}
 
-		// Get next player to play
-		nextPlayerID, err := gm.getExpectedPlayerID(&turn)
-		if err != nil {
-			// silently ignore debug output
-			return
+	allPlayed := true
+	for _, rp := range roundPlayers {
+		if !rp.IsFinished && !playedPlayers[rp.UserID] {
+			allPlayed = false
+			break
 		}
+	}
 
-		// Check if next player is a bot
-		var user model.User
-		if err := db.DB.First(&user, "id = ?", nextPlayerID).Error; err != nil {
-			// silently ignore debug output
-			return
-		}
+	if allPlayed {
+		// All players have played, complete the turn
+		return false, gm.completeTurn(turn)
+	}
 
-		if !user.IsBot {
-			// Human player, wait for their input
-			return
-		}
+	// Get next player to play
+	nextPlayerID, err := gm.getExpectedPlayerID(turn)
+	if err != nil {
+		return false, err
+	}
 
-		// Bot player - make them play immediately
-		if err := gm.makeBotPlayCard(nextPlayerID, &turn); err != nil {
-			// silently ignore debug output
-			return
-		}
+	// Check if next player is a bot
+	user, err := gm.repo.Users().Get(nextPlayerID)
+	if err != nil {
+		return false, err
+	}
 
-		// Publish state after bot play
-		publishState(gm.GameID)
+	if !user.IsBot {
+		// Human player, tell them privately what they may play and wait for their input
+		if legal, err := gm.legalCards(nextPlayerID, turn); err == nil {
+			publishPrivate(gm.GameID, nextPlayerID, "your_turn", map[string]interface{}{
+				"turnId":     turn.ID,
+				"legalCards": cardPayload(legal),
+			})
+		}
+		return false, nil
+	}
 
-		// Wait 3 seconds before next iteration
-		time.Sleep(3 * time.Second)
+	// Bot player - make them play immediately
+	if err := gm.makeBotPlayCard(nextPlayerID, turn); err != nil {
+		return false, err
 	}
+
+	// Publish state after bot play
+	publishState(gm.GameID)
+	return true, nil
 }
 
 // makeBotPlayCard makes a bot play a card without async timing
 func (gm *GameManager) makeBotPlayCard(botUserID string, turn *model.Turn) error {
 	// Get bot user details
-	var botUser model.User
-	if err := db.DB.First(&botUser, "id = ?", botUserID).Error; err != nil {
+	botUser, err := gm.repo.Users().Get(botUserID)
+	if err != nil {
 		return fmt.Errorf("bot user not found: %w", err)
 	}
 
@@ -625,9 +846,8 @@ func (gm *GameManager) makeBotPlayCard(botUserID string, turn *model.Turn) error
 	botStrategy := CreateBotStrategy(botUser.BotDifficulty, botUserID)
 
 	// Get bot's cards
-	var botCards []model.Card
-	if err := db.DB.Where("round_id = ? AND owner_id = ? AND location = 'hand'", turn.RoundID, botUserID).
-		Order("sort_order").Find(&botCards).Error; err != nil {
+	botCards, err := gm.repo.Cards().Hand(turn.RoundID, botUserID)
+	if err != nil {
 		return fmt.Errorf("failed to load bot cards: %w", err)
 	}
 
@@ -643,8 +863,8 @@ func (gm *GameManager) makeBotPlayCard(botUserID string, turn *model.Turn) error
     // Enforce rules for bot plays just like humans
     // 1) If first turn of first round and bot holds Ace of Spades, it MUST play it
     if turn.TurnNumber == 1 && len(turn.PlayedCards) == 0 {
-        var round model.Round
-        if err := db.DB.Where("id = ?", turn.RoundID).First(&round).Error; err != nil {
+        round, err := gm.repo.Rounds().Get(turn.RoundID)
+        if err != nil {
             return fmt.Errorf("failed to load round: %w", err)
         }
         if round.RoundNumber == 1 {
@@ -673,17 +893,20 @@ func (gm *GameManager) makeBotPlayCard(botUserID string, turn *model.Turn) error
         }
     }
 
-    // Execute the bot's card play
-    if err := gm.executeCardPlay(botUserID, chosenCard.ID, turn); err != nil {
-        return fmt.Errorf("failed to execute bot card play: %w", err)
+    // Execute and log the bot's card play
+    if err := gm.inTransaction(func(tx *GameManager) error {
+        if err := tx.executeCardPlay(botUserID, chosenCard.ID, turn); err != nil {
+            return fmt.Errorf("failed to execute bot card play: %w", err)
+        }
+        logMessage := fmt.Sprintf("Bot %s played %s", botUser.Name, chosenCard.CardCode())
+        if err := tx.logEvent("turn_event", logMessage, nil); err != nil {
+            return fmt.Errorf("failed to log bot play: %w", err)
+        }
+        return nil
+    }); err != nil {
+        return err
     }
 
-	// Log bot play
-	logMessage := fmt.Sprintf("Bot %s played %s", botUser.Name, chosenCard.CardCode())
-	if err := gm.logEvent("turn_event", logMessage, nil); err != nil {
-		return fmt.Errorf("failed to log bot play: %w", err)
-	}
-
 	// Check if this bot play resulted in a CUT
 	// Reload turn to get the updated PlayedCards
 	updatedTurn, err := gm.getCurrentTurn()
@@ -706,15 +929,14 @@ func (gm *GameManager) makeBotPlayCard(botUserID string, turn *model.Turn) error
 // buildGameStateSnapshot creates a read-only snapshot for bot decision making
 func (gm *GameManager) buildGameStateSnapshot(turn *model.Turn, botUserID string) (*model.GameStateSnapshot, error) {
 	// Get bot's cards
-	var botCards []model.Card
-	if err := db.DB.Where("round_id = ? AND owner_id = ? AND location = 'hand'", turn.RoundID, botUserID).
-		Order("sort_order").Find(&botCards).Error; err != nil {
+	botCards, err := gm.repo.Cards().Hand(turn.RoundID, botUserID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to load bot cards: %w", err)
 	}
 
 	// Get all round players
-	var roundPlayers []model.RoundPlayer
-	if err := db.DB.Preload("User").Where("round_id = ?", turn.RoundID).Find(&roundPlayers).Error; err != nil {
+	roundPlayers, err := gm.repo.Rounds().Players(turn.RoundID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to load round players: %w", err)
 	}
 
@@ -725,8 +947,8 @@ func (gm *GameManager) buildGameStateSnapshot(turn *model.Turn, botUserID string
 	}
 
 	// Get DONKEY status
-	var gamePlayers []model.GamePlayer
-	if err := db.DB.Where("game_id = ?", gm.GameID).Find(&gamePlayers).Error; err != nil {
+	gamePlayers, err := gm.repo.Games().Players(gm.GameID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to load game players: %w", err)
 	}
 
@@ -736,8 +958,8 @@ func (gm *GameManager) buildGameStateSnapshot(turn *model.Turn, botUserID string
 	}
 
 	// Count discard pile
-	var discardCount int64
-	if err := db.DB.Model(&model.Card{}).Where("round_id = ? AND location = 'discard'", turn.RoundID).Count(&discardCount).Error; err != nil {
+	discarded, err := gm.repo.Cards().Discarded(turn.RoundID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to count discard pile: %w", err)
 	}
 
@@ -750,7 +972,7 @@ func (gm *GameManager) buildGameStateSnapshot(turn *model.Turn, botUserID string
 		PlayerHands:   playerHands,
 		MyCards:       botCards,
 		InPlayCards:   turn.PlayedCards,
-		DiscardCount:  int(discardCount),
+		DiscardCount:  len(discarded),
 		RoundPlayers:  roundPlayers,
 		DonkeyStatus:  donkeyStatus,
 	}
@@ -815,9 +1037,8 @@ func (gm *GameManager) handleCutTurn(turn *model.Turn) error {
 	}
 
     // Resolve player names for better logs
-    var winnerUser, cutterUser model.User
-    _ = db.DB.First(&winnerUser, "id = ?", winnerID).Error
-    _ = db.DB.First(&cutterUser, "id = ?", cutPlayerID).Error
+    winnerUser := gm.userOrEmpty(winnerID)
+    cutterUser := gm.userOrEmpty(cutPlayerID)
 
     // Update turn status immediately (without transferring cards yet)
     now := time.Now()
@@ -826,10 +1047,6 @@ func (gm *GameManager) handleCutTurn(turn *model.Turn) error {
     turn.CutPlayerID = &cutPlayerID
     turn.CompletedAt = &now
 
-    if err := db.DB.Save(turn).Error; err != nil {
-        return fmt.Errorf("failed to update turn: %w", err)
-    }
-
     // Log cut and publish state immediately so players can see the CUT notification
     logMessage := fmt.Sprintf("CUT: %s cut; %s collected %d cards.", 
         nonEmptyName(cutterUser.Name, cutPlayerID), nonEmptyName(winnerUser.Name, winnerID), len(turn.PlayedCards))
@@ -841,36 +1058,67 @@ func (gm *GameManager) handleCutTurn(turn *model.Turn) error {
         "winnerName":     winnerUser.Name,
         "cutPlayerName":  cutterUser.Name,
     }
-    if err := gm.logEvent("turn_event", logMessage, eventData); err != nil {
-        return fmt.Errorf("failed to log cut: %w", err)
+    if err := gm.inTransaction(func(tx *GameManager) error {
+        if err := tx.repo.Turns().Save(turn); err != nil {
+            return fmt.Errorf("failed to update turn: %w", err)
+        }
+        if err := tx.record(journal.TypeCut, journal.CutPayload{
+            RoundID: turn.RoundID, TurnID: turn.ID, WinnerID: winnerID, CutPlayerID: cutPlayerID,
+        }); err != nil {
+            return err
+        }
+        if err := tx.logEvent("turn_event", logMessage, eventData); err != nil {
+            return fmt.Errorf("failed to log cut: %w", err)
+        }
+        return nil
+    }); err != nil {
+        return err
     }
     publishState(gm.GameID)
 
     // Wait 3 seconds for players to see the CUT notification, then transfer cards
     go func() {
         time.Sleep(3 * time.Second)
-
-        // NOW transfer the cards to winner's hand
-        for _, pc := range turn.PlayedCards {
-            card := pc.Card
-            card.Location = "hand"
-            card.OwnerID = &winnerID
-            if err := db.DB.Save(&card).Error; err != nil {
-                // continue even on error to avoid stalling the game
-                continue
+        unlock, err := gm.repo.Lock(gm.GameID)
+        if err != nil {
+            return
+        }
+        defer unlock()
+
+        // NOW transfer the cards to winner's hand. If that fails nothing
+        // moves, and the game carries on rather than stalling.
+        if err := gm.inTransaction(func(tx *GameManager) error {
+            cardIDs := make([]string, 0, len(turn.PlayedCards))
+            for _, pc := range turn.PlayedCards {
+                card := pc.Card
+                card.Location = "hand"
+                card.OwnerID = &winnerID
+                if err := tx.repo.Cards().Save(&card); err != nil {
+                    return err
+                }
+                if err := tx.repo.Rounds().AdjustCardsInHand(card.RoundID, winnerID, 1); err != nil {
+                    return err
+                }
+                cardIDs = append(cardIDs, card.ID)
             }
-
-            // Update winner's card count (best-effort)
-            if err := db.DB.Model(&model.RoundPlayer{}).
-                Where("round_id = ? AND user_id = ?", card.RoundID, winnerID).
-                UpdateColumn("cards_in_hand", db.DB.Model(&model.RoundPlayer{}).
-                    Select("cards_in_hand + 1").
-                    Where("round_id = ? AND user_id = ?", card.RoundID, winnerID)).Error; err != nil {
-                // continue even on error
-                continue
+            return tx.record(journal.TypeCollect, journal.CollectPayload{
+                RoundID: turn.RoundID, TurnID: turn.ID, UserID: winnerID, CardIDs: cardIDs,
+            })
+        }); err != nil {
+            log.Printf("game %s: failed to collect cut turn %s: %v", gm.GameID, turn.ID, err)
+        } else {
+            // Tell the winner privately which cards they picked up
+            collected := make([]model.Card, 0, len(turn.PlayedCards))
+            for _, pc := range turn.PlayedCards {
+                collected = append(collected, pc.Card)
             }
+            publishPrivate(gm.GameID, winnerID, "cards_received", map[string]interface{}{
+                "turnId":      turn.ID,
+                "cutPlayerId": cutPlayerID,
+                "cards":       cardPayload(collected),
+            })
         }
-		
+
         // Publish state again after cards have been transferred
         publishState(gm.GameID)
 		
@@ -902,13 +1150,8 @@ func (gm *GameManager) handleCompleteTurn(turn *model.Turn) error {
     turn.WinnerID = &winnerID
     turn.CompletedAt = &now
 
-    if err := db.DB.Save(turn).Error; err != nil {
-        return fmt.Errorf("failed to update turn: %w", err)
-    }
-
     // Resolve winner name for friendlier logs
-    var winnerUser model.User
-    _ = db.DB.First(&winnerUser, "id = ?", winnerID).Error
+    winnerUser := gm.userOrEmpty(winnerID)
 
     // Log completion and publish state so players can see the discard outcome
     logMessage := fmt.Sprintf("Discarded %d cards. %s starts next turn.", 
@@ -921,24 +1164,51 @@ func (gm *GameManager) handleCompleteTurn(turn *model.Turn) error {
         "winnerPlayerId": winnerID,
         "winnerName":     winnerUser.Name,
     }
-    if err := gm.logEvent("turn_event", logMessage, eventData); err != nil {
-        return fmt.Errorf("failed to log completion: %w", err)
+    if err := gm.inTransaction(func(tx *GameManager) error {
+        if err := tx.repo.Turns().Save(turn); err != nil {
+            return fmt.Errorf("failed to update turn: %w", err)
+        }
+        if err := tx.record(journal.TypeDiscard, journal.DiscardPayload{
+            RoundID: turn.RoundID, TurnID: turn.ID, WinnerID: winnerID,
+        }); err != nil {
+            return err
+        }
+        if err := tx.logEvent("turn_event", logMessage, eventData); err != nil {
+            return fmt.Errorf("failed to log completion: %w", err)
+        }
+        return nil
+    }); err != nil {
+        return err
     }
     publishState(gm.GameID)
 
     // Wait 3 seconds for players to see all in-play cards, then move them to discard
     go func() {
         time.Sleep(3 * time.Second)
+        unlock, err := gm.repo.Lock(gm.GameID)
+        if err != nil {
+            return
+        }
+        defer unlock()
 
         // Move cards to discard pile after pause
-        for _, pc := range turn.PlayedCards {
-            card := pc.Card
-            card.Location = "discard"
-            card.OwnerID = nil
-            if err := db.DB.Save(&card).Error; err != nil {
-                // silently ignore
-                return
+        if err := gm.inTransaction(func(tx *GameManager) error {
+            cardIDs := make([]string, 0, len(turn.PlayedCards))
+            for _, pc := range turn.PlayedCards {
+                card := pc.Card
+                card.Location = "discard"
+                card.OwnerID = nil
+                if err := tx.repo.Cards().Save(&card); err != nil {
+                    return err
+                }
+                cardIDs = append(cardIDs, card.ID)
             }
+            return tx.record(journal.TypeClear, journal.ClearPayload{
+                RoundID: turn.RoundID, TurnID: turn.ID, CardIDs: cardIDs,
+            })
+        }); err != nil {
+            // silently ignore
+            return
         }
 
         publishState(gm.GameID)
@@ -961,13 +1231,27 @@ func (gm *GameManager) handleCompleteTurn(turn *model.Turn) error {
 	return nil
 }
 
+// countActivePlayers counts the players of a round who still hold cards
+func (gm *GameManager) countActivePlayers(roundID string) (int, error) {
+	roundPlayers, err := gm.repo.Rounds().Players(roundID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active players: %w", err)
+	}
+	active := 0
+	for _, rp := range roundPlayers {
+		if !rp.IsFinished {
+			active++
+		}
+	}
+	return active, nil
+}
+
 // checkRoundEnd checks if the round should end
 func (gm *GameManager) checkRoundEnd(roundID string) error {
 	// Count players still in the round
-	var activeCount int64
-	if err := db.DB.Model(&model.RoundPlayer{}).
-		Where("round_id = ? AND is_finished = false", roundID).Count(&activeCount).Error; err != nil {
-		return fmt.Errorf("failed to count active players: %w", err)
+	activeCount, err := gm.countActivePlayers(roundID)
+	if err != nil {
+		return err
 	}
 
 	if activeCount <= 1 {
@@ -981,10 +1265,9 @@ func (gm *GameManager) checkRoundEnd(roundID string) error {
 // checkRoundEndWithResult checks if the round should end and returns a boolean result
 func (gm *GameManager) checkRoundEndWithResult(roundID string) (bool, error) {
 	// Count players still in the round
-	var activeCount int64
-	if err := db.DB.Model(&model.RoundPlayer{}).
-		Where("round_id = ? AND is_finished = false", roundID).Count(&activeCount).Error; err != nil {
-		return false, fmt.Errorf("failed to count active players: %w", err)
+	activeCount, err := gm.countActivePlayers(roundID)
+	if err != nil {
+		return false, err
 	}
 
 	if activeCount <= 1 {
@@ -1001,14 +1284,24 @@ func (gm *GameManager) checkRoundEndWithResult(roundID string) (bool, error) {
 // endRound ends the current round and determines loser
 func (gm *GameManager) endRound(roundID string) error {
 	// Find the last remaining player (loser)
-	var loser model.RoundPlayer
-	if err := db.DB.Where("round_id = ? AND is_finished = false", roundID).First(&loser).Error; err != nil {
+	roundPlayers, err := gm.repo.Rounds().Players(roundID)
+	if err != nil {
 		return fmt.Errorf("failed to find round loser: %w", err)
 	}
+	var loser *model.RoundPlayer
+	for i := range roundPlayers {
+		if !roundPlayers[i].IsFinished {
+			loser = &roundPlayers[i]
+			break
+		}
+	}
+	if loser == nil {
+		return errors.New("failed to find round loser: every player finished")
+	}
 
 	// Update round
-	var round model.Round
-	if err := db.DB.First(&round, "id = ?", roundID).Error; err != nil {
+	round, err := gm.repo.Rounds().Get(roundID)
+	if err != nil {
 		return fmt.Errorf("failed to load round: %w", err)
 	}
 
@@ -1017,36 +1310,61 @@ func (gm *GameManager) endRound(roundID string) error {
 	round.CompletedAt = &now
 	round.LoserID = &loser.UserID
 
-	if err := db.DB.Save(&round).Error; err != nil {
-		return fmt.Errorf("failed to update round: %w", err)
-	}
+	var gamePlayer *model.GamePlayer
+	if err := gm.inTransaction(func(tx *GameManager) error {
+		if err := tx.repo.Rounds().Save(round); err != nil {
+			return fmt.Errorf("failed to update round: %w", err)
+		}
 
-	// Add DONKEY letter to loser
-	var gamePlayer model.GamePlayer
-	if err := db.DB.Where("game_id = ? AND user_id = ?", gm.GameID, loser.UserID).First(&gamePlayer).Error; err != nil {
-		return fmt.Errorf("failed to load game player: %w", err)
-	}
+		// Add DONKEY letter to loser
+		gamePlayer, err = tx.repo.Games().Player(gm.GameID, loser.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to load game player: %w", err)
+		}
 
-	gamePlayer.AddDonkeyLetter()
-	if err := db.DB.Save(&gamePlayer).Error; err != nil {
-		return fmt.Errorf("failed to update player letters: %w", err)
-	}
+		gamePlayer.AddDonkeyLetter()
+		if err := tx.repo.Games().SavePlayer(gamePlayer); err != nil {
+			return fmt.Errorf("failed to update player letters: %w", err)
+		}
+		if err := tx.record(journal.TypeLetter, journal.LetterPayload{
+			RoundID:  round.ID,
+			UserID:   loser.UserID,
+			Letters:  gamePlayer.DonkeyLetters,
+			GameOver: gamePlayer.IsDonkey(),
+		}); err != nil {
+			return err
+		}
 
-	// Log round end
-	logMessage := fmt.Sprintf("Round %d ended. Player %s gets letter '%s' (now: %s)", 
-		round.RoundNumber, loser.UserID, string(gamePlayer.DonkeyLetters[len(gamePlayer.DonkeyLetters)-1]), gamePlayer.DonkeyLetters)
-	if err := gm.logEvent("round_event", logMessage, nil); err != nil {
-		return fmt.Errorf("failed to log round end: %w", err)
+		// Log round end
+		letter := string(gamePlayer.DonkeyLetters[len(gamePlayer.DonkeyLetters)-1])
+		logMessage := fmt.Sprintf("Round %d ended. Player %s gets letter '%s' (now: %s)", 
+			round.RoundNumber, loser.UserID, letter, gamePlayer.DonkeyLetters)
+		if err := tx.logEvent("round_event", logMessage, nil); err != nil {
+			return fmt.Errorf("failed to log round end: %w", err)
+		}
+		letters := gamePlayer.DonkeyLetters
+		tx.afterCommit(func(gm *GameManager) {
+			webhook.Dispatch(gm.repo, gm.GameID, webhook.RoundEnded, map[string]interface{}{
+				"roundNumber":   round.RoundNumber,
+				"loserId":       loser.UserID,
+				"letter":        letter,
+				"donkeyLetters": letters,
+			})
+
+			// Publish completed round state before proceeding to game end or next round
+			publishState(gm.GameID)
+		})
+
+		// Check if game should end
+		if gamePlayer.IsDonkey() {
+			return tx.endGame(loser.UserID)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	// Publish completed round state before proceeding to game end or next round
-	publishState(gm.GameID)
-
-	// Check if game should end
 	if gamePlayer.IsDonkey() {
-		if err := gm.endGame(loser.UserID); err != nil {
-			return err
-		}
 		publishState(gm.GameID)
 		return nil
 	}
@@ -1055,11 +1373,12 @@ func (gm *GameManager) endRound(roundID string) error {
 	return gm.StartNewRound(round.RoundNumber + 1)
 }
 
-// endGame ends the entire game
+// endGame ends the entire game; call it in the transaction that awards the
+// final letter
 func (gm *GameManager) endGame(loserID string) error {
 	// Update game
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", gm.GameID).Error; err != nil {
+	game, err := gm.repo.Games().Get(gm.GameID)
+	if err != nil {
 		return fmt.Errorf("failed to load game: %w", err)
 	}
 
@@ -1068,17 +1387,16 @@ func (gm *GameManager) endGame(loserID string) error {
 	game.CompletedAt = &now
 	game.LoserID = &loserID
 
-	if err := db.DB.Save(&game).Error; err != nil {
+	if err := gm.repo.Games().Save(game); err != nil {
 		return fmt.Errorf("failed to update game: %w", err)
 	}
 
 	// Get loser player name
-	var loserUser model.User
-	db.DB.First(&loserUser, "id = ?", loserID)
+	loserUser := gm.userOrEmpty(loserID)
 	
 	// Get all players with their DONKEY letter counts for scoreboard
-	var gamePlayers []model.GamePlayer
-	if err := db.DB.Preload("User").Where("game_id = ?", gm.GameID).Find(&gamePlayers).Error; err != nil {
+	gamePlayers, err := gm.repo.Games().Players(gm.GameID)
+	if err != nil {
 		return fmt.Errorf("failed to load game players: %w", err)
 	}
 	
@@ -1106,33 +1424,84 @@ func (gm *GameManager) endGame(loserID string) error {
 	if err := gm.logEvent("game_event", logMessage, eventData); err != nil {
 		return fmt.Errorf("failed to log game end: %w", err)
 	}
+	gm.afterCommit(func(gm *GameManager) {
+		gm.tallyGame()
+		webhook.Dispatch(gm.repo, gm.GameID, webhook.GameEnded, eventData)
 
-	// Publish final game status so clients resume UI from paused state
-	publishState(gm.GameID)
+		// Publish final game status so clients resume UI from paused state
+		publishState(gm.GameID)
+	})
 	return nil
 }
 
-// startNextTurn creates the next turn
-func (gm *GameManager) startNextTurn(roundID, startPlayerID string) error {
-	// Get current turn number
-	var maxTurnNumber int
-	if err := db.DB.Model(&model.Turn{}).Where("round_id = ?", roundID).
-		Select("COALESCE(MAX(turn_number), 0)").Scan(&maxTurnNumber).Error; err != nil {
-		return fmt.Errorf("failed to get max turn number: %w", err)
+// tallyGame records the statistics, ratings and badges of the game just
+// completed. It runs once the game's end has committed, and a failure only
+// loses the tally, never the result: `server stats rebuild` tallies a game's
+// statistics again and rates it if its ratings were never updated. Badges
+// are not awarded again.
+func (gm *GameManager) tallyGame() {
+	// Ratings are moved from the statistics, so they wait for them
+	if err := stats.Record(gm.repo, gm.GameID); err != nil {
+		log.Printf("game %s: failed to record stats: %v", gm.GameID, err)
+	} else if _, err := rating.Update(gm.repo, gm.GameID); err != nil {
+		log.Printf("game %s: failed to update ratings: %v", gm.GameID, err)
+	}
+	if err := gm.awardAchievements(); err != nil {
+		log.Printf("game %s: %v", gm.GameID, err)
 	}
+}
 
-	// Create next turn
-	turn := model.Turn{
-		ID:            model.NewID(),
-		RoundID:       roundID,
-		TurnNumber:    maxTurnNumber + 1,
-		StartPlayerID: startPlayerID,
-		Status:        "active",
-		StartedAt:     time.Now(),
+// awardAchievements gives the players the badges they earned in the game just
+// completed and logs each one
+func (gm *GameManager) awardAchievements() error {
+	earned, err := achievement.Evaluate(gm.repo, gm.GameID)
+	if err != nil {
+		return fmt.Errorf("failed to award achievements: %w", err)
+	}
+	for _, a := range earned {
+		rule, _ := achievement.Lookup(a.Badge)
+		user := gm.userOrEmpty(a.UserID)
+		message := fmt.Sprintf("%s earned the %s badge: %s", nonEmptyName(user.Name, a.UserID), rule.Name, rule.Description)
+		if err := gm.logEvent("game_event", message, map[string]interface{}{
+			"type":   "achievement",
+			"userId": a.UserID,
+			"badge":  a.Badge,
+			"name":   rule.Name,
+		}); err != nil {
+			return fmt.Errorf("failed to log achievement: %w", err)
+		}
 	}
+	return nil
+}
 
-	if err := db.DB.Create(&turn).Error; err != nil {
-		return fmt.Errorf("failed to create next turn: %w", err)
+// startNextTurn creates the next turn
+func (gm *GameManager) startNextTurn(roundID, startPlayerID string) error {
+	var turn model.Turn
+	if err := gm.inTransaction(func(tx *GameManager) error {
+		// Get current turn number
+		maxTurnNumber, err := tx.repo.Turns().MaxNumber(roundID)
+		if err != nil {
+			return err
+		}
+
+		// Create next turn
+		turn = model.Turn{
+			ID:            model.NewID(),
+			RoundID:       roundID,
+			TurnNumber:    maxTurnNumber + 1,
+			StartPlayerID: startPlayerID,
+			Status:        "active",
+			StartedAt:     time.Now(),
+		}
+
+		if err := tx.repo.Turns().Create(&turn); err != nil {
+			return fmt.Errorf("failed to create next turn: %w", err)
+		}
+		return tx.record(journal.TypeTurn, journal.TurnPayload{
+			RoundID: roundID, TurnID: turn.ID, TurnNumber: turn.TurnNumber, StartPlayerID: startPlayerID,
+		})
+	}); err != nil {
+		return err
 	}
 
 	// Publish state so clients see the new active turn and expected player
@@ -1148,38 +1517,42 @@ func (gm *GameManager) startNextTurn(roundID, startPlayerID string) error {
 	go func() {
 		// Small delay to allow clients to receive the new turn
 		time.Sleep(200 * time.Millisecond)
-		var checkTurn model.Turn
-		if err := db.DB.Preload("PlayedCards").First(&checkTurn, "id = ?", turn.ID).Error; err != nil {
-			return
-		}
-		if checkTurn.Status != "active" || len(checkTurn.PlayedCards) > 0 {
-			return
-		}
-		// Determine expected player
-		nextID, err := gm.getExpectedPlayerID(&checkTurn)
-		if err != nil || nextID == "" {
-			return
-		}
-		// Check if expected player is a bot
-		var u model.User
-		if err := db.DB.First(&u, "id = ?", nextID).Error; err != nil {
-			return
-		}
-		if !u.IsBot {
+		botsTurn := false
+		_ = gm.withGameLock(func() error {
+			checkTurn, err := gm.repo.Turns().Get(turn.ID)
+			if err != nil {
+				return err
+			}
+			if checkTurn.Status != "active" || len(checkTurn.PlayedCards) > 0 {
+				return nil
+			}
+			// Determine expected player
+			nextID, err := gm.getExpectedPlayerID(checkTurn)
+			if err != nil || nextID == "" {
+				return err
+			}
+			// Check if expected player is a bot
+			u, err := gm.repo.Users().Get(nextID)
+			if err != nil || !u.IsBot {
+				return err
+			}
+			// Execute bot play
+			botsTurn = true
+			return gm.makeBotPlayCard(nextID, checkTurn)
+		})
+		if !botsTurn {
 			return
 		}
-		// Execute bot play
-		_ = gm.makeBotPlayCard(nextID, &checkTurn)
 		publishState(gm.GameID)
 		// Continue sequence after pause
 		time.Sleep(3 * time.Second)
-		gm.continueTurnSequence(checkTurn.ID)
+		gm.continueTurnSequence(turn.ID)
 	}()
 
 	return nil
 }
 
-// logEvent creates a log entry and publishes it via SSE if publisher is set
+// logEvent stores a log entry and publishes it via SSE if publisher is set
 func (gm *GameManager) logEvent(eventType, message string, eventData interface{}) error {
     log := model.GameSessionLog{
         ID:        model.NewID(),
@@ -1195,18 +1568,28 @@ func (gm *GameManager) logEvent(eventType, message string, eventData interface{}
         }
     }
 
-    if err := db.DB.Create(&log).Error; err != nil {
+    if err := gm.repo.Logs().Create(&log); err != nil {
         return err
     }
 
-    // Publish log via SSE if publisher is available
-    if globalLogPublisher != nil {
-        globalLogPublisher(gm.GameID, "", eventType, message, eventData)
-    }
+    // Publish the stored entry via SSE once it is committed
+    gm.afterCommit(func(*GameManager) {
+        if globalLogPublisher != nil {
+            globalLogPublisher(&log)
+        }
+    })
 
     return nil
 }
 
+// userOrEmpty loads a user for display, returning an empty user if missing
+func (gm *GameManager) userOrEmpty(userID string) model.User {
+    if user, err := gm.repo.Users().Get(userID); err == nil {
+        return *user
+    }
+    return model.User{}
+}
+
 // nonEmptyName returns fallback to id if name is empty
 func nonEmptyName(name, id string) string {
     if name == "" {
@@ -1217,6 +1600,16 @@ func nonEmptyName(name, id string) string {
 
 // AddBotPlayer adds a bot player to the game
 func (gm *GameManager) AddBotPlayer(difficulty string) (*model.User, error) {
+	var bot *model.User
+	err := gm.withGameLock(func() error {
+		var err error
+		bot, err = gm.addBotPlayer(difficulty)
+		return err
+	})
+	return bot, err
+}
+
+func (gm *GameManager) addBotPlayer(difficulty string) (*model.User, error) {
 	// Create bot user
 	botUser := model.User{
 		ID:            model.NewID(),
@@ -1226,13 +1619,13 @@ func (gm *GameManager) AddBotPlayer(difficulty string) (*model.User, error) {
 		CreatedAt:     time.Now(),
 	}
 
-	if err := db.DB.Create(&botUser).Error; err != nil {
+	if err := gm.repo.Users().Create(&botUser); err != nil {
 		return nil, fmt.Errorf("failed to create bot user: %w", err)
 	}
 
 	// Join the game
-	var game model.Game
-	if err := db.DB.First(&game, "id = ?", gm.GameID).Error; err != nil {
+	game, err := gm.repo.Games().Get(gm.GameID)
+	if err != nil {
 		return nil, fmt.Errorf("game not found: %w", err)
 	}
 
@@ -1241,10 +1634,11 @@ func (gm *GameManager) AddBotPlayer(difficulty string) (*model.User, error) {
 	}
 
 	// Get current player count
-	var count int64
-	if err := db.DB.Model(&model.GamePlayer{}).Where("game_id = ?", gm.GameID).Count(&count).Error; err != nil {
+	players, err := gm.repo.Games().Players(gm.GameID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to count players: %w", err)
 	}
+	count := len(players)
 
 	if count >= 8 {
 		return nil, errors.New("game is full")
@@ -1254,14 +1648,14 @@ func (gm *GameManager) AddBotPlayer(difficulty string) (*model.User, error) {
 	gamePlayer := model.GamePlayer{
 		GameID:        gm.GameID,
 		UserID:        botUser.ID,
-		JoinOrder:     int(count),
+		JoinOrder:     count,
 		IsConnected:   true,
 		DonkeyLetters: "",
 		JoinedAt:      time.Now(),
 		LastSeenAt:    time.Now(),
 	}
 
-	if err := db.DB.Create(&gamePlayer).Error; err != nil {
+	if err := gm.repo.Games().AddPlayer(&gamePlayer); err != nil {
 		return nil, fmt.Errorf("failed to add bot to game: %w", err)
 	}
 
@@ -1270,6 +1664,12 @@ func (gm *GameManager) AddBotPlayer(difficulty string) (*model.User, error) {
 	if err := gm.logEvent("game_event", logMessage, nil); err != nil {
 		return nil, fmt.Errorf("failed to log bot join: %w", err)
 	}
+	webhook.Dispatch(gm.repo, gm.GameID, webhook.PlayerJoined, map[string]interface{}{
+		"playerId":      botUser.ID,
+		"playerName":    botUser.Name,
+		"isBot":         true,
+		"botDifficulty": difficulty,
+	})
 
 	return &botUser, nil
 }
@@ -0,0 +1,147 @@
+// Package journal defines the events of a game's append-only journal. The game
+// manager appends one event per action in the same transaction as the action's
+// writes, so the journal and the tables never disagree about what happened.
+// Replay rebuilds the state of a game's rounds from its journal and Verify
+// checks the tables against that replay.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// Event types, stored in GameEvent.Type
+const (
+	TypeDeal    = "deal"    // a round was set up and dealt
+	TypePlay    = "play"    // a card was played
+	TypeFinish  = "finish"  // a player ran out of cards
+	TypeCut     = "cut"     // a turn was cut
+	TypeDiscard = "discard" // a turn was completed in suit
+	TypeCollect = "collect" // a cut turn's cards went to the player who picks them up
+	TypeClear   = "clear"   // a completed turn's cards went to the discard pile
+	TypeTurn    = "turn"    // a turn after the first of a round was started
+	TypeLetter  = "letter"  // a round's loser got a DONKEY letter
+)
+
+// Seat is a player's place at the table for a round
+type Seat struct {
+	UserID   string `json:"userId"`
+	Position int    `json:"position"`
+}
+
+// DealtCard is a card as dealt
+type DealtCard struct {
+	ID      string `json:"id"`
+	Suit    string `json:"suit"`
+	Rank    string `json:"rank"`
+	OwnerID string `json:"ownerId"`
+}
+
+// DealPayload records a new round: who sits where, who was dealt which card
+// and the first turn
+type DealPayload struct {
+	RoundID       string      `json:"roundId"`
+	RoundNumber   int         `json:"roundNumber"`
+	Seed          int64       `json:"seed,omitempty"` // shuffle seed of the round
+	Seats         []Seat      `json:"seats"`          // in position order
+	Cards         []DealtCard `json:"cards"`
+	TurnID        string      `json:"turnId"`
+	StartPlayerID string      `json:"startPlayerId"`
+}
+
+// PlayPayload records a card played on a turn
+type PlayPayload struct {
+	RoundID   string `json:"roundId"`
+	TurnID    string `json:"turnId"`
+	CardID    string `json:"cardId"`
+	PlayerID  string `json:"playerId"`
+	PlayOrder int    `json:"playOrder"`
+}
+
+// FinishPayload records a player playing out their hand
+type FinishPayload struct {
+	RoundID string `json:"roundId"`
+	UserID  string `json:"userId"`
+}
+
+// CutPayload records a turn being cut. WinnerID played the highest card of
+// the lead suit and picks up the turn's cards; CutPlayerID leads next.
+type CutPayload struct {
+	RoundID     string `json:"roundId"`
+	TurnID      string `json:"turnId"`
+	WinnerID    string `json:"winnerId"`
+	CutPlayerID string `json:"cutPlayerId"`
+}
+
+// DiscardPayload records a turn completed in suit; WinnerID leads next
+type DiscardPayload struct {
+	RoundID  string `json:"roundId"`
+	TurnID   string `json:"turnId"`
+	WinnerID string `json:"winnerId"`
+}
+
+// CollectPayload records the cards of a cut turn moving into a player's hand
+type CollectPayload struct {
+	RoundID string   `json:"roundId"`
+	TurnID  string   `json:"turnId"`
+	UserID  string   `json:"userId"`
+	CardIDs []string `json:"cardIds"`
+}
+
+// ClearPayload records the cards of a completed turn moving to the discard
+// pile
+type ClearPayload struct {
+	RoundID string   `json:"roundId"`
+	TurnID  string   `json:"turnId"`
+	CardIDs []string `json:"cardIds"`
+}
+
+// TurnPayload records a new turn
+type TurnPayload struct {
+	RoundID       string `json:"roundId"`
+	TurnID        string `json:"turnId"`
+	TurnNumber    int    `json:"turnNumber"`
+	StartPlayerID string `json:"startPlayerId"`
+}
+
+// LetterPayload records the end of a round: its loser and the letters they
+// now hold. GameOver is set when the letters spell DONKEY.
+type LetterPayload struct {
+	RoundID  string `json:"roundId"`
+	UserID   string `json:"userId"`
+	Letters  string `json:"letters"`
+	GameOver bool   `json:"gameOver"`
+}
+
+// Decode returns the typed payload of an event, e.g. *PlayPayload for a play
+func Decode(ev model.GameEvent) (interface{}, error) {
+	var payload interface{}
+	switch ev.Type {
+	case TypeDeal:
+		payload = &DealPayload{}
+	case TypePlay:
+		payload = &PlayPayload{}
+	case TypeFinish:
+		payload = &FinishPayload{}
+	case TypeCut:
+		payload = &CutPayload{}
+	case TypeDiscard:
+		payload = &DiscardPayload{}
+	case TypeCollect:
+		payload = &CollectPayload{}
+	case TypeClear:
+		payload = &ClearPayload{}
+	case TypeTurn:
+		payload = &TurnPayload{}
+	case TypeLetter:
+		payload = &LetterPayload{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", ev.Type)
+	}
+	if err := json.Unmarshal([]byte(ev.Payload), payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event %d: %w", ev.Type, ev.Seq, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,150 @@
+package journal_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/game"
+	"github.com/kairodrad/donkey/internal/journal"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func gormStore(t *testing.T) store.Store {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open("file:"+model.NewID()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := conn.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, migrations.Up(conn))
+	return store.NewGorm(conn)
+}
+
+func TestJournalMatchesTablesAfterPlay(t *testing.T) {
+	for name, repo := range map[string]store.Store{"gorm": gormStore(t), "memory": store.NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+			bob := model.User{ID: model.NewID(), Name: "Bob", CreatedAt: now}
+			g := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "waiting", MaxPlayers: 8, MinPlayers: 2, CreatedAt: now}
+			require.NoError(t, repo.Users().Create(&ann))
+			require.NoError(t, repo.Users().Create(&bob))
+			require.NoError(t, repo.Games().Create(&g))
+			for i, u := range []model.User{ann, bob} {
+				require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: u.ID, JoinOrder: i, JoinedAt: now, LastSeenAt: now}))
+			}
+
+			gm := game.NewGameManager(repo, g.ID)
+			require.NoError(t, gm.StartGame())
+			round, err := repo.Rounds().Current(g.ID)
+			require.NoError(t, err)
+			ace, err := repo.Cards().Find(round.ID, "spades", "A")
+			require.NoError(t, err)
+			require.NoError(t, gm.PlayCard(*ace.OwnerID, ace.ID))
+
+			events, err := repo.Events().List(g.ID)
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, journal.TypeDeal, events[0].Type)
+			assert.Equal(t, journal.TypePlay, events[1].Type)
+
+			state, err := journal.Replay(g.ID, events)
+			require.NoError(t, err)
+			assert.Equal(t, "active", state.Status)
+			require.Len(t, state.Rounds, 1)
+			assert.Len(t, state.Rounds[0].Cards, 52)
+			assert.Equal(t, "spades", state.Rounds[0].Turns[0].LeadSuit)
+
+			diffs, err := journal.Verify(repo, g.ID)
+			require.NoError(t, err)
+			assert.Empty(t, diffs)
+
+			// A write that bypasses the journal is caught
+			ace.Location = "discard"
+			require.NoError(t, repo.Cards().Save(ace))
+			diffs, err = journal.Verify(repo, g.ID)
+			require.NoError(t, err)
+			assert.Len(t, diffs, 1)
+		})
+	}
+}
+
+// events builds a journal from typed payloads
+func events(t *testing.T, payloads ...interface{}) []model.GameEvent {
+	t.Helper()
+	var evs []model.GameEvent
+	for i, p := range payloads {
+		var typ string
+		switch p.(type) {
+		case journal.DealPayload:
+			typ = journal.TypeDeal
+		case journal.PlayPayload:
+			typ = journal.TypePlay
+		case journal.FinishPayload:
+			typ = journal.TypeFinish
+		case journal.CutPayload:
+			typ = journal.TypeCut
+		case journal.CollectPayload:
+			typ = journal.TypeCollect
+		case journal.TurnPayload:
+			typ = journal.TypeTurn
+		case journal.LetterPayload:
+			typ = journal.TypeLetter
+		}
+		require.NotEmpty(t, typ)
+		data, err := json.Marshal(p)
+		require.NoError(t, err)
+		evs = append(evs, model.GameEvent{ID: model.NewID(), GameID: "g", Seq: i + 1, Type: typ, Payload: string(data)})
+	}
+	return evs
+}
+
+func TestReplayFollowsCutsAndLetters(t *testing.T) {
+	deal := journal.DealPayload{
+		RoundID: "r1", RoundNumber: 1, TurnID: "t1", StartPlayerID: "ann",
+		Seats: []journal.Seat{{UserID: "bob", Position: 0}, {UserID: "ann", Position: 1}},
+		Cards: []journal.DealtCard{
+			{ID: "AS", Suit: "spades", Rank: "A", OwnerID: "ann"},
+			{ID: "2H", Suit: "hearts", Rank: "2", OwnerID: "ann"},
+			{ID: "KS", Suit: "spades", Rank: "K", OwnerID: "bob"},
+			{ID: "3D", Suit: "diamonds", Rank: "3", OwnerID: "bob"},
+		},
+	}
+	journalled := events(t,
+		deal,
+		journal.PlayPayload{RoundID: "r1", TurnID: "t1", CardID: "AS", PlayerID: "ann", PlayOrder: 1},
+		journal.PlayPayload{RoundID: "r1", TurnID: "t1", CardID: "3D", PlayerID: "bob", PlayOrder: 2},
+		journal.CutPayload{RoundID: "r1", TurnID: "t1", WinnerID: "ann", CutPlayerID: "bob"},
+		journal.CollectPayload{RoundID: "r1", TurnID: "t1", UserID: "ann", CardIDs: []string{"AS", "3D"}},
+		journal.TurnPayload{RoundID: "r1", TurnID: "t2", TurnNumber: 2, StartPlayerID: "bob"},
+		journal.PlayPayload{RoundID: "r1", TurnID: "t2", CardID: "KS", PlayerID: "bob", PlayOrder: 1},
+		journal.FinishPayload{RoundID: "r1", UserID: "bob"},
+		journal.LetterPayload{RoundID: "r1", UserID: "ann", Letters: "D"},
+	)
+
+	state, err := journal.Replay("g", journalled)
+	require.NoError(t, err)
+	round := state.Rounds[0]
+	assert.Equal(t, "completed", round.Status)
+	assert.Equal(t, "ann", round.LoserID)
+	assert.Equal(t, 3, round.Player("ann").CardsInHand)
+	assert.True(t, round.Player("bob").Finished)
+	assert.Equal(t, "hand", round.Cards["3D"].Location)
+	assert.Equal(t, "ann", round.Cards["3D"].OwnerID)
+	assert.Equal(t, "cut", round.Turn("t1").Status)
+	assert.Equal(t, "D", state.Letters["ann"])
+	assert.Equal(t, "active", state.Status, "one letter does not end the game")
+
+	// A card can only be played from the player's own hand
+	bad := events(t, deal, journal.PlayPayload{RoundID: "r1", TurnID: "t1", CardID: "KS", PlayerID: "ann", PlayOrder: 1})
+	_, err = journal.Replay("g", bad)
+	assert.Error(t, err)
+}
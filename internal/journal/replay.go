@@ -0,0 +1,235 @@
+package journal
+
+import (
+	"fmt"
+
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// State is a game as rebuilt from its journal
+type State struct {
+	GameID  string
+	Status  string            // "waiting" until the first deal, then "active", then "completed"
+	LoserID string            // set once the game is completed
+	Letters map[string]string // userID -> DONKEY letters
+	Rounds  []*Round          // in play order
+}
+
+// Round is the replayed state of a round
+type Round struct {
+	ID      string
+	Number  int
+	Status  string // "active" or "completed"
+	LoserID string
+	Players []*Player // in seating order
+	Cards   map[string]*Card
+	Turns   []*Turn // in turn order
+}
+
+// Player is a player's replayed state within a round
+type Player struct {
+	UserID      string
+	Position    int
+	CardsInHand int
+	Finished    bool
+}
+
+// Card is the replayed location of a card
+type Card struct {
+	ID       string
+	Suit     string
+	Rank     string
+	Location string // "hand", "in_play" or "discard"
+	OwnerID  string // empty once discarded
+}
+
+// Turn is the replayed state of a turn
+type Turn struct {
+	ID            string
+	Number        int
+	StartPlayerID string
+	LeadSuit      string
+	Status        string // "active", "cut" or "completed"
+	WinnerID      string
+	CutPlayerID   string
+	Plays         []Play // in play order
+}
+
+// Play is a card played on a turn
+type Play struct {
+	CardID   string
+	PlayerID string
+}
+
+// Replay rebuilds a game's state from its events, which must be in Seq order.
+// It fails on an event that does not fit the state built so far.
+func Replay(gameID string, events []model.GameEvent) (*State, error) {
+	s := &State{GameID: gameID, Status: "waiting", Letters: make(map[string]string)}
+	for _, ev := range events {
+		payload, err := Decode(ev)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.apply(payload); err != nil {
+			return nil, fmt.Errorf("event %d (%s): %w", ev.Seq, ev.Type, err)
+		}
+	}
+	return s, nil
+}
+
+// Round returns the replayed round with the given ID, or nil
+func (s *State) Round(id string) *Round {
+	for _, r := range s.Rounds {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// Player returns the round's player with the given ID, or nil
+func (r *Round) Player(userID string) *Player {
+	for _, p := range r.Players {
+		if p.UserID == userID {
+			return p
+		}
+	}
+	return nil
+}
+
+// Turn returns the round's turn with the given ID, or nil
+func (r *Round) Turn(id string) *Turn {
+	for _, t := range r.Turns {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *State) apply(payload interface{}) error {
+	switch p := payload.(type) {
+	case *DealPayload:
+		if s.Round(p.RoundID) != nil {
+			return fmt.Errorf("round %s dealt twice", p.RoundID)
+		}
+		round := &Round{ID: p.RoundID, Number: p.RoundNumber, Status: "active", Cards: make(map[string]*Card)}
+		for _, seat := range p.Seats {
+			round.Players = append(round.Players, &Player{UserID: seat.UserID, Position: seat.Position})
+		}
+		for _, c := range p.Cards {
+			owner := round.Player(c.OwnerID)
+			if owner == nil {
+				return fmt.Errorf("card %s dealt to %s, who is not seated", c.ID, c.OwnerID)
+			}
+			owner.CardsInHand++
+			round.Cards[c.ID] = &Card{ID: c.ID, Suit: c.Suit, Rank: c.Rank, Location: "hand", OwnerID: c.OwnerID}
+		}
+		round.Turns = append(round.Turns, &Turn{ID: p.TurnID, Number: 1, StartPlayerID: p.StartPlayerID, Status: "active"})
+		s.Rounds = append(s.Rounds, round)
+		s.Status = "active"
+
+	case *PlayPayload:
+		round, turn, err := s.turn(p.RoundID, p.TurnID)
+		if err != nil {
+			return err
+		}
+		card := round.Cards[p.CardID]
+		if card == nil || card.Location != "hand" || card.OwnerID != p.PlayerID {
+			return fmt.Errorf("card %s is not in %s's hand", p.CardID, p.PlayerID)
+		}
+		if turn.Status != "active" || p.PlayOrder != len(turn.Plays)+1 {
+			return fmt.Errorf("play %d does not follow turn %d", p.PlayOrder, turn.Number)
+		}
+		if turn.LeadSuit == "" {
+			turn.LeadSuit = card.Suit
+		}
+		card.Location = "in_play"
+		round.Player(p.PlayerID).CardsInHand--
+		turn.Plays = append(turn.Plays, Play{CardID: p.CardID, PlayerID: p.PlayerID})
+
+	case *FinishPayload:
+		round := s.Round(p.RoundID)
+		if round == nil || round.Player(p.UserID) == nil {
+			return fmt.Errorf("player %s is not in round %s", p.UserID, p.RoundID)
+		}
+		round.Player(p.UserID).Finished = true
+
+	case *CutPayload:
+		_, turn, err := s.turn(p.RoundID, p.TurnID)
+		if err != nil {
+			return err
+		}
+		turn.Status, turn.WinnerID, turn.CutPlayerID = "cut", p.WinnerID, p.CutPlayerID
+
+	case *DiscardPayload:
+		_, turn, err := s.turn(p.RoundID, p.TurnID)
+		if err != nil {
+			return err
+		}
+		turn.Status, turn.WinnerID = "completed", p.WinnerID
+
+	case *CollectPayload:
+		round, _, err := s.turn(p.RoundID, p.TurnID)
+		if err != nil {
+			return err
+		}
+		player := round.Player(p.UserID)
+		if player == nil {
+			return fmt.Errorf("player %s is not in round %s", p.UserID, p.RoundID)
+		}
+		for _, id := range p.CardIDs {
+			card := round.Cards[id]
+			if card == nil || card.Location != "in_play" {
+				return fmt.Errorf("card %s is not in play", id)
+			}
+			card.Location, card.OwnerID = "hand", p.UserID
+			player.CardsInHand++
+		}
+
+	case *ClearPayload:
+		round, _, err := s.turn(p.RoundID, p.TurnID)
+		if err != nil {
+			return err
+		}
+		for _, id := range p.CardIDs {
+			card := round.Cards[id]
+			if card == nil || card.Location != "in_play" {
+				return fmt.Errorf("card %s is not in play", id)
+			}
+			card.Location, card.OwnerID = "discard", ""
+		}
+
+	case *TurnPayload:
+		round := s.Round(p.RoundID)
+		if round == nil {
+			return fmt.Errorf("unknown round %s", p.RoundID)
+		}
+		round.Turns = append(round.Turns, &Turn{ID: p.TurnID, Number: p.TurnNumber, StartPlayerID: p.StartPlayerID, Status: "active"})
+
+	case *LetterPayload:
+		round := s.Round(p.RoundID)
+		if round == nil {
+			return fmt.Errorf("unknown round %s", p.RoundID)
+		}
+		round.Status, round.LoserID = "completed", p.UserID
+		s.Letters[p.UserID] = p.Letters
+		if p.GameOver {
+			s.Status, s.LoserID = "completed", p.UserID
+		}
+	}
+	return nil
+}
+
+// turn finds a replayed round and one of its turns
+func (s *State) turn(roundID, turnID string) (*Round, *Turn, error) {
+	round := s.Round(roundID)
+	if round == nil {
+		return nil, nil, fmt.Errorf("unknown round %s", roundID)
+	}
+	turn := round.Turn(turnID)
+	if turn == nil {
+		return nil, nil, fmt.Errorf("unknown turn %s in round %d", turnID, round.Number)
+	}
+	return round, turn, nil
+}
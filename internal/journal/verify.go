@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Verify replays a game's journal and compares the result with the tables.
+// It returns one line per difference found; an empty result means the tables
+// match the journal. An error means the journal could not be read or
+// replayed.
+func Verify(repo store.Store, gameID string) ([]string, error) {
+	game, err := repo.Games().Get(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game: %w", err)
+	}
+	events, err := repo.Events().List(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal: %w", err)
+	}
+	state, err := Replay(gameID, events)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	diff := func(format string, args ...interface{}) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+
+	if state.Status == "waiting" && game.Status != "waiting" {
+		diff("game is %s but the journal has no deal", game.Status)
+	}
+	if state.Status == "completed" && (game.Status != "completed" || deref(game.LoserID) != state.LoserID) {
+		diff("game is %s with loser %q, journal says completed with loser %q", game.Status, deref(game.LoserID), state.LoserID)
+	}
+	players, err := repo.Games().Players(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load players: %w", err)
+	}
+	for _, gp := range players {
+		if gp.DonkeyLetters != state.Letters[gp.UserID] {
+			diff("player %s has letters %q, journal says %q", gp.UserID, gp.DonkeyLetters, state.Letters[gp.UserID])
+		}
+	}
+
+	for _, r := range state.Rounds {
+		round, err := repo.Rounds().Get(r.ID)
+		if err != nil {
+			diff("round %d: %v", r.Number, err)
+			continue
+		}
+		if round.Status != r.Status || deref(round.LoserID) != r.LoserID {
+			diff("round %d is %s with loser %q, journal says %s with loser %q",
+				r.Number, round.Status, deref(round.LoserID), r.Status, r.LoserID)
+		}
+
+		seated, err := repo.Rounds().Players(r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load round players: %w", err)
+		}
+		if len(seated) != len(r.Players) {
+			diff("round %d seats %d players, journal says %d", r.Number, len(seated), len(r.Players))
+		}
+		for _, rp := range seated {
+			p := r.Player(rp.UserID)
+			if p == nil {
+				diff("round %d: player %s is not in the journal", r.Number, rp.UserID)
+				continue
+			}
+			if rp.Position != p.Position || rp.CardsInHand != p.CardsInHand || rp.IsFinished != p.Finished {
+				diff("round %d: player %s at %d holds %d cards (finished %t), journal says at %d holding %d (finished %t)",
+					r.Number, rp.UserID, rp.Position, rp.CardsInHand, rp.IsFinished, p.Position, p.CardsInHand, p.Finished)
+			}
+		}
+
+		ids := make([]string, 0, len(r.Cards))
+		for id := range r.Cards {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			c := r.Cards[id]
+			card, err := repo.Cards().Get(c.ID)
+			if err != nil {
+				diff("round %d: card %s: %v", r.Number, c.ID, err)
+				continue
+			}
+			if card.Suit != c.Suit || card.Rank != c.Rank || card.Location != c.Location || deref(card.OwnerID) != c.OwnerID {
+				diff("round %d: card %s is %s%s in %s of %q, journal says %s%s in %s of %q", r.Number, c.ID,
+					card.Rank, card.Suit, card.Location, deref(card.OwnerID), c.Rank, c.Suit, c.Location, c.OwnerID)
+			}
+		}
+
+		for _, t := range r.Turns {
+			turn, err := repo.Turns().Get(t.ID)
+			if err != nil {
+				diff("round %d turn %d: %v", r.Number, t.Number, err)
+				continue
+			}
+			if turn.TurnNumber != t.Number || turn.StartPlayerID != t.StartPlayerID || turn.Status != t.Status ||
+				deref(turn.LeadSuit) != t.LeadSuit || deref(turn.WinnerID) != t.WinnerID || deref(turn.CutPlayerID) != t.CutPlayerID {
+				diff("round %d turn %d is %s (lead %q, winner %q, cut by %q), journal says %s (lead %q, winner %q, cut by %q)",
+					r.Number, t.Number, turn.Status, deref(turn.LeadSuit), deref(turn.WinnerID), deref(turn.CutPlayerID),
+					t.Status, t.LeadSuit, t.WinnerID, t.CutPlayerID)
+			}
+			if len(turn.PlayedCards) != len(t.Plays) {
+				diff("round %d turn %d has %d plays, journal says %d", r.Number, t.Number, len(turn.PlayedCards), len(t.Plays))
+				continue
+			}
+			for i, pc := range turn.PlayedCards {
+				if pc.CardID != t.Plays[i].CardID || pc.PlayerID != t.Plays[i].PlayerID {
+					diff("round %d turn %d play %d is %s by %s, journal says %s by %s",
+						r.Number, t.Number, i+1, pc.CardID, pc.PlayerID, t.Plays[i].CardID, t.Plays[i].PlayerID)
+				}
+			}
+		}
+	}
+	return diffs, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
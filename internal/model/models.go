@@ -32,6 +32,7 @@ type Game struct {
 	StartedAt    *time.Time `json:"startedAt,omitempty"`
 	CompletedAt  *time.Time `json:"completedAt,omitempty"`
 	LoserID      *string   `json:"loserId,omitempty"` // Final DONKEY loser
+	Version      int       `gorm:"not null;default:0" json:"version"` // Bumped on every save; a stale save fails
 	
 	// Relationships
 	Rounds       []Round   `gorm:"foreignKey:GameID" json:"rounds"`
@@ -60,6 +61,8 @@ type Round struct {
 	StartedAt   time.Time `json:"startedAt"`
 	CompletedAt *time.Time `json:"completedAt,omitempty"`
 	LoserID     *string   `json:"loserId,omitempty"` // Player who lost this round
+	Seed        int64     `gorm:"not null;default:0" json:"seed"` // Seeds the shuffle and the first player dealt to
+	Version     int       `gorm:"not null;default:0" json:"version"` // Bumped on every save; a stale save fails
 	
 	// Relationships
 	Turns       []Turn    `gorm:"foreignKey:RoundID" json:"turns"`
@@ -90,6 +93,7 @@ type Turn struct {
 	CutPlayerID *string   `json:"cutPlayerId,omitempty"` // Player who cut the suit
 	StartedAt   time.Time `json:"startedAt"`
 	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	Version     int       `gorm:"not null;default:0" json:"version"` // Bumped on every save and play; a stale save fails
 	
 	// Relationships
 	PlayedCards []PlayedCard `gorm:"foreignKey:TurnID" json:"playedCards"`
@@ -144,6 +148,18 @@ type GameSessionLog struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// GameEvent is one entry of a game's append-only journal. Events are written
+// in the same transaction as the action they describe and are never changed;
+// replaying them in Seq order rebuilds the state of the game's rounds.
+type GameEvent struct {
+	ID        string    `gorm:"primaryKey;size:32" json:"id"`
+	GameID    string    `gorm:"size:32;not null;uniqueIndex:idx_game_events_seq" json:"gameId"`
+	Seq       int       `gorm:"not null;uniqueIndex:idx_game_events_seq" json:"seq"` // 1, 2, ... within the game
+	Type      string    `gorm:"size:20;not null" json:"type"`                         // see internal/journal
+	Payload   string    `gorm:"type:text;not null" json:"payload"`                    // JSON of the type's payload
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 // GameSettings holds configurable game parameters
 type GameSettings struct {
 	GameID              string `gorm:"primaryKey;size:32" json:"gameId"`
@@ -152,6 +168,147 @@ type GameSettings struct {
 	MaxBots             int    `gorm:"default:6" json:"maxBots"`
 	TurnTimeoutSeconds  int    `gorm:"default:30" json:"turnTimeoutSeconds"`
 	PauseOnDisconnect   bool   `gorm:"default:true" json:"pauseOnDisconnect"`
+	Rated               bool   `gorm:"not null" json:"rated"` // casual games leave ratings alone; no default tag, so false is stored
+}
+
+// Webhook is an outgoing HTTP callback registered by a user. A webhook with a
+// GameID only fires for that game; without one it fires for every game the
+// owner plays in.
+type Webhook struct {
+	ID          string    `gorm:"primaryKey;size:32" json:"id"`
+	OwnerID     string    `gorm:"size:32;index;not null" json:"ownerId"`
+	GameID      *string   `gorm:"size:32;index" json:"gameId,omitempty"`
+	URL         string    `gorm:"size:2048;not null" json:"url"`
+	Secret      string    `gorm:"size:64;not null" json:"-"` // HMAC-SHA256 signing key
+	Events      string    `gorm:"size:255" json:"events"` // Comma-separated event types, empty for all
+	Active      bool      `gorm:"default:true" json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery records each attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID          string     `gorm:"primaryKey;size:32" json:"id"`
+	WebhookID   string     `gorm:"size:32;index" json:"webhookId"`
+	GameID      string     `gorm:"size:32;index" json:"gameId"`
+	Event       string     `gorm:"size:30" json:"event"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	Status      string     `gorm:"size:20;default:'pending'" json:"status"` // "pending", "delivered", "failed"
+	Attempts    int        `gorm:"default:0" json:"attempts"`
+	StatusCode  int        `json:"statusCode"` // Last HTTP status received
+	LastError   string     `gorm:"type:text" json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// GameArchive is the compact record kept for a completed game once its
+// detailed rounds, turns and cards have been purged
+type GameArchive struct {
+	GameID      string     `gorm:"primaryKey;size:32" json:"gameId"`
+	RequesterID string     `gorm:"size:32" json:"requesterId"`
+	LoserID     *string    `gorm:"size:32" json:"loserId,omitempty"`
+	Players     string     `gorm:"type:text" json:"players"` // JSON []ArchivedPlayer
+	Rounds      string     `gorm:"type:text" json:"rounds"`  // JSON []ArchivedRound
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ArchivedAt  time.Time  `json:"archivedAt"`
+}
+
+// ArchivedPlayer is a player entry in GameArchive.Players
+type ArchivedPlayer struct {
+	UserID        string `json:"userId"`
+	Name          string `json:"name"`
+	IsBot         bool   `json:"isBot"`
+	BotDifficulty string `json:"botDifficulty,omitempty"`
+	JoinOrder     int    `json:"joinOrder"`
+	DonkeyLetters string `json:"donkeyLetters"`
+}
+
+// ArchivedRound is a round entry in GameArchive.Rounds. Seats, Deal, Tricks
+// and FinishOrder are missing from archives made before they were recorded,
+// and Deal from rounds dealt before the game journal existed.
+type ArchivedRound struct {
+	RoundNumber int                 `json:"roundNumber"`
+	LoserID     *string             `json:"loserId,omitempty"`
+	Letter      string              `json:"letter,omitempty"`
+	StartedAt   time.Time           `json:"startedAt"`
+	CompletedAt *time.Time          `json:"completedAt,omitempty"`
+	Seed        int64               `json:"seed,omitempty"`
+	Seats       []string            `json:"seats,omitempty"`  // user IDs in seating order
+	Deal        map[string][]string `json:"deal,omitempty"`   // userID -> card codes in the order dealt
+	Tricks      []ArchivedTrick     `json:"tricks,omitempty"` // in turn order
+	FinishOrder []string            `json:"finishOrder,omitempty"` // user IDs, first out of cards first and the loser last
+}
+
+// ArchivedTrick is one turn of an archived round
+type ArchivedTrick struct {
+	Number        int            `json:"number"`
+	StartPlayerID string         `json:"startPlayerId"`
+	LeadSuit      string         `json:"leadSuit,omitempty"`
+	Status        string         `json:"status"` // "completed" or "cut"; "active" for a live round
+	WinnerID      string         `json:"winnerId,omitempty"`
+	CutPlayerID   string         `json:"cutPlayerId,omitempty"`
+	Plays         []ArchivedPlay `json:"plays"` // in play order
+}
+
+// ArchivedPlay is a card played in an archived trick
+type ArchivedPlay struct {
+	PlayerID string `json:"playerId"`
+	Card     string `json:"card"` // card code, e.g. "AS"
+}
+
+// PlayerGameStats is one player's tally for one completed game. A row is
+// written as each game ends and outlives the game's detail rows, so a user's
+// statistics are sums over their rows.
+type PlayerGameStats struct {
+	GameID            string    `gorm:"primaryKey;size:32" json:"gameId"`
+	UserID            string    `gorm:"primaryKey;size:32;index" json:"userId"`
+	CompletedAt       time.Time `gorm:"index" json:"completedAt"`
+	Lost              bool      `gorm:"not null;default:false" json:"lost"` // became the DONKEY
+	RoundsPlayed      int       `gorm:"not null;default:0" json:"roundsPlayed"`
+	RoundsLost        int       `gorm:"not null;default:0" json:"roundsLost"`
+	FinishPositionSum int       `gorm:"not null;default:0" json:"finishPositionSum"` // 1 for first out, over every round
+	Cuts              int       `gorm:"not null;default:0" json:"cuts"`
+	TricksCollected   int       `gorm:"not null;default:0" json:"tricksCollected"` // cut tricks picked up
+	CardsPickedUp     int       `gorm:"not null;default:0" json:"cardsPickedUp"`
+}
+
+// Rating is the skill rating of a human player, or of every bot of one
+// difficulty, whose SubjectID is "bot:" followed by the difficulty
+type Rating struct {
+	SubjectID string    `gorm:"primaryKey;size:40" json:"subjectId"`
+	Rating    float64   `gorm:"not null" json:"rating"`
+	Games     int       `gorm:"not null;default:0" json:"games"` // rated games played
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Achievement is a badge a user has earned. Each badge is earned once, in the
+// game recorded here.
+type Achievement struct {
+	UserID   string    `gorm:"primaryKey;size:32" json:"userId"`
+	Badge    string    `gorm:"primaryKey;size:32" json:"badge"` // see internal/achievement
+	GameID   string    `gorm:"size:32;not null" json:"gameId"`
+	EarnedAt time.Time `json:"earnedAt"`
+}
+
+// RatingChange records how one rated game moved a rating
+type RatingChange struct {
+	ID        string    `gorm:"primaryKey;size:32" json:"id"`
+	SubjectID string    `gorm:"size:40;not null;index" json:"subjectId"`
+	GameID    string    `gorm:"size:32;not null;index" json:"gameId"`
+	Before    float64   `gorm:"not null" json:"before"`
+	After     float64   `gorm:"not null" json:"after"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ShareLink lets someone outside a game look at it through an unguessable
+// token. A game has at most one link; once its owner revokes it, sharing the
+// game again makes a new token.
+type ShareLink struct {
+	Token     string    `gorm:"primaryKey;size:32" json:"token"`
+	GameID    string    `gorm:"size:32;not null;uniqueIndex" json:"gameId"`
+	CreatedBy string    `gorm:"size:32;not null" json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 // Helper methods and types for game logic
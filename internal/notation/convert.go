@@ -0,0 +1,260 @@
+package notation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Export writes the notation of a game read from repo, archived or not
+func Export(repo store.Store, gameID string) (*Game, error) {
+	record, err := archive.Load(repo, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return FromRecord(record)
+}
+
+// FromRecord turns a game's history into notation. Every round must have a
+// recorded deal.
+func FromRecord(record *archive.Record) (*Game, error) {
+	g := &Game{ID: record.GameID, Ruleset: Ruleset, Date: record.CreatedAt}
+	if record.StartedAt != nil {
+		g.Date = *record.StartedAt
+	}
+	if record.LoserID != nil {
+		g.LoserID = *record.LoserID
+	}
+	for _, p := range record.Players {
+		player := Player{ID: p.UserID, Name: p.Name}
+		if p.IsBot {
+			player.Bot = p.BotDifficulty
+			if player.Bot == "" {
+				player.Bot = "easy"
+			}
+		}
+		g.Players = append(g.Players, player)
+	}
+
+	for _, r := range record.Rounds {
+		if r.Deal == nil {
+			return nil, fmt.Errorf("the deal of round %d was not recorded", r.RoundNumber)
+		}
+		round := Round{Number: r.RoundNumber, Seed: r.Seed, Seats: r.Seats, Hands: r.Deal, Letter: r.Letter}
+		if r.LoserID != nil {
+			round.Loser = *r.LoserID
+		}
+		for _, t := range r.Tricks {
+			var trick Trick
+			cut := t.Status == "cut"
+			for _, p := range t.Plays {
+				play := Play{Card: p.Card}
+				if suit, _, _ := splitCode(p.Card); cut && p.PlayerID == t.CutPlayerID && suit != t.LeadSuit {
+					play.Cut, cut = true, false
+				}
+				trick.Plays = append(trick.Plays, play)
+			}
+			round.Tricks = append(round.Tricks, trick)
+		}
+		g.Rounds = append(g.Rounds, round)
+	}
+	return g, nil
+}
+
+// Check plays a parsed game through and returns its rounds in archive form.
+// It fails on a card played out of turn or from the wrong hand, on a player
+// not following suit when they could, and on a cut marker that does not sit
+// on the first card off the lead suit, which always ends the trick.
+func Check(g *Game) ([]model.ArchivedRound, error) {
+	var rounds []model.ArchivedRound
+	for _, r := range g.Rounds {
+		ar, err := checkRound(r)
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %w", r.Number, err)
+		}
+		rounds = append(rounds, *ar)
+	}
+	return rounds, nil
+}
+
+func checkRound(r Round) (*model.ArchivedRound, error) {
+	ar := &model.ArchivedRound{RoundNumber: r.Number, Seed: r.Seed, Seats: r.Seats, Deal: r.Hands, Letter: r.Letter}
+	if r.Loser != "" {
+		loser := r.Loser
+		ar.LoserID = &loser
+	}
+
+	hands := make(map[string]map[string]bool)
+	holder := make(map[string]string)
+	for userID, codes := range r.Hands {
+		hands[userID] = make(map[string]bool)
+		for _, code := range codes {
+			hands[userID][code] = true
+			holder[code] = userID
+		}
+	}
+	finished := make(map[string]bool)
+	// The ace of spades leads the round; after that the cutter or the winner
+	// of the last trick leads, or the next player if they are out of cards
+	leader := holder["AS"]
+
+	for i, t := range r.Tricks {
+		start := nextPlayer(r.Seats, leader, finished, nil)
+		if holder[t.Plays[0].Card] != start {
+			return nil, fmt.Errorf("trick %d: %s leads but it is %s's lead", i+1, t.Plays[0].Card, nonEmpty(start, "nobody"))
+		}
+		leadSuit, _, _ := splitCode(t.Plays[0].Card)
+		trick := model.ArchivedTrick{Number: i + 1, StartPlayerID: start, LeadSuit: leadSuit, Status: "completed"}
+		played := make(map[string]bool)
+		bestValue := 0
+
+		for j, p := range t.Plays {
+			player := holder[p.Card]
+			if expected := nextPlayer(r.Seats, start, finished, played); player == "" || player != expected {
+				return nil, fmt.Errorf("trick %d: %s was played but it is %s's turn", i+1, p.Card, nonEmpty(expected, "nobody"))
+			}
+			suit, value, _ := splitCode(p.Card)
+			if suit != leadSuit {
+				for code := range hands[player] {
+					if s, _, _ := splitCode(code); s == leadSuit {
+						return nil, fmt.Errorf("trick %d: %s cut with %s while holding %s", i+1, player, p.Card, code)
+					}
+				}
+				if !p.Cut {
+					return nil, fmt.Errorf("trick %d: %s cuts but is not marked", i+1, p.Card)
+				}
+				if j != len(t.Plays)-1 {
+					return nil, fmt.Errorf("trick %d: cards played after the cut", i+1)
+				}
+				trick.Status, trick.CutPlayerID = "cut", player
+			} else if p.Cut {
+				return nil, fmt.Errorf("trick %d: %s follows suit but is marked as a cut", i+1, p.Card)
+			}
+			if suit == leadSuit && value > bestValue {
+				bestValue, trick.WinnerID = value, player
+			}
+
+			delete(hands[player], p.Card)
+			delete(holder, p.Card)
+			played[player] = true
+			if len(hands[player]) == 0 && !finished[player] {
+				finished[player] = true
+				ar.FinishOrder = append(ar.FinishOrder, player)
+			}
+			trick.Plays = append(trick.Plays, model.ArchivedPlay{PlayerID: player, Card: p.Card})
+		}
+
+		if trick.Status == "cut" {
+			leader = trick.CutPlayerID
+			for _, p := range t.Plays {
+				hands[trick.WinnerID][p.Card] = true
+				holder[p.Card] = trick.WinnerID
+			}
+		} else if next := nextPlayer(r.Seats, start, finished, played); next != "" {
+			if i < len(r.Tricks)-1 || r.Loser != "" {
+				return nil, fmt.Errorf("trick %d ends before %s played", i+1, next)
+			}
+			trick.Status = "active"
+		} else {
+			leader = trick.WinnerID
+		}
+		ar.Tricks = append(ar.Tricks, trick)
+	}
+	if r.Loser == "" {
+		ar.FinishOrder = nil
+	} else {
+		ar.FinishOrder = append(ar.FinishOrder, r.Loser)
+	}
+	return ar, nil
+}
+
+// nextPlayer is the first seated player from start onwards who is still in
+// the round and has not played in the trick, or "" if there is none
+func nextPlayer(seats []string, start string, finished, played map[string]bool) string {
+	startIdx := -1
+	for i, userID := range seats {
+		if userID == start {
+			startIdx = i
+		}
+	}
+	if startIdx < 0 {
+		return ""
+	}
+	for i := range seats {
+		userID := seats[(startIdx+i)%len(seats)]
+		if !finished[userID] && !played[userID] {
+			return userID
+		}
+	}
+	return ""
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// Import checks a parsed game and stores it as a new archived game, which
+// can then be replayed like any other finished game. It gets a fresh ID and
+// no game row, so it never shows up in game lists or anyone's statistics.
+// It returns the new game's ID.
+func Import(repo store.Store, g *Game) (string, error) {
+	rounds, err := Check(g)
+	if err != nil {
+		return "", err
+	}
+	letters := make(map[string]string)
+	for _, r := range rounds {
+		if r.LoserID != nil {
+			letters[*r.LoserID] += r.Letter
+		}
+	}
+	players := make([]model.ArchivedPlayer, 0, len(g.Players))
+	for i, p := range g.Players {
+		players = append(players, model.ArchivedPlayer{
+			UserID:        p.ID,
+			Name:          p.Name,
+			IsBot:         p.Bot != "",
+			BotDifficulty: p.Bot,
+			JoinOrder:     i,
+			DonkeyLetters: letters[p.ID],
+		})
+	}
+
+	playersJSON, err := json.Marshal(players)
+	if err != nil {
+		return "", err
+	}
+	roundsJSON, err := json.Marshal(rounds)
+	if err != nil {
+		return "", err
+	}
+	// The requester always sits last
+	requester := g.Players[0].ID
+	if len(g.Rounds) > 0 {
+		requester = g.Rounds[0].Seats[len(g.Rounds[0].Seats)-1]
+	}
+	record := &model.GameArchive{
+		GameID:      model.NewID(),
+		RequesterID: requester,
+		Players:     string(playersJSON),
+		Rounds:      string(roundsJSON),
+		CreatedAt:   g.Date,
+		StartedAt:   &g.Date,
+		CompletedAt: &g.Date,
+		ArchivedAt:  time.Now(),
+	}
+	if g.LoserID != "" {
+		record.LoserID = &g.LoserID
+	}
+	if err := repo.Archives().Save(record); err != nil {
+		return "", fmt.Errorf("failed to save game: %w", err)
+	}
+	return record.GameID, nil
+}
@@ -0,0 +1,391 @@
+// Package notation reads and writes Donkey game notation, a plain-text record
+// of a whole game that can be shared and replayed:
+//
+//	[Game "9f2c41d0e7a84b6c"]
+//	[Ruleset "standard"]
+//	[Date "2026-10-18T20:15:00Z"]
+//	[Player "ann" "Ann"]
+//	[Bot "b1" "Dusty" "easy"]
+//	[Loser "ann"]
+//
+//	Round 1
+//	[Seed "1760818500000000000"]
+//	[Seats "b1 ann"]
+//	[Hand "b1" "KS 3D ..."]
+//	[Hand "ann" "AS 2H ..."]
+//	1. AS 3D*
+//	2. KS
+//	[Letter "ann" "D"]
+//
+// Headers are a name and one or more quoted values. Each round lists the
+// seating, every hand as dealt and then one line per trick: the card codes
+// in the order they were played, the card that cut marked with "*". Who
+// played each card follows from the hands. Lines starting with ";" are
+// comments.
+package notation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ruleset is the only ruleset games are played under so far
+const Ruleset = "standard"
+
+// Game is a parsed game
+type Game struct {
+	ID      string
+	Ruleset string
+	Date    time.Time
+	Players []Player // in join order
+	LoserID string   // empty while nobody has lost the game
+	Rounds  []Round
+}
+
+// Player is a seat at the game
+type Player struct {
+	ID   string
+	Name string
+	Bot  string // difficulty; empty for a human
+}
+
+// Round is one round of a game
+type Round struct {
+	Number int
+	Seed   int64
+	Seats  []string            // user IDs in seating order
+	Hands  map[string][]string // userID -> card codes in the order dealt
+	Tricks []Trick
+	Loser  string // empty if the round was not finished
+	Letter string
+}
+
+// Trick is the cards played in one turn
+type Trick struct {
+	Plays []Play
+}
+
+// Play is a card played in a trick
+type Play struct {
+	Card string // card code, e.g. "AS"
+	Cut  bool   // the card cut the lead suit
+}
+
+// Write writes g in notation
+func Write(w io.Writer, g *Game) error {
+	b := &strings.Builder{}
+	header(b, "Game", g.ID)
+	header(b, "Ruleset", g.Ruleset)
+	header(b, "Date", g.Date.UTC().Format(time.RFC3339))
+	for _, p := range g.Players {
+		if p.Bot != "" {
+			header(b, "Bot", p.ID, p.Name, p.Bot)
+		} else {
+			header(b, "Player", p.ID, p.Name)
+		}
+	}
+	if g.LoserID != "" {
+		header(b, "Loser", g.LoserID)
+	}
+	for _, r := range g.Rounds {
+		fmt.Fprintf(b, "\nRound %d\n", r.Number)
+		if r.Seed != 0 {
+			header(b, "Seed", strconv.FormatInt(r.Seed, 10))
+		}
+		header(b, "Seats", strings.Join(r.Seats, " "))
+		for _, userID := range r.Seats {
+			header(b, "Hand", userID, strings.Join(r.Hands[userID], " "))
+		}
+		for i, t := range r.Tricks {
+			fmt.Fprintf(b, "%d.", i+1)
+			for _, p := range t.Plays {
+				b.WriteString(" " + p.Card)
+				if p.Cut {
+					b.WriteString("*")
+				}
+			}
+			b.WriteString("\n")
+		}
+		if r.Loser != "" {
+			header(b, "Letter", r.Loser, r.Letter)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func header(b *strings.Builder, name string, values ...string) {
+	b.WriteString("[" + name)
+	for _, v := range values {
+		b.WriteString(" " + strconv.Quote(v))
+	}
+	b.WriteString("]\n")
+}
+
+// SyntaxError reports where a game could not be parsed
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse reads a game in notation. It checks the syntax, the card codes and
+// that every round's deal is a full deck; Check verifies the play itself.
+func Parse(r io.Reader) (*Game, error) {
+	g := &Game{}
+	var round *Round
+	scanner := bufio.NewScanner(r)
+	line := 0
+	fail := func(format string, args ...interface{}) error {
+		return &SyntaxError{Line: line, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, ";"):
+			continue
+
+		case strings.HasPrefix(text, "Round "):
+			n, err := strconv.Atoi(strings.TrimPrefix(text, "Round "))
+			if err != nil || n != len(g.Rounds)+1 {
+				return nil, fail("expected round %d", len(g.Rounds)+1)
+			}
+			g.Rounds = append(g.Rounds, Round{Number: n, Hands: make(map[string][]string)})
+			round = &g.Rounds[len(g.Rounds)-1]
+
+		case strings.HasPrefix(text, "["):
+			name, values, err := parseHeader(text)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			if err := g.apply(round, name, values); err != nil {
+				return nil, fail("%v", err)
+			}
+
+		default:
+			if round == nil {
+				return nil, fail("trick before the first round")
+			}
+			trick, err := parseTrick(text, len(round.Tricks)+1)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			round.Tricks = append(round.Tricks, trick)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	line = 0
+	if g.Ruleset != Ruleset {
+		return nil, fail("unsupported ruleset %q", g.Ruleset)
+	}
+	if len(g.Players) < 2 {
+		return nil, fail("a game needs at least two players")
+	}
+	for _, r := range g.Rounds {
+		if err := checkDeal(r); err != nil {
+			return nil, fail("round %d: %v", r.Number, err)
+		}
+	}
+	return g, nil
+}
+
+// parseHeader splits `[Name "v1" "v2"]` into its name and values
+func parseHeader(text string) (string, []string, error) {
+	if !strings.HasSuffix(text, "]") {
+		return "", nil, fmt.Errorf("unterminated header")
+	}
+	body := strings.TrimSpace(text[1 : len(text)-1])
+	name, rest, _ := strings.Cut(body, " ")
+	var values []string
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return "", nil, fmt.Errorf("header %s: values must be quoted", name)
+		}
+		v, _ := strconv.Unquote(quoted)
+		values = append(values, v)
+		rest = rest[len(quoted):]
+	}
+	return name, values, nil
+}
+
+// headerArity is how many values each header takes
+var headerArity = map[string]int{
+	"Game": 1, "Ruleset": 1, "Date": 1, "Player": 2, "Bot": 3, "Loser": 1,
+	"Seed": 1, "Seats": 1, "Hand": 2, "Letter": 2,
+}
+
+func (g *Game) apply(round *Round, name string, values []string) error {
+	arity, ok := headerArity[name]
+	if !ok {
+		return fmt.Errorf("unknown header %s", name)
+	}
+	if len(values) != arity {
+		return fmt.Errorf("header %s takes %d values", name, arity)
+	}
+	roundHeader := name == "Seed" || name == "Seats" || name == "Hand" || name == "Letter"
+	if roundHeader && round == nil {
+		return fmt.Errorf("header %s outside a round", name)
+	}
+	if !roundHeader && round != nil {
+		return fmt.Errorf("header %s inside a round", name)
+	}
+
+	switch name {
+	case "Game":
+		g.ID = values[0]
+	case "Ruleset":
+		g.Ruleset = values[0]
+	case "Date":
+		t, err := time.Parse(time.RFC3339, values[0])
+		if err != nil {
+			return fmt.Errorf("invalid date %q", values[0])
+		}
+		g.Date = t
+	case "Player", "Bot":
+		p := Player{ID: values[0], Name: values[1]}
+		if name == "Bot" {
+			p.Bot = values[2]
+		}
+		if p.ID == "" || strings.ContainsAny(p.ID, " \t") {
+			return fmt.Errorf("invalid player ID %q", p.ID)
+		}
+		if g.player(p.ID) != nil {
+			return fmt.Errorf("player %s listed twice", p.ID)
+		}
+		g.Players = append(g.Players, p)
+	case "Loser":
+		if g.player(values[0]) == nil {
+			return fmt.Errorf("unknown player %s", values[0])
+		}
+		g.LoserID = values[0]
+	case "Seed":
+		seed, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed %q", values[0])
+		}
+		round.Seed = seed
+	case "Seats":
+		round.Seats = strings.Fields(values[0])
+		for _, userID := range round.Seats {
+			if g.player(userID) == nil {
+				return fmt.Errorf("unknown player %s", userID)
+			}
+		}
+	case "Hand":
+		if !seated(round, values[0]) {
+			return fmt.Errorf("player %s is not seated", values[0])
+		}
+		if _, ok := round.Hands[values[0]]; ok {
+			return fmt.Errorf("hand of %s listed twice", values[0])
+		}
+		codes := strings.Fields(values[1])
+		for _, code := range codes {
+			if _, _, ok := splitCode(code); !ok {
+				return fmt.Errorf("invalid card %q", code)
+			}
+		}
+		round.Hands[values[0]] = codes
+	case "Letter":
+		if !seated(round, values[0]) {
+			return fmt.Errorf("player %s is not seated", values[0])
+		}
+		round.Loser, round.Letter = values[0], values[1]
+	}
+	return nil
+}
+
+func (g *Game) player(id string) *Player {
+	for i := range g.Players {
+		if g.Players[i].ID == id {
+			return &g.Players[i]
+		}
+	}
+	return nil
+}
+
+func seated(round *Round, userID string) bool {
+	for _, id := range round.Seats {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrick reads a line such as "3. AS KS 2H*"
+func parseTrick(text string, number int) (Trick, error) {
+	fields := strings.Fields(text)
+	if fields[0] != strconv.Itoa(number)+"." {
+		return Trick{}, fmt.Errorf("expected trick %d", number)
+	}
+	if len(fields) == 1 {
+		return Trick{}, fmt.Errorf("trick %d has no cards", number)
+	}
+	var t Trick
+	for _, f := range fields[1:] {
+		p := Play{Card: strings.TrimSuffix(f, "*"), Cut: strings.HasSuffix(f, "*")}
+		if _, _, ok := splitCode(p.Card); !ok {
+			return Trick{}, fmt.Errorf("invalid card %q", f)
+		}
+		t.Plays = append(t.Plays, p)
+	}
+	return t, nil
+}
+
+// checkDeal makes sure every seat was dealt and the hands hold one deck
+func checkDeal(r Round) error {
+	if len(r.Seats) == 0 {
+		return fmt.Errorf("no seats")
+	}
+	seen := make(map[string]bool)
+	for _, userID := range r.Seats {
+		hand, ok := r.Hands[userID]
+		if !ok {
+			return fmt.Errorf("no hand for %s", userID)
+		}
+		for _, code := range hand {
+			if seen[code] {
+				return fmt.Errorf("card %s dealt twice", code)
+			}
+			seen[code] = true
+		}
+	}
+	if len(seen) != 52 {
+		return fmt.Errorf("%d cards dealt, expected 52", len(seen))
+	}
+	return nil
+}
+
+var suits = map[byte]string{'S': "spades", 'H': "hearts", 'D': "diamonds", 'C': "clubs"}
+
+var ranks = map[string]int{
+	"2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9, "10": 10,
+	"J": 11, "Q": 12, "K": 13, "A": 14,
+}
+
+// splitCode turns a code made by model.Card.CardCode back into its suit and
+// the rank's value
+func splitCode(code string) (suit string, value int, ok bool) {
+	if len(code) < 2 {
+		return "", 0, false
+	}
+	suit, ok = suits[code[len(code)-1]]
+	if !ok {
+		return "", 0, false
+	}
+	value, ok = ranks[code[:len(code)-1]]
+	return suit, value, ok
+}
@@ -0,0 +1,92 @@
+package notation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/notation"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Ann holds the spades and hearts, Bob the diamonds and clubs. Bob has to
+// cut Ann's ace, leads diamonds after it, and Ann, who picked up 2D with the
+// cut, must follow.
+const game = `[Game "g1"]
+[Ruleset "standard"]
+[Date "2026-10-18T20:15:00Z"]
+[Bot "bob" "Bob" "easy"]
+[Player "ann" "Ann \"the Ace\""]
+
+Round 1
+[Seed "42"]
+[Seats "bob ann"]
+[Hand "bob" "2D 3D 4D 5D 6D 7D 8D 9D 10D JD QD KD AD 2C 3C 4C 5C 6C 7C 8C 9C 10C JC QC KC AC"]
+[Hand "ann" "2S 3S 4S 5S 6S 7S 8S 9S 10S JS QS KS AS 2H 3H 4H 5H 6H 7H 8H 9H 10H JH QH KH AH"]
+1. AS 2D*
+2. 3D 2D
+3. 4D 2S*
+`
+
+func TestRoundTripThroughStore(t *testing.T) {
+	g, err := notation.Parse(strings.NewReader(game))
+	require.NoError(t, err)
+	assert.Equal(t, `Ann "the Ace"`, g.Players[1].Name)
+	require.Len(t, g.Rounds[0].Tricks, 3)
+	assert.True(t, g.Rounds[0].Tricks[0].Plays[1].Cut)
+
+	rounds, err := notation.Check(g)
+	require.NoError(t, err)
+	tricks := rounds[0].Tricks
+	assert.Equal(t, "cut", tricks[0].Status)
+	assert.Equal(t, "ann", tricks[0].WinnerID)
+	assert.Equal(t, "bob", tricks[0].CutPlayerID)
+	assert.Equal(t, "completed", tricks[1].Status)
+	assert.Equal(t, "bob", tricks[1].StartPlayerID)
+	assert.Equal(t, "ann", tricks[1].Plays[1].PlayerID)
+	assert.Equal(t, "bob", tricks[2].WinnerID)
+
+	repo := store.NewMemory()
+	id, err := notation.Import(repo, g)
+	require.NoError(t, err)
+	exported, err := notation.Export(repo, id)
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, notation.Write(&out, exported))
+	assert.Equal(t, strings.Replace(game, `"g1"`, `"`+id+`"`, 1), out.String())
+}
+
+func TestCheckRejectsIllegalPlay(t *testing.T) {
+	for name, tricks := range map[string]string{
+		"unmarked cut":        "1. AS 2D",
+		"marked follow":       "1. AS 2D*\n2. 3D* 2D",
+		"play after cut":      "1. AS 2D* 3D",
+		"out of turn":         "1. AS 2D*\n2. 2S",
+		"not following suit":  "1. AS 2D*\n2. 3D 2H*",
+		"incomplete trick":    "1. AS 2D*\n2. 3D\n3. 4D 2D",
+		"card played twice":   "1. AS 2D*\n2. 3D 2D\n3. 3D",
+		"card from no hand":   "1. XS",
+		"wrong trick numbers": "2. AS 2D*",
+	} {
+		t.Run(name, func(t *testing.T) {
+			text := game[:strings.Index(game, "1. ")] + tricks + "\n"
+			g, err := notation.Parse(strings.NewReader(text))
+			if err == nil {
+				_, err = notation.Check(g)
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseReportsLine(t *testing.T) {
+	_, err := notation.Parse(strings.NewReader("[Game \"g1\"]\n[Ruleset standard]\n"))
+	var syntax *notation.SyntaxError
+	require.ErrorAs(t, err, &syntax)
+	assert.Equal(t, 2, syntax.Line)
+
+	_, err = notation.Parse(strings.NewReader(strings.Replace(game, " AH\"]", "\"]", 1)))
+	assert.ErrorContains(t, err, "51 cards dealt")
+}
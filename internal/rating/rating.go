@@ -0,0 +1,189 @@
+// Package rating keeps a skill rating for every human player and for each bot
+// difficulty. Ratings follow a multiplayer Elo model: after each rated game
+// every player is scored against every other one, the game's DONKEY losing to
+// all and the rest ranked by their average finish position over the rounds.
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+const (
+	// Initial is the rating of a subject before their first rated game
+	Initial = 1500.0
+	// ProvisionalGames is how many rated games a rating needs to be trusted
+	ProvisionalGames = 10
+
+	// K factors: provisional ratings move twice as fast to settle sooner
+	kEstablished = 24.0
+	kProvisional = 48.0
+)
+
+// Subject is the ID a user's rating is kept under; all bots of a difficulty
+// share one
+func Subject(u model.User) string {
+	if u.IsBot {
+		return "bot:" + nonEmpty(u.BotDifficulty, "easy")
+	}
+	return u.ID
+}
+
+// Provisional reports whether a rating rests on too few games to be trusted
+func Provisional(r model.Rating) bool {
+	return r.Games < ProvisionalGames
+}
+
+// Get returns a subject's rating, or a fresh one if they have never played a
+// rated game
+func Get(repo store.Store, subjectID string) (*model.Rating, error) {
+	r, err := repo.Ratings().Get(subjectID)
+	if errors.Is(err, store.ErrNotFound) {
+		return &model.Rating{SubjectID: subjectID, Rating: Initial}, nil
+	}
+	return r, err
+}
+
+// Update moves the ratings of a completed game's players and records each
+// change, unless the game is casual. It reads the game's statistics, so it
+// runs after stats.Record. All the changes are saved together, and a game
+// that already moved the ratings is not rated again: its changes are
+// returned as they were.
+func Update(repo store.Store, gameID string) ([]model.RatingChange, error) {
+	var changes []model.RatingChange
+	err := repo.Transaction(func(tx store.Store) error {
+		done, err := tx.Ratings().ForGame(gameID)
+		if err != nil {
+			return fmt.Errorf("failed to load rating changes: %w", err)
+		}
+		if len(done) > 0 {
+			changes = done
+			return nil
+		}
+		changes, err = update(tx, gameID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func update(repo store.Store, gameID string) ([]model.RatingChange, error) {
+	settings, err := repo.Games().Settings(gameID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load game settings: %w", err)
+	}
+	if settings != nil && !settings.Rated {
+		return nil, nil
+	}
+	rows, err := repo.Stats().ForGame(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game stats: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, nil
+	}
+	players, err := repo.Games().Players(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game players: %w", err)
+	}
+	subjectOf := make(map[string]string, len(players))
+	for _, gp := range players {
+		subjectOf[gp.UserID] = Subject(gp.User)
+	}
+
+	ratings := make(map[string]*model.Rating)
+	for _, row := range rows {
+		subject := subjectOf[row.UserID]
+		if subject == "" {
+			return nil, fmt.Errorf("player %s is not in game %s", row.UserID, gameID)
+		}
+		if ratings[subject] == nil {
+			if ratings[subject], err = Get(repo, subject); err != nil {
+				return nil, fmt.Errorf("failed to load rating: %w", err)
+			}
+		}
+	}
+
+	// Each seat plays everyone else at the table; bots sharing a difficulty
+	// pool their seats' results into one change
+	deltas := make(map[string]float64)
+	for _, a := range rows {
+		ra := ratings[subjectOf[a.UserID]]
+		k := kEstablished
+		if Provisional(*ra) {
+			k = kProvisional
+		}
+		for _, b := range rows {
+			rb := ratings[subjectOf[b.UserID]]
+			if ra == rb {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (rb.Rating-ra.Rating)/400))
+			deltas[ra.SubjectID] += k * (score(a, b) - expected) / float64(len(rows)-1)
+		}
+	}
+
+	subjects := make([]string, 0, len(ratings))
+	for subject := range ratings {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	now := time.Now()
+	changes := make([]model.RatingChange, 0, len(subjects))
+	for _, subject := range subjects {
+		r := ratings[subject]
+		change := model.RatingChange{
+			ID:        model.NewID(),
+			SubjectID: subject,
+			GameID:    gameID,
+			Before:    r.Rating,
+			After:     math.Round((r.Rating+deltas[subject])*10) / 10,
+			CreatedAt: now,
+		}
+		r.Rating, r.Games, r.UpdatedAt = change.After, r.Games+1, now
+		if err := repo.Ratings().Save(r); err != nil {
+			return nil, fmt.Errorf("failed to save rating: %w", err)
+		}
+		if err := repo.Ratings().AddChange(&change); err != nil {
+			return nil, fmt.Errorf("failed to save rating change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// score is a's result against b: 1 for a better game, 0 for a worse one and
+// 0.5 for a tie. The DONKEY loses to everyone; otherwise the lower average
+// finish position wins.
+func score(a, b model.PlayerGameStats) float64 {
+	if a.Lost != b.Lost {
+		if a.Lost {
+			return 0
+		}
+		return 1
+	}
+	// Compare FinishPositionSum/RoundsPlayed without dividing
+	pa, pb := a.FinishPositionSum*b.RoundsPlayed, b.FinishPositionSum*a.RoundsPlayed
+	switch {
+	case pa < pb:
+		return 1
+	case pa > pb:
+		return 0
+	}
+	return 0.5
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
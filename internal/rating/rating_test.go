@@ -0,0 +1,79 @@
+package rating_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/rating"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// finishedGame stores a completed game whose players averaged the given
+// finish positions over four rounds; the last player is the DONKEY
+func finishedGame(t *testing.T, repo store.Store, rated bool, players []model.User, positions []int) string {
+	t.Helper()
+	now := time.Now()
+	g := model.Game{ID: model.NewID(), Status: "completed", CreatedAt: now, CompletedAt: &now}
+	require.NoError(t, repo.Games().Create(&g))
+	require.NoError(t, repo.Games().CreateSettings(&model.GameSettings{GameID: g.ID, Rated: rated}))
+	var rows []model.PlayerGameStats
+	for i, u := range players {
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: u.ID, JoinOrder: i}))
+		rows = append(rows, model.PlayerGameStats{
+			UserID: u.ID, CompletedAt: now, RoundsPlayed: 4, FinishPositionSum: 4 * positions[i], Lost: i == len(players)-1,
+		})
+	}
+	require.NoError(t, repo.Stats().Record(g.ID, rows))
+	return g.ID
+}
+
+func TestRatingsFollowFinishOrder(t *testing.T) {
+	repo := store.NewMemory()
+	ann := model.User{ID: "ann", Name: "Ann"}
+	bob := model.User{ID: "bob", Name: "Bob"}
+	bot1 := model.User{ID: "b1", Name: "Dusty", IsBot: true, BotDifficulty: "easy"}
+	bot2 := model.User{ID: "b2", Name: "Rusty", IsBot: true, BotDifficulty: "easy"}
+	for _, u := range []*model.User{&ann, &bob, &bot1, &bot2} {
+		require.NoError(t, repo.Users().Create(u))
+	}
+
+	// Ann always goes out first and Bob ends up the DONKEY
+	gameID := finishedGame(t, repo, true, []model.User{ann, bot1, bot2, bob}, []int{1, 2, 2, 3})
+	changes, err := rating.Update(repo, gameID)
+	require.NoError(t, err)
+	require.Len(t, changes, 3, "both easy bots share one rating")
+
+	after := make(map[string]float64)
+	total := 0.0
+	for _, c := range changes {
+		assert.Equal(t, rating.Initial, c.Before)
+		after[c.SubjectID] = c.After
+		total += c.After - c.Before
+	}
+	assert.Greater(t, after["ann"], after["bot:easy"])
+	assert.Greater(t, after["bot:easy"], after["bob"])
+	assert.InDelta(t, 0, total, 0.2, "equal K factors move no points in or out")
+
+	// Updating the game again changes nothing
+	again, err := rating.Update(repo, gameID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, changes, again)
+
+	r, err := repo.Ratings().Get("bot:easy")
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Games)
+	assert.True(t, rating.Provisional(*r))
+
+	// Casual games leave ratings alone
+	casual := finishedGame(t, repo, false, []model.User{bob, ann}, []int{1, 2})
+	changes, err = rating.Update(repo, casual)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	history, err := repo.Ratings().History("ann")
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
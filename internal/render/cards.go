@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"sync"
+
+	"github.com/kairodrad/donkey/internal/game"
+)
+
+// Card images are drawn from the PNGs in web/assets, which are far larger
+// than anything drawn here. Each is decoded and shrunk once per size and kept.
+var cardImages = struct {
+	sync.Mutex
+	scaled map[cardSize]*image.RGBA
+}{scaled: make(map[cardSize]*image.RGBA)}
+
+type cardSize struct {
+	path  string
+	width int
+}
+
+// cardFace returns the face of a card such as "AS", width pixels wide
+func cardFace(code string, width int) (*image.RGBA, error) {
+	return cardImage(game.Card(code).AssetPath(), width)
+}
+
+// cardBack returns the back of a card in one of game.BackColors, width
+// pixels wide
+func cardBack(color string, width int) (*image.RGBA, error) {
+	return cardImage(game.BackAssetPath(color), width)
+}
+
+func cardImage(path string, width int) (*image.RGBA, error) {
+	key := cardSize{path, width}
+	cardImages.Lock()
+	defer cardImages.Unlock()
+	if img, ok := cardImages.scaled[key]; ok {
+		return img, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open card image: %w", err)
+	}
+	defer f.Close()
+	src, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	img := shrink(src, width)
+	cardImages.scaled[key] = img
+	return img, nil
+}
+
+// shrink scales src down to width pixels, keeping its aspect ratio. Each
+// pixel is the average of the source pixels it covers, so the fine lines of
+// a card face survive.
+func shrink(src image.Image, width int) *image.RGBA {
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	if width >= b.Dx() {
+		return rgba
+	}
+	height := max(1, b.Dy()*width/b.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*b.Dy()/height, max((y+1)*b.Dy()/height, y*b.Dy()/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*b.Dx()/width, max((x+1)*b.Dx()/width, x*b.Dx()/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+x0*4 : sy*rgba.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// The font is a 5x7 pixel bitmap with a pixel of spacing on each side, so
+// text of scale s is 6s pixels per character wide and 8s pixels tall.
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+	lineHeight   = glyphHeight + 1
+)
+
+// glyphs holds the rows of each character, '#' for a lit pixel. Letters are
+// capitals only; text is upper-cased before drawing and characters without a
+// glyph are drawn as '?'.
+var glyphs = map[rune][glyphHeight]string{
+	' ':  {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3':  {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'.':  {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	',':  {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	':':  {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'-':  {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'_':  {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	'\'': {"..#..", "..#..", ".#...", ".....", ".....", ".....", "....."},
+	'!':  {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+	'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'#':  {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'&':  {".##..", "#..#.", "#.#..", ".#...", "#.#.#", "#..#.", ".##.#"},
+	'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'/':  {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+}
+
+// textWidth returns how many pixels wide text is drawn at scale
+func textWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*glyphAdvance - 1) * scale
+}
+
+// drawText draws text with its top-left corner at x, y, each font pixel a
+// scale by scale square
+func drawText(dst draw.Image, x, y int, text string, scale int, c color.Color) {
+	ink := image.NewUniform(c)
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := glyphs[r]
+		if !ok {
+			glyph = glyphs['?']
+		}
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				px := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, px, ink, image.Point{}, draw.Src)
+			}
+		}
+		x += glyphAdvance * scale
+	}
+}
+
+// fitText shortens text with a trailing '.' until it is at most width pixels
+// wide at scale
+func fitText(text string, width, scale int) string {
+	runes := []rune(text)
+	if textWidth(text, scale) <= width {
+		return text
+	}
+	for len(runes) > 1 {
+		runes = runes[:len(runes)-1]
+		if short := string(runes) + "."; textWidth(short, scale) <= width {
+			return short
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,360 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"math"
+
+	"github.com/kairodrad/donkey/internal/game"
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// ErrNoTricks is returned for a round whose tricks were not recorded
+var ErrNoTricks = errors.New("the tricks of the round were not recorded")
+
+// Round animation layout, in pixels
+const (
+	tableSize   = 400
+	seatRadiusX = 150
+	seatRadiusY = 135
+	seatBackW   = 28
+	playedCardW = 40
+	fanStep     = 16
+	pileBackW   = 24
+	moveSteps   = 3
+)
+
+// Frame delays, in hundredths of a second
+const (
+	moveDelay    = 5
+	landDelay    = 25
+	outcomeDelay = 120
+	clearDelay   = 40
+	openDelay    = 150
+	endDelay     = 400
+)
+
+// tablePalette is the web-safe colors plus those of the table, so the felt
+// and highlights come out exactly and cards close enough
+var tablePalette = append(append(color.Palette{}, palette.WebSafe...),
+	felt, feltLight, loserRow, red, gold, white, dim, color.RGBA{0, 0, 0, 0xff})
+
+var tableCenter = image.Pt(tableSize/2, tableSize/2-10)
+
+// RoundAnimation draws a round as an animated GIF, looping forever: the seats
+// around the table, every card moving from its player into the trick, and
+// each trick's end, a cut highlighted and the trick carried to whoever picks
+// it up, or swept to the discard pile.
+func RoundAnimation(players []model.ArchivedPlayer, round model.ArchivedRound) (*gif.GIF, error) {
+	if len(round.Tricks) == 0 {
+		return nil, ErrNoTricks
+	}
+	t := newTable(players, round)
+	a := &animator{
+		g:       &gif.GIF{Config: image.Config{ColorModel: tablePalette, Width: tableSize, Height: tableSize}},
+		indexes: make(map[color.RGBA]uint8),
+	}
+	frame := func(delay int) error {
+		if err := t.draw(a.canvas()); err != nil {
+			return err
+		}
+		a.emit(delay)
+		return nil
+	}
+
+	t.banner = fmt.Sprintf("Round %d", round.RoundNumber)
+	if err := frame(openDelay); err != nil {
+		return nil, err
+	}
+	t.banner = ""
+	for _, trick := range round.Tricks {
+		for i, play := range trick.Plays {
+			from, to := t.seatCenter(play.PlayerID), t.trickSlot(i)
+			if t.counts != nil {
+				t.counts[play.PlayerID]--
+			}
+			for step := 1; step < moveSteps; step++ {
+				t.moving = []movingCard{{play.Card, lerp(from, to, step, moveSteps)}}
+				if err := frame(moveDelay); err != nil {
+					return nil, err
+				}
+			}
+			t.moving = nil
+			t.trick = append(t.trick, trickCard{code: play.Card, highlight: trick.Status == "cut" && play.PlayerID == trick.CutPlayerID})
+			if err := frame(landDelay); err != nil {
+				return nil, err
+			}
+		}
+
+		// Carry the trick off: to whoever picks up a cut, else to the discards
+		var to image.Point
+		switch trick.Status {
+		case "cut":
+			t.banner = fmt.Sprintf("%s cuts! %s picks up %d", t.name(trick.CutPlayerID), t.name(trick.WinnerID), len(trick.Plays))
+			to = t.seatCenter(trick.WinnerID)
+		case "completed":
+			t.banner = t.name(trick.WinnerID) + " leads next"
+			to = t.pileCenter()
+		default:
+			continue
+		}
+		if err := frame(outcomeDelay); err != nil {
+			return nil, err
+		}
+		t.banner = ""
+		cards := t.trick
+		t.trick = nil
+		for step := 1; step < moveSteps; step++ {
+			t.moving = t.moving[:0]
+			for i, c := range cards {
+				t.moving = append(t.moving, movingCard{c.code, lerp(t.trickSlot(i), to, step, moveSteps)})
+			}
+			if err := frame(moveDelay); err != nil {
+				return nil, err
+			}
+		}
+		t.moving = nil
+		if trick.Status == "cut" {
+			if t.counts != nil {
+				t.counts[trick.WinnerID] += len(cards)
+			}
+		} else {
+			t.discards += len(cards)
+		}
+		if err := frame(clearDelay); err != nil {
+			return nil, err
+		}
+	}
+
+	if round.LoserID != nil {
+		t.banner = t.name(*round.LoserID) + " loses the round"
+		if round.Letter != "" {
+			t.banner = t.name(*round.LoserID) + " gets a " + round.Letter
+		}
+		t.loserID = *round.LoserID
+	}
+	if err := frame(endDelay); err != nil {
+		return nil, err
+	}
+	return a.g, nil
+}
+
+// table is what is on the table at one moment of a round
+type table struct {
+	seats    []string          // user IDs in seating order
+	names    map[string]string // userID -> name
+	counts   map[string]int    // userID -> cards in hand; nil if the deal was not recorded
+	trick    []trickCard
+	moving   []movingCard
+	discards int
+	banner   string
+	loserID  string
+}
+
+type trickCard struct {
+	code      string
+	highlight bool // the card that cut the trick
+}
+
+type movingCard struct {
+	code string
+	at   image.Point // center
+}
+
+func newTable(players []model.ArchivedPlayer, round model.ArchivedRound) *table {
+	t := &table{seats: round.Seats, names: make(map[string]string, len(players))}
+	for _, p := range players {
+		t.names[p.UserID] = p.Name
+	}
+	if len(t.seats) == 0 {
+		for _, p := range players {
+			t.seats = append(t.seats, p.UserID)
+		}
+	}
+	if round.Deal != nil {
+		t.counts = make(map[string]int, len(round.Deal))
+		for userID, cards := range round.Deal {
+			t.counts[userID] = len(cards)
+		}
+	}
+	return t
+}
+
+func (t *table) name(userID string) string {
+	if name := t.names[userID]; name != "" {
+		return name
+	}
+	return userID
+}
+
+// seatCenter returns where a player sits; the first seat is at the bottom
+// and the rest follow clockwise
+func (t *table) seatCenter(userID string) image.Point {
+	seat := 0
+	for i, id := range t.seats {
+		if id == userID {
+			seat = i
+		}
+	}
+	angle := math.Pi/2 + 2*math.Pi*float64(seat)/float64(max(len(t.seats), 1))
+	return image.Pt(
+		tableCenter.X+int(math.Round(seatRadiusX*math.Cos(angle))),
+		tableCenter.Y+int(math.Round(seatRadiusY*math.Sin(angle))),
+	)
+}
+
+// trickSlot returns the center of the i-th card of a trick. A trick holds at
+// most a card per seat, so the fan is laid out for a full one.
+func (t *table) trickSlot(i int) image.Point {
+	fanW := playedCardW + (max(len(t.seats), 1)-1)*fanStep
+	return image.Pt(tableCenter.X-fanW/2+playedCardW/2+i*fanStep, tableCenter.Y-16)
+}
+
+func (t *table) pileCenter() image.Point {
+	return image.Pt(margin/2+pileBackW/2, margin/2+pileBackW*3/4)
+}
+
+func (t *table) draw(dst *image.RGBA) error {
+	fill(dst, dst.Bounds(), felt)
+
+	if t.discards > 0 {
+		back, err := cardBack(game.BackColors[0], pileBackW)
+		if err != nil {
+			return err
+		}
+		pile := t.pileCenter()
+		paste(dst, back, pile.X-back.Bounds().Dx()/2, pile.Y-back.Bounds().Dy()/2)
+		drawText(dst, pile.X+pileBackW/2+6, pile.Y-glyphHeight, fmt.Sprint(t.discards), textScale, dim)
+	}
+
+	for i, userID := range t.seats {
+		at := t.seatCenter(userID)
+		back, err := cardBack(game.BackColors[i%len(game.BackColors)], seatBackW)
+		if err != nil {
+			return err
+		}
+		b := back.Bounds().Add(at.Sub(image.Pt(back.Bounds().Dx()/2, back.Bounds().Dy()/2)))
+		if userID == t.loserID {
+			fill(dst, b.Inset(-4), loserRow)
+		}
+		paste(dst, back, b.Min.X, b.Min.Y)
+		if t.counts != nil {
+			label := "out"
+			if n := t.counts[userID]; n > 0 {
+				label = fmt.Sprint(n)
+			}
+			drawText(dst, b.Max.X+4, at.Y-glyphHeight, label, textScale, white)
+		}
+		name := fitText(t.name(userID), 90, 1)
+		drawText(dst, at.X-textWidth(name, 1)/2, b.Max.Y+4, name, 1, white)
+	}
+
+	for i, c := range t.trick {
+		face, err := cardFace(c.code, playedCardW)
+		if err != nil {
+			return err
+		}
+		at := t.trickSlot(i)
+		b := face.Bounds().Add(at.Sub(image.Pt(face.Bounds().Dx()/2, face.Bounds().Dy()/2)))
+		if c.highlight {
+			fill(dst, b.Inset(-3), red)
+		}
+		paste(dst, face, b.Min.X, b.Min.Y)
+	}
+	for _, c := range t.moving {
+		face, err := cardFace(c.code, playedCardW)
+		if err != nil {
+			return err
+		}
+		paste(dst, face, c.at.X-face.Bounds().Dx()/2, c.at.Y-face.Bounds().Dy()/2)
+	}
+
+	if t.banner != "" {
+		text := fitText(t.banner, tableSize-2*margin, textScale)
+		drawText(dst, (tableSize-textWidth(text, textScale))/2, tableCenter.Y+30, text, textScale, gold)
+	}
+	return nil
+}
+
+// lerp returns the point step/steps of the way from a to b
+func lerp(a, b image.Point, step, steps int) image.Point {
+	return a.Add(b.Sub(a).Mul(step).Div(steps))
+}
+
+// animator collects the frames of a GIF. Each frame after the first only
+// covers the part of the table that changed, drawn over the frames before
+// it, which keeps a round of a few hundred frames small.
+type animator struct {
+	g       *gif.GIF
+	next    *image.RGBA // frame being drawn
+	last    *image.RGBA // last frame emitted
+	indexes map[color.RGBA]uint8
+}
+
+// canvas returns the image to draw the next frame on
+func (a *animator) canvas() *image.RGBA {
+	if a.next == nil {
+		a.next = image.NewRGBA(image.Rect(0, 0, tableSize, tableSize))
+	}
+	return a.next
+}
+
+// emit adds the canvas as the next frame, shown for delay hundredths of a
+// second. A frame that changes nothing lengthens the one before.
+func (a *animator) emit(delay int) {
+	bounds := a.next.Bounds()
+	if a.last != nil {
+		bounds = changed(a.last, a.next)
+		if bounds.Empty() {
+			a.g.Delay[len(a.g.Delay)-1] += delay
+			return
+		}
+	}
+	frame := image.NewPaletted(bounds, tablePalette)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			frame.SetColorIndex(x, y, a.index(a.next.RGBAAt(x, y)))
+		}
+	}
+	a.g.Image = append(a.g.Image, frame)
+	a.g.Delay = append(a.g.Delay, delay)
+	a.g.Disposal = append(a.g.Disposal, gif.DisposalNone)
+	a.last, a.next = a.next, a.last
+}
+
+// index returns the palette entry nearest to c
+func (a *animator) index(c color.RGBA) uint8 {
+	i, ok := a.indexes[c]
+	if !ok {
+		i = uint8(tablePalette.Index(c))
+		a.indexes[c] = i
+	}
+	return i
+}
+
+// changed returns the smallest rectangle holding every pixel that differs
+// between two images of the same size
+func changed(a, b *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+		rowA := a.Pix[a.PixOffset(a.Rect.Min.X, y):a.PixOffset(a.Rect.Max.X, y)]
+		rowB := b.Pix[b.PixOffset(b.Rect.Min.X, y):b.PixOffset(b.Rect.Max.X, y)]
+		if bytes.Equal(rowA, rowB) {
+			continue
+		}
+		first, last := 0, len(rowA)-1
+		for rowA[first] == rowB[first] {
+			first++
+		}
+		for rowA[last] == rowB[last] {
+			last--
+		}
+		r = r.Union(image.Rect(a.Rect.Min.X+first/4, y, a.Rect.Min.X+last/4+1, y+1))
+	}
+	return r
+}
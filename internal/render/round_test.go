@@ -0,0 +1,53 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+func threePlayerRound() ([]model.ArchivedPlayer, model.ArchivedRound) {
+	players := []model.ArchivedPlayer{{UserID: "ann", Name: "Ann"}, {UserID: "bob", Name: "Bob", JoinOrder: 1}, {UserID: "cy", Name: "Cy", JoinOrder: 2}}
+	cy := "cy"
+	return players, model.ArchivedRound{
+		RoundNumber: 2, LoserID: &cy, Letter: "O",
+		Seats: []string{"ann", "bob", "cy"},
+		Deal:  map[string][]string{"ann": {"AS", "KS", "2H"}, "bob": {"QS", "3S"}, "cy": {"JS", "4D", "5D"}},
+		Tricks: []model.ArchivedTrick{
+			{Number: 1, StartPlayerID: "ann", Status: "completed", WinnerID: "ann",
+				Plays: []model.ArchivedPlay{{PlayerID: "ann", Card: "AS"}, {PlayerID: "bob", Card: "QS"}, {PlayerID: "cy", Card: "JS"}}},
+			{Number: 2, StartPlayerID: "ann", Status: "cut", WinnerID: "ann", CutPlayerID: "cy",
+				Plays: []model.ArchivedPlay{{PlayerID: "ann", Card: "KS"}, {PlayerID: "bob", Card: "3S"}, {PlayerID: "cy", Card: "4D"}}},
+		},
+	}
+}
+
+func TestRoundAnimation(t *testing.T) {
+	players, round := threePlayerRound()
+	g, err := RoundAnimation(players, round)
+	require.NoError(t, err)
+	// An opening and a closing frame, each card's move and landing, and each
+	// trick's outcome and clearing
+	assert.Len(t, g.Image, 2+6*moveSteps+2*(1+moveSteps))
+	assert.Equal(t, tableSize, g.Image[0].Bounds().Dx(), "the first frame covers the table")
+	assert.Less(t, g.Image[1].Bounds().Dx(), tableSize, "later frames only cover what changed")
+	assert.Equal(t, endDelay, g.Delay[len(g.Delay)-1])
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, g))
+	decoded, err := gif.DecodeAll(&buf)
+	require.NoError(t, err)
+	assert.Len(t, decoded.Image, len(g.Image))
+}
+
+func TestRoundAnimationNeedsTricks(t *testing.T) {
+	players, round := threePlayerRound()
+	round.Tricks = nil
+	_, err := RoundAnimation(players, round)
+	assert.ErrorIs(t, err, ErrNoTricks)
+}
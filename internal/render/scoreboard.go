@@ -0,0 +1,153 @@
+// Package render draws pictures of games from their history, using the card
+// images in web/assets and only the standard library's image packages.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/game"
+)
+
+const donkeyWord = "DONKEY"
+
+var (
+	felt      = color.RGBA{0x1b, 0x5e, 0x20, 0xff}
+	feltLight = color.RGBA{0x2e, 0x7d, 0x32, 0xff}
+	loserRow  = color.RGBA{0x8e, 0x24, 0x24, 0xff}
+	red       = color.RGBA{0xc6, 0x28, 0x28, 0xff}
+	gold      = color.RGBA{0xff, 0xd5, 0x4f, 0xff}
+	white     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	dim       = color.RGBA{0xa5, 0xd6, 0xa7, 0xff}
+)
+
+// Scoreboard layout, in pixels
+const (
+	margin      = 24
+	headerH     = 120
+	columnsH    = 28
+	rowH        = 56
+	seatCardW   = 30
+	nameW       = 216
+	letterBoxW  = 26
+	letterGap   = 4
+	roundCellW  = 30
+	minBoardW   = 640
+	titleScale  = 6
+	textScale   = 2
+	headerCardW = 72
+)
+
+// Scoreboard draws the final result of a game: every player with their
+// DONKEY letters, the loser's row highlighted, and a timeline of the letter
+// each round cost and whom.
+func Scoreboard(record *archive.Record) (*image.RGBA, error) {
+	nameX := margin + seatCardW + 14
+	lettersX := nameX + nameW + 12
+	timelineX := lettersX + len(donkeyWord)*(letterBoxW+letterGap) + 12
+	width := max(minBoardW, timelineX+len(record.Rounds)*roundCellW+margin)
+	tableY := margin + headerH
+	height := tableY + columnsH + len(record.Players)*rowH + margin
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), felt)
+
+	// Header: the title, who lost and the ace of spades every round opens with
+	drawText(img, margin, margin, donkeyWord, titleScale, gold)
+	subY := margin + titleScale*lineHeight + 8
+	if name := playerName(record, record.LoserID); name != "" {
+		drawText(img, margin, subY, fitText(name+" is the donkey", width-2*margin-headerCardW, textScale), textScale, white)
+	}
+	summary := fmt.Sprintf("%d rounds", len(record.Rounds))
+	if record.CompletedAt != nil {
+		summary += " - " + record.CompletedAt.Format("2 Jan 2006")
+	}
+	drawText(img, margin, subY+textScale*lineHeight+6, summary, textScale, dim)
+	ace, err := cardFace("AS", headerCardW)
+	if err != nil {
+		return nil, err
+	}
+	paste(img, ace, width-margin-headerCardW, margin)
+
+	// Column headings
+	headY := tableY + (columnsH-textScale*glyphHeight)/2
+	drawText(img, nameX, headY, "Player", textScale, dim)
+	drawText(img, lettersX, headY, "Letters", textScale, dim)
+	for i, r := range record.Rounds {
+		label := strconv.Itoa(r.RoundNumber)
+		drawText(img, timelineX+i*roundCellW+(roundCellW-textWidth(label, textScale))/2, headY, label, textScale, dim)
+	}
+
+	for i, p := range record.Players {
+		top := tableY + columnsH + i*rowH
+		row := image.Rect(margin/2, top+2, width-margin/2, top+rowH-2)
+		lost := record.LoserID != nil && *record.LoserID == p.UserID
+		if lost {
+			fill(img, row, loserRow)
+		}
+
+		back, err := cardBack(game.BackColors[i%len(game.BackColors)], seatCardW)
+		if err != nil {
+			return nil, err
+		}
+		paste(img, back, margin, top+(rowH-back.Bounds().Dy())/2)
+		textY := top + (rowH-textScale*glyphHeight)/2
+		drawText(img, nameX, textY, fitText(p.Name, nameW, textScale), textScale, white)
+
+		for j := range donkeyWord {
+			box := image.Rect(0, 0, letterBoxW, letterBoxW+4).Add(image.Pt(lettersX+j*(letterBoxW+letterGap), top+(rowH-letterBoxW-4)/2))
+			if j < len(p.DonkeyLetters) {
+				fill(img, box, red)
+				drawText(img, box.Min.X+(letterBoxW-textWidth("D", textScale))/2, box.Min.Y+(box.Dy()-textScale*glyphHeight)/2, donkeyWord[j:j+1], textScale, gold)
+			} else {
+				outline(img, box, feltLight)
+			}
+		}
+
+		for j, r := range record.Rounds {
+			cell := image.Rect(0, 0, roundCellW-4, letterBoxW+4).Add(image.Pt(timelineX+j*roundCellW+2, top+(rowH-letterBoxW-4)/2))
+			if r.LoserID == nil || *r.LoserID != p.UserID {
+				fill(img, cell, feltLight)
+				continue
+			}
+			fill(img, cell, red)
+			if r.Letter != "" {
+				drawText(img, cell.Min.X+(cell.Dx()-textWidth(r.Letter, textScale))/2, cell.Min.Y+(cell.Dy()-textScale*glyphHeight)/2, r.Letter, textScale, gold)
+			}
+		}
+	}
+	return img, nil
+}
+
+// playerName returns the name of the player with the given ID
+func playerName(record *archive.Record, userID *string) string {
+	if userID == nil {
+		return ""
+	}
+	for _, p := range record.Players {
+		if p.UserID == *userID {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+func fill(dst draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(dst, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func outline(dst draw.Image, r image.Rectangle, c color.Color) {
+	fill(dst, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+2), c)
+	fill(dst, image.Rect(r.Min.X, r.Max.Y-2, r.Max.X, r.Max.Y), c)
+	fill(dst, image.Rect(r.Min.X, r.Min.Y, r.Min.X+2, r.Max.Y), c)
+	fill(dst, image.Rect(r.Max.X-2, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+// paste draws src over dst with its top-left corner at x, y
+func paste(dst draw.Image, src image.Image, x, y int) {
+	draw.Draw(dst, src.Bounds().Add(image.Pt(x, y)), src, src.Bounds().Min, draw.Over)
+}
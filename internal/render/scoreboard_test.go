@@ -0,0 +1,70 @@
+package render
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+func finishedGame(rounds int) *archive.Record {
+	ann, bob := "ann", "bob"
+	completed := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	record := &archive.Record{
+		GameID: "g1", Status: "completed", LoserID: &ann, CompletedAt: &completed,
+		Players: []model.ArchivedPlayer{
+			{UserID: "ann", Name: "Ann", DonkeyLetters: "DONKEY"},
+			{UserID: "bob", Name: "Bob with a very long name indeed", JoinOrder: 1, DonkeyLetters: "D"},
+		},
+	}
+	for i := 0; i < rounds; i++ {
+		loser, letter := &ann, string(donkeyWord[min(i, len(donkeyWord)-1)])
+		if i == 0 {
+			loser, letter = &bob, "D"
+		}
+		record.Rounds = append(record.Rounds, model.ArchivedRound{RoundNumber: i + 1, LoserID: loser, Letter: letter})
+	}
+	return record
+}
+
+func TestScoreboardHighlightsLoser(t *testing.T) {
+	img, err := Scoreboard(finishedGame(3))
+	require.NoError(t, err)
+	assert.Equal(t, minBoardW, img.Bounds().Dx())
+	assert.Equal(t, margin+headerH+columnsH+2*rowH+margin, img.Bounds().Dy())
+
+	// The loser's row is red at its right end, the other one felt
+	x := img.Bounds().Dx() - margin
+	annY := margin + headerH + columnsH + rowH/2
+	assert.Equal(t, loserRow, img.RGBAAt(x, annY))
+	assert.Equal(t, felt, img.RGBAAt(x, annY+rowH))
+}
+
+func TestScoreboardWidensForLongGames(t *testing.T) {
+	img, err := Scoreboard(finishedGame(30))
+	require.NoError(t, err)
+	assert.Greater(t, img.Bounds().Dx(), minBoardW)
+}
+
+func TestFitText(t *testing.T) {
+	assert.Equal(t, "Ann", fitText("Ann", 100, 2))
+	short := fitText("Bob with a very long name", 100, 2)
+	assert.LessOrEqual(t, textWidth(short, 2), 100)
+	assert.Equal(t, '.', []rune(short)[len([]rune(short))-1])
+}
+
+func TestShrinkAverages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for i := 0; i < len(src.Pix); i += 8 {
+		copy(src.Pix[i:], []uint8{0xff, 0xff, 0xff, 0xff})
+	}
+	dst := shrink(src, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	assert.Equal(t, uint8(0x7f), dst.Pix[0])
+	assert.Equal(t, uint8(0x7f), dst.Pix[3])
+}
@@ -0,0 +1,209 @@
+// Package retention periodically removes stale game data, as promised by the
+// spec ("game data automatically deleted after 24 hours of inactivity").
+// Completed games are compacted into an archive record; abandoned and
+// never-started games are removed entirely.
+package retention
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Policy sets how long a game of each status may stay inactive before it is
+// cleaned up. A zero duration disables cleanup for that status.
+type Policy struct {
+	Abandoned time.Duration
+	Completed time.Duration
+	Waiting   time.Duration
+	Interval  time.Duration // How often the worker runs
+}
+
+// DefaultPolicy removes games after 24 hours of inactivity, checking hourly
+var DefaultPolicy = Policy{
+	Abandoned: 24 * time.Hour,
+	Completed: 24 * time.Hour,
+	Waiting:   24 * time.Hour,
+	Interval:  time.Hour,
+}
+
+// PolicyFromEnv reads RETENTION_ABANDONED, RETENTION_COMPLETED,
+// RETENTION_WAITING and RETENTION_INTERVAL as Go durations (e.g. "72h"),
+// falling back to DefaultPolicy for unset values. "0" disables a status.
+func PolicyFromEnv() (Policy, error) {
+	p := DefaultPolicy
+	fields := []struct {
+		env string
+		dst *time.Duration
+	}{
+		{"RETENTION_ABANDONED", &p.Abandoned},
+		{"RETENTION_COMPLETED", &p.Completed},
+		{"RETENTION_WAITING", &p.Waiting},
+		{"RETENTION_INTERVAL", &p.Interval},
+	}
+	for _, f := range fields {
+		raw := os.Getenv(f.env)
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return p, fmt.Errorf("invalid %s %q", f.env, raw)
+		}
+		*f.dst = d
+	}
+	return p, nil
+}
+
+// Report describes what a retention run removed
+type Report struct {
+	RanAt        time.Time        `json:"ranAt"`
+	Archived     int              `json:"archived"`     // Completed games compacted into archives
+	GamesDeleted map[string]int   `json:"gamesDeleted"` // Status -> games removed entirely
+	RowsDeleted  map[string]int64 `json:"rowsDeleted"`  // Table -> rows removed
+	Errors       []string         `json:"errors,omitempty"`
+}
+
+func newReport(now time.Time) *Report {
+	return &Report{RanAt: now, GamesDeleted: make(map[string]int), RowsDeleted: make(map[string]int64)}
+}
+
+func (r *Report) add(rows map[string]int64) {
+	for table, n := range rows {
+		r.RowsDeleted[table] += n
+	}
+}
+
+var (
+	mu        sync.Mutex
+	last      *Report
+	startOnce sync.Once
+)
+
+// LastReport returns the report of the most recent run, if any
+func LastReport() *Report {
+	mu.Lock()
+	defer mu.Unlock()
+	return last
+}
+
+// Start runs the retention worker over repo in the background at the policy
+// interval. It is safe to call more than once; only the first call starts a
+// worker.
+func Start(repo store.Store, p Policy) {
+	if p.Interval <= 0 {
+		return
+	}
+	startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(p.Interval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				Run(repo, p, now)
+			}
+		}()
+	})
+}
+
+// Run applies the policy once, as of now, and returns what it removed
+func Run(repo store.Store, p Policy, now time.Time) *Report {
+	report := newReport(now)
+
+	if p.Completed > 0 {
+		for _, g := range staleGames(repo, report, "completed", now.Add(-p.Completed)) {
+			var rows map[string]int64
+			if err := repo.Transaction(func(tx store.Store) (err error) {
+				rows, err = archive.Compact(tx, g.ID)
+				return err
+			}); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("archive %s: %v", g.ID, err))
+				continue
+			}
+			// Rows only count once their deletion has committed
+			report.add(rows)
+			report.Archived++
+		}
+	}
+
+	for status, age := range map[string]time.Duration{"abandoned": p.Abandoned, "waiting": p.Waiting} {
+		if age <= 0 {
+			continue
+		}
+		for _, g := range staleGames(repo, report, status, now.Add(-age)) {
+			var rows map[string]int64
+			if err := repo.Transaction(func(tx store.Store) (err error) {
+				rows, err = deleteGame(tx, g.ID)
+				return err
+			}); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("delete %s: %v", g.ID, err))
+				continue
+			}
+			report.add(rows)
+			report.GamesDeleted[status]++
+		}
+	}
+
+	if report.Archived > 0 || len(report.GamesDeleted) > 0 || len(report.Errors) > 0 {
+		log.Printf("retention: archived %d completed games, deleted %v, rows %v, errors %d",
+			report.Archived, report.GamesDeleted, report.RowsDeleted, len(report.Errors))
+	}
+
+	mu.Lock()
+	last = report
+	mu.Unlock()
+	return report
+}
+
+// staleGames returns games of a status whose last activity is before cutoff.
+// Activity is the newest session log, falling back to the game's own
+// timestamps. Completed games that were already archived are skipped.
+func staleGames(repo store.Store, report *Report, status string, cutoff time.Time) []model.Game {
+	games, err := repo.Games().ListByStatus(status)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("list %s games: %v", status, err))
+		return nil
+	}
+
+	var stale []model.Game
+	for _, g := range games {
+		if status == "completed" {
+			if _, err := repo.Archives().Get(g.ID); err == nil {
+				continue
+			}
+		}
+		lastActivity := g.CreatedAt
+		for _, t := range []*time.Time{g.StartedAt, g.CompletedAt} {
+			if t != nil && t.After(lastActivity) {
+				lastActivity = *t
+			}
+		}
+		if latest, err := repo.Logs().Recent(g.ID, 1); err == nil && len(latest) > 0 &&
+			latest[0].CreatedAt.After(lastActivity) {
+			lastActivity = latest[0].CreatedAt
+		}
+		if lastActivity.Before(cutoff) {
+			stale = append(stale, g)
+		}
+	}
+	return stale
+}
+
+// deleteGame removes a game and everything that belongs to it, including bot
+// users that played only in this game.
+func deleteGame(repo store.Store, gameID string) (map[string]int64, error) {
+	rows, err := repo.Games().PurgeDetails(gameID)
+	if err != nil {
+		return rows, err
+	}
+	deleted, err := repo.Games().Delete(gameID)
+	for table, n := range deleted {
+		rows[table] += n
+	}
+	return rows, err
+}
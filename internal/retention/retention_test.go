@@ -0,0 +1,97 @@
+package retention
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func TestRunArchivesCompletedAndDeletesStaleGames(t *testing.T) {
+	conn, err := gorm.Open(sqlite.Open("file:retention?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, migrations.Up(conn))
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	human := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: old}
+	other := model.User{ID: model.NewID(), Name: "Ben", CreatedAt: old}
+	bot := model.User{ID: model.NewID(), Name: "Bot", IsBot: true, BotDifficulty: "easy", CreatedAt: old}
+	require.NoError(t, conn.Create([]model.User{human, other, bot}).Error)
+
+	// Completed two days ago, with one round of detail
+	completed := model.Game{ID: model.NewID(), RequesterID: human.ID, Status: "completed", CreatedAt: old, CompletedAt: &old, LoserID: &other.ID}
+	require.NoError(t, conn.Create(&completed).Error)
+	require.NoError(t, conn.Create(&model.GameSettings{GameID: completed.ID, Rated: true}).Error)
+	require.NoError(t, conn.Create([]model.GamePlayer{
+		{GameID: completed.ID, UserID: human.ID, JoinOrder: 0, JoinedAt: old, LastSeenAt: old},
+		{GameID: completed.ID, UserID: other.ID, JoinOrder: 1, DonkeyLetters: "D", JoinedAt: old, LastSeenAt: old},
+	}).Error)
+	round := model.Round{ID: model.NewID(), GameID: completed.ID, RoundNumber: 1, Status: "completed", StartedAt: old, CompletedAt: &old, LoserID: &other.ID}
+	require.NoError(t, conn.Create(&round).Error)
+	require.NoError(t, conn.Create(&model.RoundPlayer{RoundID: round.ID, UserID: human.ID}).Error)
+	turn := model.Turn{ID: model.NewID(), RoundID: round.ID, TurnNumber: 1, StartPlayerID: human.ID, Status: "completed", StartedAt: old}
+	require.NoError(t, conn.Create(&turn).Error)
+	card := model.Card{ID: model.NewID(), RoundID: round.ID, Suit: "spades", Rank: "A", Value: 14, Location: "discard"}
+	require.NoError(t, conn.Create(&card).Error)
+	require.NoError(t, conn.Create(&model.PlayedCard{ID: model.NewID(), TurnID: turn.ID, CardID: card.ID, PlayerID: human.ID, PlayOrder: 1, PlayedAt: old}).Error)
+	require.NoError(t, conn.Create(&model.GameSessionLog{ID: model.NewID(), GameID: completed.ID, Type: "game_event", Message: "done", CreatedAt: old}).Error)
+
+	// Abandoned two days ago with a bot that played nowhere else
+	abandoned := model.Game{ID: model.NewID(), RequesterID: human.ID, Status: "abandoned", CreatedAt: old}
+	require.NoError(t, conn.Create(&abandoned).Error)
+	require.NoError(t, conn.Create([]model.GamePlayer{
+		{GameID: abandoned.ID, UserID: human.ID, JoinedAt: old, LastSeenAt: old},
+		{GameID: abandoned.ID, UserID: bot.ID, JoinOrder: 1, JoinedAt: old, LastSeenAt: old},
+	}).Error)
+
+	// Waiting but created long ago, with recent chat keeping it alive
+	waiting := model.Game{ID: model.NewID(), RequesterID: human.ID, Status: "waiting", CreatedAt: old}
+	require.NoError(t, conn.Create(&waiting).Error)
+	require.NoError(t, conn.Create(&model.GameSessionLog{ID: model.NewID(), GameID: waiting.ID, Type: "chat", Message: "hi", CreatedAt: now.Add(-time.Hour)}).Error)
+
+	report := Run(store.NewGorm(conn), DefaultPolicy, now)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 1, report.Archived)
+	assert.Equal(t, 1, report.GamesDeleted["abandoned"])
+	assert.Zero(t, report.GamesDeleted["waiting"])
+	assert.Equal(t, int64(1), report.RowsDeleted["played_cards"])
+	assert.Equal(t, int64(1), report.RowsDeleted["rounds"])
+	assert.Equal(t, int64(1), report.RowsDeleted["users"])
+
+	var archived model.GameArchive
+	require.NoError(t, conn.First(&archived, "game_id = ?", completed.ID).Error)
+	var rounds []model.ArchivedRound
+	require.NoError(t, json.Unmarshal([]byte(archived.Rounds), &rounds))
+	if assert.Len(t, rounds, 1) {
+		assert.Equal(t, "D", rounds[0].Letter)
+	}
+
+	var count int64
+	conn.Model(&model.Round{}).Where("game_id = ?", completed.ID).Count(&count)
+	assert.Zero(t, count)
+	var settings model.GameSettings
+	require.NoError(t, conn.First(&settings, "game_id = ?", completed.ID).Error)
+	assert.True(t, settings.Rated, "an archived game stays rated")
+	conn.Model(&model.Game{}).Where("id IN ?", []string{completed.ID, waiting.ID}).Count(&count)
+	assert.Equal(t, int64(2), count, "completed and waiting game rows are kept")
+	conn.Model(&model.Game{}).Where("id = ?", abandoned.ID).Count(&count)
+	assert.Zero(t, count)
+	conn.Model(&model.User{}).Where("id = ?", bot.ID).Count(&count)
+	assert.Zero(t, count)
+	conn.Model(&model.User{}).Where("id = ?", human.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// A second run finds nothing left to do
+	again := Run(store.NewGorm(conn), DefaultPolicy, now)
+	assert.Zero(t, again.Archived)
+	assert.Empty(t, again.GamesDeleted)
+}
@@ -14,17 +14,37 @@ import (
 	"github.com/kairodrad/donkey/internal/api"
 	"github.com/kairodrad/donkey/internal/db"
 	"github.com/kairodrad/donkey/internal/game"
-	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/retention"
+	"github.com/kairodrad/donkey/internal/store"
 )
 
-// New creates a new HTTP server with routes configured.
+// New creates a new HTTP server with routes configured, backed by the
+// database selected through the environment.
 func New() *gin.Engine {
+	db.Init()
+	repo := store.NewGorm(db.DB)
+
+	// Clean up stale game data in the background
+	if policy, err := retention.PolicyFromEnv(); err != nil {
+		log.Printf("retention disabled: %v", err)
+	} else {
+		retention.Start(repo, policy)
+	}
+
+	return NewWithStore(repo)
+}
+
+// NewWithStore creates a new HTTP server whose games live in repo. Tests use
+// it with store.NewMemory() to get a server of their own.
+func NewWithStore(repo store.Store) *gin.Engine {
 	game.VerifyAssets()
-	db.Init(&model.User{}, &model.Game{}, &model.GamePlayer{}, &model.Round{}, &model.RoundPlayer{}, &model.Turn{}, &model.Card{}, &model.PlayedCard{}, &model.BotMemory{}, &model.GameSessionLog{}, &model.GameSettings{})
+	h := api.NewHandlers(repo)
 
 	// Set up publishers for game events
 	game.SetStatePublisher(api.PublishState)
 	game.SetLogPublisher(api.PublishLog)
+	game.SetPrivatePublisher(api.PublishPrivate)
+	h.StartPresenceMonitor()
 
 	r := gin.Default()
 	r.Use(logRequests())
@@ -54,27 +74,56 @@ func New() *gin.Engine {
 	apiGroup := r.Group("/api")
 	{
 		// User management
-		apiGroup.POST("/register", api.RegisterHandler)
-		apiGroup.GET("/user/:id", api.GetUserHandler)
-		apiGroup.GET("/users", api.ListUsersHandler)
+		apiGroup.POST("/register", h.RegisterHandler)
+		apiGroup.GET("/user/:id", h.GetUserHandler)
+		apiGroup.GET("/user/:id/stats", h.UserStatsHandler)
+		apiGroup.GET("/user/:id/rating", h.UserRatingHandler)
+		apiGroup.GET("/user/:id/vs/:otherId", h.HeadToHeadHandler)
+		apiGroup.GET("/user/:id/achievements", h.UserAchievementsHandler)
+		apiGroup.GET("/leaderboard", h.LeaderboardHandler)
+		apiGroup.GET("/users", h.ListUsersHandler)
 		
 		// Game management
-		apiGroup.POST("/game/create", api.CreateGameHandler)
-		apiGroup.POST("/game/join", api.JoinGameHandler)
-		apiGroup.POST("/game/start", api.StartGameHandler)
-		apiGroup.POST("/game/abandon", api.AbandonGameHandler)
-		apiGroup.POST("/game/add-bot", api.AddBotHandler)
-		apiGroup.POST("/game/play-card", api.PlayCardHandler)
-		apiGroup.GET("/game/:gameId/state/:userId", api.GameStateHandler)
-		apiGroup.GET("/games", api.GetGameListHandler)
+		apiGroup.POST("/game/create", h.CreateGameHandler)
+		apiGroup.POST("/game/join", h.JoinGameHandler)
+		apiGroup.POST("/game/start", h.StartGameHandler)
+		apiGroup.POST("/game/abandon", h.AbandonGameHandler)
+		apiGroup.POST("/game/add-bot", h.AddBotHandler)
+		apiGroup.POST("/game/play-card", h.PlayCardHandler)
+		apiGroup.GET("/game/:gameId/state/:userId", h.GameStateHandler)
+		apiGroup.GET("/games", h.GetGameListHandler)
 		
 		// Admin endpoints
-		apiGroup.GET("/admin/game/:gameId/state", api.AdminStateHandler)
-		
+		apiGroup.GET("/admin/game/:gameId/state", h.AdminStateHandler)
+		apiGroup.GET("/admin/retention", api.RetentionReportHandler)
 		// Chat and streaming
-		apiGroup.POST("/game/chat", api.ChatHandler)
-		apiGroup.GET("/game/:gameId/logs", api.LogsHandler)
-		apiGroup.GET("/game/:gameId/stream/:userId", api.StreamHandler)
+		apiGroup.POST("/game/chat", h.ChatHandler)
+		apiGroup.GET("/game/:gameId/logs", h.PlayersOnly(h.LogsHandler))
+		apiGroup.GET("/game/:gameId/history", h.PlayersOnly(h.GameHistoryHandler))
+		apiGroup.GET("/game/:gameId/replay", h.PlayersOnly(h.ReplayHandler))
+		apiGroup.GET("/game/:gameId/replay/step", h.PlayersOnly(h.ReplayStepHandler))
+		apiGroup.GET("/game/:gameId/replay/animation.gif", h.PlayersOnly(h.RoundAnimationHandler))
+		apiGroup.GET("/game/:gameId/report", h.PlayersOnly(h.GameReportHandler))
+		apiGroup.GET("/game/:gameId/notation", h.PlayersOnly(h.GameNotationHandler))
+		apiGroup.POST("/notation/import", h.ImportNotationHandler)
+		apiGroup.POST("/game/:gameId/share", h.ShareGameHandler)
+		apiGroup.DELETE("/game/:gameId/share", h.RevokeShareHandler)
+		
+		// Read-only views of shared games
+		apiGroup.GET("/share/:token/state", h.SharedStateHandler)
+		apiGroup.GET("/share/:token/replay", h.Shared(h.ReplayHandler))
+		apiGroup.GET("/share/:token/replay/step", h.Shared(h.ReplayStepHandler))
+		apiGroup.GET("/share/:token/replay/animation.gif", h.Shared(h.RoundAnimationHandler))
+		apiGroup.GET("/share/:token/report", h.Shared(h.GameReportHandler))
+		apiGroup.GET("/share/:token/scoreboard.png", h.SharedScoreboardHandler)
+		apiGroup.GET("/game/:gameId/stream/:userId", h.StreamHandler)
+		apiGroup.GET("/game/:gameId/poll", h.PollHandler)
+		
+		// Webhooks
+		apiGroup.POST("/webhooks", h.RegisterWebhookHandler)
+		apiGroup.GET("/webhooks", h.ListWebhooksHandler)
+		apiGroup.DELETE("/webhooks/:id", h.DeleteWebhookHandler)
+		apiGroup.GET("/webhooks/:id/deliveries", h.WebhookDeliveriesHandler)
 		
 		// Utilities
 		apiGroup.GET("/version", api.VersionHandler)
@@ -0,0 +1,122 @@
+// Package stats keeps each user's playing statistics. Every completed game
+// leaves one model.PlayerGameStats row per player, tallied from its history as
+// it ends; a user's statistics add up the rows of the games asked for.
+package stats
+
+import (
+	"fmt"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Tally counts what each player of a completed game did in it. It works
+// from the game's history, so an archived game tallies the same as before
+// its rounds and turns were compacted away.
+func Tally(repo store.Store, gameID string) ([]model.PlayerGameStats, error) {
+	// Imported games have no game row and stay out of statistics
+	if _, err := repo.Games().Get(gameID); err != nil {
+		return nil, fmt.Errorf("failed to load game: %w", err)
+	}
+	record, err := archive.Load(repo, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game history: %w", err)
+	}
+	if record.Status != "completed" || record.CompletedAt == nil {
+		return nil, fmt.Errorf("game %s is not completed", gameID)
+	}
+	tallies := make(map[string]*model.PlayerGameStats, len(record.Players))
+	rows := make([]model.PlayerGameStats, len(record.Players))
+	for i, p := range record.Players {
+		rows[i] = model.PlayerGameStats{
+			GameID:      gameID,
+			UserID:      p.UserID,
+			CompletedAt: *record.CompletedAt,
+			Lost:        record.LoserID != nil && *record.LoserID == p.UserID,
+		}
+		tallies[p.UserID] = &rows[i]
+	}
+
+	for _, round := range record.Rounds {
+		if round.CompletedAt == nil {
+			continue
+		}
+		for i, userID := range round.FinishOrder {
+			if t := tallies[userID]; t != nil {
+				t.RoundsPlayed++
+				t.FinishPositionSum += i + 1
+			}
+		}
+		if round.LoserID != nil {
+			if t := tallies[*round.LoserID]; t != nil {
+				t.RoundsLost++
+			}
+		}
+		for _, trick := range round.Tricks {
+			if trick.Status != "cut" {
+				continue
+			}
+			if t := tallies[trick.CutPlayerID]; t != nil {
+				t.Cuts++
+			}
+			if t := tallies[trick.WinnerID]; t != nil {
+				t.TricksCollected++
+				t.CardsPickedUp += len(trick.Plays)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// Record tallies a completed game and stores the result, replacing any
+// earlier tally of it
+func Record(repo store.Store, gameID string) error {
+	rows, err := Tally(repo, gameID)
+	if err != nil {
+		return err
+	}
+	if err := repo.Stats().Record(gameID, rows); err != nil {
+		return fmt.Errorf("failed to save stats: %w", err)
+	}
+	return nil
+}
+
+// Summary is a user's statistics over a set of games
+type Summary struct {
+	UserID                string  `json:"userId"`
+	GamesPlayed           int     `json:"gamesPlayed"`
+	GamesLost             int     `json:"gamesLost"` // times the user was the DONKEY
+	RoundsPlayed          int     `json:"roundsPlayed"`
+	RoundsLost            int     `json:"roundsLost"`
+	AverageFinishPosition float64 `json:"averageFinishPosition"` // 1 is first out of cards
+	Cuts                  int     `json:"cuts"`
+	TricksCollected       int     `json:"tricksCollected"` // cut tricks picked up
+	CardsPickedUp         int     `json:"cardsPickedUp"`
+	AverageCardsPickedUp  float64 `json:"averageCardsPickedUp"` // per trick collected
+}
+
+// Summarize adds up a user's tallies
+func Summarize(userID string, rows []model.PlayerGameStats) Summary {
+	s := Summary{UserID: userID}
+	positions := 0
+	for _, row := range rows {
+		s.GamesPlayed++
+		if row.Lost {
+			s.GamesLost++
+		}
+		s.RoundsPlayed += row.RoundsPlayed
+		s.RoundsLost += row.RoundsLost
+		positions += row.FinishPositionSum
+		s.Cuts += row.Cuts
+		s.TricksCollected += row.TricksCollected
+		s.CardsPickedUp += row.CardsPickedUp
+	}
+	if s.RoundsPlayed > 0 {
+		s.AverageFinishPosition = float64(positions) / float64(s.RoundsPlayed)
+	}
+	if s.TricksCollected > 0 {
+		s.AverageCardsPickedUp = float64(s.CardsPickedUp) / float64(s.TricksCollected)
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/stats"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// playedGame stores a completed game of one round. finishOrder lists the
+// players in the order they ran out of cards, the round's loser last; cuts
+// are (cutter, winner) pairs of three-card tricks.
+func playedGame(t *testing.T, repo store.Store, completed time.Time, finishOrder []string, cuts ...[2]string) string {
+	t.Helper()
+	loser := finishOrder[len(finishOrder)-1]
+	g := model.Game{ID: model.NewID(), Status: "completed", CompletedAt: &completed, LoserID: &loser, CreatedAt: completed}
+	require.NoError(t, repo.Games().Create(&g))
+	r := model.Round{ID: model.NewID(), GameID: g.ID, RoundNumber: 1, Status: "completed", LoserID: &loser, CompletedAt: &completed}
+	require.NoError(t, repo.Rounds().Create(&r))
+	for i, userID := range finishOrder {
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: userID, JoinOrder: i}))
+		rp := model.RoundPlayer{RoundID: r.ID, UserID: userID, Position: len(finishOrder) - i}
+		if userID != loser {
+			at := completed.Add(time.Duration(i-len(finishOrder)) * time.Minute)
+			rp.IsFinished, rp.FinishedAt = true, &at
+		}
+		require.NoError(t, repo.Rounds().AddPlayer(&rp))
+	}
+	for i, cut := range cuts {
+		turn := model.Turn{ID: model.NewID(), RoundID: r.ID, TurnNumber: i + 1, Status: "cut", CutPlayerID: &cut[0], WinnerID: &cut[1]}
+		require.NoError(t, repo.Turns().Create(&turn))
+		for order := 1; order <= 3; order++ {
+			require.NoError(t, repo.Turns().AddPlayedCard(&model.PlayedCard{ID: model.NewID(), TurnID: turn.ID, PlayOrder: order}))
+		}
+	}
+	require.NoError(t, stats.Record(repo, g.ID))
+	return g.ID
+}
+
+func TestStatsAddUpCompletedGames(t *testing.T) {
+	repo := store.NewMemory()
+	first := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	playedGame(t, repo, first, []string{"ann", "cat", "bob"}, [2]string{"bob", "ann"})
+	playedGame(t, repo, second, []string{"bob", "ann"})
+
+	rows, err := repo.Stats().List("ann", store.StatsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, stats.Summary{
+		UserID: "ann", GamesPlayed: 2, GamesLost: 1, RoundsPlayed: 2, RoundsLost: 1,
+		AverageFinishPosition: 1.5, TricksCollected: 1, CardsPickedUp: 3, AverageCardsPickedUp: 3,
+	}, stats.Summarize("ann", rows))
+
+	rows, err = repo.Stats().List("bob", store.StatsFilter{})
+	require.NoError(t, err)
+	bob := stats.Summarize("bob", rows)
+	assert.Equal(t, 1, bob.Cuts)
+	assert.Equal(t, 2.0, bob.AverageFinishPosition)
+
+	rows, err = repo.Stats().List("ann", store.StatsFilter{Opponents: []string{"cat"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Summarize("ann", rows).GamesPlayed)
+	assert.Equal(t, 0, stats.Summarize("ann", rows).GamesLost)
+
+	rows, err = repo.Stats().List("ann", store.StatsFilter{From: second})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Summarize("ann", rows).GamesLost)
+	rows, err = repo.Stats().List("ann", store.StatsFilter{To: second})
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Summarize("ann", rows).GamesLost)
+
+	// Tallying a game again replaces its rows
+	require.NoError(t, stats.Record(repo, rows[0].GameID))
+	rows, err = repo.Stats().List("ann", store.StatsFilter{})
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+}
+
+func TestArchivedGameTalliesTheSame(t *testing.T) {
+	repo := store.NewMemory()
+	gameID := playedGame(t, repo, time.Now(), []string{"ann", "cat", "bob"}, [2]string{"bob", "ann"})
+	before, err := repo.Stats().ForGame(gameID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Transaction(func(tx store.Store) error {
+		_, err := archive.Compact(tx, gameID)
+		return err
+	}))
+	require.NoError(t, stats.Record(repo, gameID))
+	after, err := repo.Stats().ForGame(gameID)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	assert.Equal(t, 1, after[1].Cuts, "bob's cut is kept in the archive")
+}
@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/archive"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Rival is one side of a head-to-head record
+type Rival struct {
+	UserID     string `json:"userId"`
+	Name       string `json:"name"`
+	Donkeys    int    `json:"donkeys"`    // shared games they ended as the DONKEY
+	RoundsLost int    `json:"roundsLost"` // rounds of shared games they lost
+	// Cuts counts the tricks they cut that the other one had to pick up
+	Cuts int `json:"cuts"`
+}
+
+// SharedGame is a completed game two players both played in
+type SharedGame struct {
+	GameID      string     `json:"gameId"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	LoserID     *string    `json:"loserId,omitempty"`
+}
+
+// HeadToHead is how two players fared in the games they played together
+type HeadToHead struct {
+	Players     [2]Rival     `json:"players"`
+	SharedGames int          `json:"sharedGames"`
+	Games       []SharedGame `json:"games"` // newest first
+}
+
+// Versus compares two players over every completed game they both played,
+// archived or not. Cuts are only known for games whose tricks were recorded.
+func Versus(repo store.Store, userID, otherID string) (*HeadToHead, error) {
+	h := &HeadToHead{Games: []SharedGame{}}
+	sides := map[string]*Rival{userID: &h.Players[0], otherID: &h.Players[1]}
+	for id, side := range sides {
+		user, err := repo.Users().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		side.UserID, side.Name = user.ID, user.Name
+	}
+
+	games, err := repo.Games().ListForUser(userID, "completed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+	for _, g := range games {
+		if _, err := repo.Games().Player(g.ID, otherID); err != nil {
+			continue
+		}
+		record, err := archive.Load(repo, g.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load game %s: %w", g.ID, err)
+		}
+		h.SharedGames++
+		h.Games = append(h.Games, SharedGame{GameID: g.ID, CompletedAt: record.CompletedAt, LoserID: record.LoserID})
+		if record.LoserID != nil && sides[*record.LoserID] != nil {
+			sides[*record.LoserID].Donkeys++
+		}
+		for _, round := range record.Rounds {
+			if round.LoserID != nil && sides[*round.LoserID] != nil {
+				sides[*round.LoserID].RoundsLost++
+			}
+			for _, trick := range round.Tricks {
+				// Only a cut the other side picked up counts between the two
+				if trick.Status == "cut" && sides[trick.CutPlayerID] != nil && sides[trick.WinnerID] != nil {
+					sides[trick.CutPlayerID].Cuts++
+				}
+			}
+		}
+	}
+	return h, nil
+}
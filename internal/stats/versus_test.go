@@ -0,0 +1,60 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/stats"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// archivedGame stores a completed, archived game lost by loser. Each round
+// is lost by its first entry and may list cuts as "cutter>winner".
+func archivedGame(t *testing.T, repo store.Store, completed time.Time, players []string, loser string, rounds ...[]string) {
+	t.Helper()
+	g := model.Game{ID: model.NewID(), Status: "completed", CreatedAt: completed, CompletedAt: &completed, LoserID: &loser}
+	require.NoError(t, repo.Games().Create(&g))
+	for i, userID := range players {
+		require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: g.ID, UserID: userID, JoinOrder: i}))
+	}
+	var archived []model.ArchivedRound
+	for i, r := range rounds {
+		round := model.ArchivedRound{RoundNumber: i + 1, LoserID: &r[0], Seats: players}
+		for _, cut := range r[1:] {
+			cutter, winner := cut[:3], cut[4:]
+			round.Tricks = append(round.Tricks, model.ArchivedTrick{Status: "cut", CutPlayerID: cutter, WinnerID: winner})
+		}
+		archived = append(archived, round)
+	}
+	roundsJSON, _ := json.Marshal(archived)
+	require.NoError(t, repo.Archives().Save(&model.GameArchive{
+		GameID: g.ID, LoserID: &loser, Players: "[]", Rounds: string(roundsJSON), CompletedAt: &completed, ArchivedAt: completed,
+	}))
+}
+
+func TestVersusCountsSharedGamesOnly(t *testing.T) {
+	repo := store.NewMemory()
+	for _, id := range []string{"ann", "bob", "cat"} {
+		require.NoError(t, repo.Users().Create(&model.User{ID: id, Name: id}))
+	}
+	day := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	archivedGame(t, repo, day, []string{"ann", "bob", "cat"}, "ann",
+		[]string{"ann", "bob>ann", "cat>ann"}, []string{"bob", "ann>bob", "bob>cat"})
+	archivedGame(t, repo, day.Add(time.Hour), []string{"ann", "bob"}, "bob", []string{"bob", "bob>ann"})
+	archivedGame(t, repo, day.Add(2*time.Hour), []string{"ann", "cat"}, "cat", []string{"ann", "cat>ann"})
+
+	h, err := stats.Versus(repo, "ann", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 2, h.SharedGames)
+	assert.Equal(t, "bob", *h.Games[0].LoserID, "newest first")
+	assert.Equal(t, stats.Rival{UserID: "ann", Name: "ann", Donkeys: 1, RoundsLost: 1, Cuts: 1}, h.Players[0])
+	assert.Equal(t, stats.Rival{UserID: "bob", Name: "bob", Donkeys: 1, RoundsLost: 2, Cuts: 2}, h.Players[1])
+
+	_, err = stats.Versus(repo, "ann", "nobody")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
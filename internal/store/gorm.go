@@ -0,0 +1,658 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// gormStore implements Store on top of a GORM connection
+type gormStore struct {
+	db    *gorm.DB
+	locks *gameLocks
+}
+
+// NewGorm returns a Store backed by conn. The schema must already be
+// migrated. On Postgres game locks are advisory locks, which hold across
+// every server sharing the database; elsewhere they only hold within this
+// process.
+func NewGorm(conn *gorm.DB) Store {
+	return gormStore{db: conn, locks: newGameLocks()}
+}
+
+func (s gormStore) Games() Games               { return gormGames{s.db} }
+func (s gormStore) Rounds() Rounds             { return gormRounds{s.db} }
+func (s gormStore) Turns() Turns               { return gormTurns{s.db} }
+func (s gormStore) Cards() Cards               { return gormCards{s.db} }
+func (s gormStore) Logs() Logs                 { return gormLogs{s.db} }
+func (s gormStore) Users() Users               { return gormUsers{s.db} }
+func (s gormStore) Events() Events             { return gormEvents{s.db} }
+func (s gormStore) Archives() Archives         { return gormArchives{s.db} }
+func (s gormStore) Stats() Stats               { return gormStats{s.db} }
+func (s gormStore) Ratings() Ratings           { return gormRatings{s.db} }
+func (s gormStore) Achievements() Achievements { return gormAchievements{s.db} }
+func (s gormStore) Shares() Shares             { return gormShares{s.db} }
+func (s gormStore) Webhooks() Webhooks         { return gormWebhooks{s.db} }
+
+func (s gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(gormStore{tx, s.locks})
+	})
+}
+
+func (s gormStore) Lock(gameID string) (func(), error) {
+	// Waiting on the process's own mutex first keeps local contenders from
+	// each tying up a connection while they wait on the database
+	unlock := s.locks.lock(gameID)
+	if s.db.Dialector.Name() != "postgres" {
+		return unlock, nil
+	}
+	release, err := advisoryLock(s.db, gameID)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return func() {
+		release()
+		unlock()
+	}, nil
+}
+
+// found translates GORM's missing-row error into ErrNotFound
+func found(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// saveVersioned updates every column of a row that is still at *version and
+// advances the version, or returns ErrConflict if the row has moved on
+func saveVersioned(db *gorm.DB, value interface{}, version *int) error {
+	read := *version
+	*version = read + 1
+	res := db.Model(value).Where("version = ?", read).Select("*").Omit(clause.Associations).Updates(value)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = ErrConflict
+	}
+	if res.Error != nil {
+		*version = read
+	}
+	return res.Error
+}
+
+type gormUsers struct{ db *gorm.DB }
+
+func (r gormUsers) Create(user *model.User) error {
+	return r.db.Omit(clause.Associations).Create(user).Error
+}
+
+func (r gormUsers) Get(id string) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+		return nil, found(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) GetWithGames(id string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Preload("Games").First(&user, "id = ?", id).Error; err != nil {
+		return nil, found(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) List() ([]model.User, error) {
+	var users []model.User
+	err := r.db.Preload("Games").Find(&users).Error
+	return users, err
+}
+
+type gormGames struct{ db *gorm.DB }
+
+func (r gormGames) Create(game *model.Game) error {
+	return r.db.Omit(clause.Associations).Create(game).Error
+}
+
+func (r gormGames) Get(id string) (*model.Game, error) {
+	var game model.Game
+	if err := r.db.First(&game, "id = ?", id).Error; err != nil {
+		return nil, found(err)
+	}
+	return &game, nil
+}
+
+func (r gormGames) Save(game *model.Game) error {
+	return saveVersioned(r.db, game, &game.Version)
+}
+
+func (r gormGames) SetStatus(id, status string) error {
+	return r.db.Model(&model.Game{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":  status,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+}
+
+func (r gormGames) ListForUser(userID, status string) ([]model.Game, error) {
+	query := r.db.Model(&model.Game{}).
+		Joins("JOIN game_players ON games.id = game_players.game_id").
+		Where("game_players.user_id = ?", userID)
+	if status != "" {
+		query = query.Where("games.status = ?", status)
+	}
+	var games []model.Game
+	err := query.Order("games.created_at DESC").Find(&games).Error
+	return games, err
+}
+
+func (r gormGames) AddPlayer(gp *model.GamePlayer) error {
+	return r.db.Omit(clause.Associations).Create(gp).Error
+}
+
+func (r gormGames) Player(gameID, userID string) (*model.GamePlayer, error) {
+	var gp model.GamePlayer
+	if err := r.db.Where("game_id = ? AND user_id = ?", gameID, userID).First(&gp).Error; err != nil {
+		return nil, found(err)
+	}
+	return &gp, nil
+}
+
+func (r gormGames) Players(gameID string) ([]model.GamePlayer, error) {
+	var players []model.GamePlayer
+	err := r.db.Preload("User").Where("game_id = ?", gameID).Order("join_order").Find(&players).Error
+	return players, err
+}
+
+func (r gormGames) SavePlayer(gp *model.GamePlayer) error {
+	return r.db.Omit(clause.Associations).Save(gp).Error
+}
+
+func (r gormGames) SetConnected(gameID, userID string, connected bool) error {
+	return r.db.Model(&model.GamePlayer{}).Where("game_id = ? AND user_id = ?", gameID, userID).
+		Update("is_connected", connected).Error
+}
+
+func (r gormGames) Touch(gameID, userID string, at time.Time) error {
+	return r.db.Model(&model.GamePlayer{}).Where("game_id = ? AND user_id = ?", gameID, userID).
+		Update("last_seen_at", at).Error
+}
+
+func (r gormGames) IdlePlayers(cutoff time.Time) ([]model.GamePlayer, error) {
+	var idle []model.GamePlayer
+	err := r.db.Model(&model.GamePlayer{}).
+		Joins("JOIN games ON games.id = game_players.game_id").
+		Joins("JOIN users ON users.id = game_players.user_id").
+		Where("games.status IN ('waiting', 'active') AND users.is_bot = false AND game_players.last_seen_at < ?", cutoff).
+		Find(&idle).Error
+	return idle, err
+}
+
+func (r gormGames) CreateSettings(settings *model.GameSettings) error {
+	return r.db.Create(settings).Error
+}
+
+func (r gormGames) Settings(gameID string) (*model.GameSettings, error) {
+	var settings model.GameSettings
+	if err := r.db.First(&settings, "game_id = ?", gameID).Error; err != nil {
+		return nil, found(err)
+	}
+	return &settings, nil
+}
+
+func (r gormGames) ListByStatus(status string) ([]model.Game, error) {
+	var games []model.Game
+	err := r.db.Where("status = ?", status).Order("created_at").Find(&games).Error
+	return games, err
+}
+
+// deleteStep deletes the rows of one table that belong to a game
+type deleteStep struct {
+	table string
+	model interface{}
+	where string
+	arg   interface{}
+}
+
+func (r gormGames) deleteAll(steps []deleteStep) (map[string]int64, error) {
+	deleted := make(map[string]int64)
+	for _, step := range steps {
+		res := r.db.Where(step.where, step.arg).Delete(step.model)
+		if res.Error != nil {
+			return deleted, fmt.Errorf("failed to delete %s: %w", step.table, res.Error)
+		}
+		deleted[step.table] += res.RowsAffected
+	}
+	return deleted, nil
+}
+
+func (r gormGames) PurgeDetails(gameID string) (map[string]int64, error) {
+	rounds := r.db.Model(&model.Round{}).Select("id").Where("game_id = ?", gameID)
+	turns := r.db.Model(&model.Turn{}).Select("id").Where("round_id IN (?)", rounds)
+	return r.deleteAll([]deleteStep{
+		{"played_cards", &model.PlayedCard{}, "turn_id IN (?)", turns},
+		{"turns", &model.Turn{}, "round_id IN (?)", rounds},
+		{"cards", &model.Card{}, "round_id IN (?)", rounds},
+		{"round_players", &model.RoundPlayer{}, "round_id IN (?)", rounds},
+		{"rounds", &model.Round{}, "game_id = ?", gameID},
+		{"game_session_logs", &model.GameSessionLog{}, "game_id = ?", gameID},
+		{"bot_memories", &model.BotMemory{}, "game_id = ?", gameID},
+	})
+}
+
+func (r gormGames) Delete(gameID string) (map[string]int64, error) {
+	var botIDs []string
+	if err := r.db.Model(&model.GamePlayer{}).
+		Joins("JOIN users ON users.id = game_players.user_id").
+		Where("game_players.game_id = ? AND users.is_bot = ?", gameID, true).
+		Where("game_players.user_id NOT IN (?)", r.db.Model(&model.GamePlayer{}).Select("user_id").Where("game_id <> ?", gameID)).
+		Pluck("game_players.user_id", &botIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find bots: %w", err)
+	}
+	deleted, err := r.deleteAll([]deleteStep{
+		{"webhook_deliveries", &model.WebhookDelivery{}, "game_id = ?", gameID},
+		{"webhooks", &model.Webhook{}, "game_id = ?", gameID},
+		{"share_links", &model.ShareLink{}, "game_id = ?", gameID},
+		{"game_events", &model.GameEvent{}, "game_id = ?", gameID},
+		{"game_settings", &model.GameSettings{}, "game_id = ?", gameID},
+		{"game_players", &model.GamePlayer{}, "game_id = ?", gameID},
+		{"games", &model.Game{}, "id = ?", gameID},
+	})
+	if err != nil || len(botIDs) == 0 {
+		return deleted, err
+	}
+	res := r.db.Where("id IN ?", botIDs).Delete(&model.User{})
+	if res.Error != nil {
+		return deleted, fmt.Errorf("failed to delete bots: %w", res.Error)
+	}
+	deleted["users"] += res.RowsAffected
+	return deleted, nil
+}
+
+type gormRounds struct{ db *gorm.DB }
+
+func (r gormRounds) Create(round *model.Round) error {
+	return r.db.Omit(clause.Associations).Create(round).Error
+}
+
+func (r gormRounds) Get(id string) (*model.Round, error) {
+	var round model.Round
+	if err := r.db.First(&round, "id = ?", id).Error; err != nil {
+		return nil, found(err)
+	}
+	return &round, nil
+}
+
+func (r gormRounds) Save(round *model.Round) error {
+	return saveVersioned(r.db, round, &round.Version)
+}
+
+func (r gormRounds) Current(gameID string) (*model.Round, error) {
+	var round model.Round
+	if err := r.db.Where("game_id = ? AND status IN ('dealing', 'active')", gameID).
+		Order("round_number DESC").First(&round).Error; err != nil {
+		return nil, found(err)
+	}
+	return &round, nil
+}
+
+func (r gormRounds) List(gameID string) ([]model.Round, error) {
+	var rounds []model.Round
+	err := r.db.Where("game_id = ?", gameID).Order("round_number").Find(&rounds).Error
+	return rounds, err
+}
+
+func (r gormRounds) AddPlayer(rp *model.RoundPlayer) error {
+	return r.db.Omit(clause.Associations).Create(rp).Error
+}
+
+func (r gormRounds) Players(roundID string) ([]model.RoundPlayer, error) {
+	var players []model.RoundPlayer
+	err := r.db.Preload("User").Where("round_id = ?", roundID).Order("position asc").Find(&players).Error
+	return players, err
+}
+
+func (r gormRounds) AdjustCardsInHand(roundID, userID string, delta int) error {
+	return r.db.Model(&model.RoundPlayer{}).
+		Where("round_id = ? AND user_id = ?", roundID, userID).
+		UpdateColumn("cards_in_hand", gorm.Expr("cards_in_hand + ?", delta)).Error
+}
+
+func (r gormRounds) FinishPlayer(roundID, userID string, at time.Time) error {
+	return r.db.Model(&model.RoundPlayer{}).
+		Where("round_id = ? AND user_id = ?", roundID, userID).
+		Updates(map[string]interface{}{
+			"is_finished":   true,
+			"finished_at":   &at,
+			"cards_in_hand": 0,
+		}).Error
+}
+
+type gormTurns struct{ db *gorm.DB }
+
+// withPlays loads a turn's played cards along with their cards and players
+func (r gormTurns) withPlays() *gorm.DB {
+	return r.db.Preload("PlayedCards", func(db *gorm.DB) *gorm.DB {
+		return db.Order("play_order")
+	}).Preload("PlayedCards.Card").Preload("PlayedCards.Player")
+}
+
+func (r gormTurns) Create(turn *model.Turn) error {
+	return r.db.Omit(clause.Associations).Create(turn).Error
+}
+
+func (r gormTurns) Get(id string) (*model.Turn, error) {
+	var turn model.Turn
+	if err := r.withPlays().First(&turn, "id = ?", id).Error; err != nil {
+		return nil, found(err)
+	}
+	return &turn, nil
+}
+
+func (r gormTurns) Save(turn *model.Turn) error {
+	return saveVersioned(r.db, turn, &turn.Version)
+}
+
+func (r gormTurns) Active(gameID string) (*model.Turn, error) {
+	var turn model.Turn
+	if err := r.withPlays().
+		Where("round_id IN (SELECT id FROM rounds WHERE game_id = ?) AND status = 'active'", gameID).
+		First(&turn).Error; err != nil {
+		return nil, found(err)
+	}
+	return &turn, nil
+}
+
+func (r gormTurns) Latest(roundID string) (*model.Turn, error) {
+	var turn model.Turn
+	if err := r.withPlays().Where("round_id = ?", roundID).
+		Order("turn_number DESC").First(&turn).Error; err != nil {
+		return nil, found(err)
+	}
+	return &turn, nil
+}
+
+func (r gormTurns) MaxNumber(roundID string) (int, error) {
+	var max int
+	if err := r.db.Model(&model.Turn{}).Where("round_id = ?", roundID).
+		Select("COALESCE(MAX(turn_number), 0)").Scan(&max).Error; err != nil {
+		return 0, fmt.Errorf("failed to get max turn number: %w", err)
+	}
+	return max, nil
+}
+
+func (r gormTurns) List(roundID string) ([]model.Turn, error) {
+	var turns []model.Turn
+	err := r.withPlays().Where("round_id = ?", roundID).Order("turn_number").Find(&turns).Error
+	return turns, err
+}
+
+func (r gormTurns) AddPlayedCard(pc *model.PlayedCard) error {
+	return r.db.Omit(clause.Associations).Create(pc).Error
+}
+
+type gormCards struct{ db *gorm.DB }
+
+func (r gormCards) Create(card *model.Card) error {
+	return r.db.Create(card).Error
+}
+
+func (r gormCards) Get(id string) (*model.Card, error) {
+	var card model.Card
+	if err := r.db.First(&card, "id = ?", id).Error; err != nil {
+		return nil, found(err)
+	}
+	return &card, nil
+}
+
+func (r gormCards) Save(card *model.Card) error {
+	return r.db.Save(card).Error
+}
+
+func (r gormCards) Find(roundID, suit, rank string) (*model.Card, error) {
+	var card model.Card
+	if err := r.db.Where("round_id = ? AND suit = ? AND rank = ?", roundID, suit, rank).First(&card).Error; err != nil {
+		return nil, found(err)
+	}
+	return &card, nil
+}
+
+func (r gormCards) Hand(roundID, userID string) ([]model.Card, error) {
+	var hand []model.Card
+	err := r.db.Where("round_id = ? AND owner_id = ? AND location = 'hand'", roundID, userID).
+		Order("sort_order").Find(&hand).Error
+	return hand, err
+}
+
+func (r gormCards) InHand(roundID string) ([]model.Card, error) {
+	var cards []model.Card
+	err := r.db.Where("round_id = ? AND location = 'hand'", roundID).
+		Order("owner_id, sort_order").Find(&cards).Error
+	return cards, err
+}
+
+func (r gormCards) Discarded(roundID string) ([]model.Card, error) {
+	var cards []model.Card
+	err := r.db.Model(&model.Card{}).
+		Joins("JOIN played_cards ON played_cards.card_id = cards.id").
+		Where("cards.round_id = ? AND cards.location = 'discard'", roundID).
+		Order("played_cards.played_at, played_cards.play_order").
+		Find(&cards).Error
+	return cards, err
+}
+
+type gormLogs struct{ db *gorm.DB }
+
+func (r gormLogs) Create(entry *model.GameSessionLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r gormLogs) Recent(gameID string, limit int) ([]model.GameSessionLog, error) {
+	query := r.db.Where("game_id = ?", gameID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var logs []model.GameSessionLog
+	err := query.Find(&logs).Error
+	return logs, err
+}
+
+type gormEvents struct{ db *gorm.DB }
+
+func (r gormEvents) Append(ev *model.GameEvent) error {
+	var last int
+	if err := r.db.Model(&model.GameEvent{}).Where("game_id = ?", ev.GameID).
+		Select("COALESCE(MAX(seq), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+	ev.Seq = last + 1
+	return r.db.Create(ev).Error
+}
+
+func (r gormEvents) List(gameID string) ([]model.GameEvent, error) {
+	var events []model.GameEvent
+	err := r.db.Where("game_id = ?", gameID).Order("seq").Find(&events).Error
+	return events, err
+}
+
+type gormArchives struct{ db *gorm.DB }
+
+func (r gormArchives) Save(a *model.GameArchive) error {
+	return r.db.Save(a).Error
+}
+
+func (r gormArchives) Get(gameID string) (*model.GameArchive, error) {
+	var a model.GameArchive
+	if err := r.db.First(&a, "game_id = ?", gameID).Error; err != nil {
+		return nil, found(err)
+	}
+	return &a, nil
+}
+
+type gormStats struct{ db *gorm.DB }
+
+func (r gormStats) Record(gameID string, rows []model.PlayerGameStats) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("game_id = ?", gameID).Delete(&model.PlayerGameStats{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r gormStats) List(userID string, filter StatsFilter) ([]model.PlayerGameStats, error) {
+	q := r.db.Where("user_id = ?", userID)
+	if !filter.From.IsZero() {
+		q = q.Where("completed_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("completed_at < ?", filter.To)
+	}
+	for _, opponent := range filter.Opponents {
+		q = q.Where("game_id IN (?)", r.db.Model(&model.GamePlayer{}).Select("game_id").Where("user_id = ?", opponent))
+	}
+	var rows []model.PlayerGameStats
+	err := q.Order("completed_at, game_id").Find(&rows).Error
+	return rows, err
+}
+
+func (r gormStats) ForGame(gameID string) ([]model.PlayerGameStats, error) {
+	var rows []model.PlayerGameStats
+	err := r.db.Where("game_id = ?", gameID).Order("user_id").Find(&rows).Error
+	return rows, err
+}
+
+type gormRatings struct{ db *gorm.DB }
+
+func (r gormRatings) Get(subjectID string) (*model.Rating, error) {
+	var rating model.Rating
+	if err := r.db.First(&rating, "subject_id = ?", subjectID).Error; err != nil {
+		return nil, found(err)
+	}
+	return &rating, nil
+}
+
+func (r gormRatings) Save(rating *model.Rating) error {
+	return r.db.Save(rating).Error
+}
+
+func (r gormRatings) Top(limit, minGames int) ([]model.Rating, error) {
+	var ratings []model.Rating
+	err := r.db.Where("games >= ?", minGames).Order("rating DESC, subject_id").Limit(limit).Find(&ratings).Error
+	return ratings, err
+}
+
+func (r gormRatings) AddChange(c *model.RatingChange) error {
+	return r.db.Create(c).Error
+}
+
+func (r gormRatings) History(subjectID string) ([]model.RatingChange, error) {
+	var changes []model.RatingChange
+	err := r.db.Where("subject_id = ?", subjectID).Order("created_at, id").Find(&changes).Error
+	return changes, err
+}
+
+func (r gormRatings) ForGame(gameID string) ([]model.RatingChange, error) {
+	var changes []model.RatingChange
+	err := r.db.Where("game_id = ?", gameID).Order("subject_id").Find(&changes).Error
+	return changes, err
+}
+
+type gormAchievements struct{ db *gorm.DB }
+
+func (r gormAchievements) Award(a *model.Achievement) error {
+	return r.db.Create(a).Error
+}
+
+func (r gormAchievements) List(userID string) ([]model.Achievement, error) {
+	var achievements []model.Achievement
+	err := r.db.Where("user_id = ?", userID).Order("earned_at, badge").Find(&achievements).Error
+	return achievements, err
+}
+
+type gormShares struct{ db *gorm.DB }
+
+func (r gormShares) Create(link *model.ShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r gormShares) Get(token string) (*model.ShareLink, error) {
+	var link model.ShareLink
+	if err := r.db.First(&link, "token = ?", token).Error; err != nil {
+		return nil, found(err)
+	}
+	return &link, nil
+}
+
+func (r gormShares) ForGame(gameID string) (*model.ShareLink, error) {
+	var link model.ShareLink
+	if err := r.db.First(&link, "game_id = ?", gameID).Error; err != nil {
+		return nil, found(err)
+	}
+	return &link, nil
+}
+
+func (r gormShares) Revoke(gameID string) error {
+	res := r.db.Where("game_id = ?", gameID).Delete(&model.ShareLink{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type gormWebhooks struct{ db *gorm.DB }
+
+func (r gormWebhooks) Create(hook *model.Webhook) error {
+	return r.db.Create(hook).Error
+}
+
+func (r gormWebhooks) Get(id, ownerID string) (*model.Webhook, error) {
+	var hook model.Webhook
+	if err := r.db.First(&hook, "id = ? AND owner_id = ?", id, ownerID).Error; err != nil {
+		return nil, found(err)
+	}
+	return &hook, nil
+}
+
+func (r gormWebhooks) ListForOwner(ownerID string) ([]model.Webhook, error) {
+	var hooks []model.Webhook
+	err := r.db.Where("owner_id = ?", ownerID).Order("created_at desc").Find(&hooks).Error
+	return hooks, err
+}
+
+func (r gormWebhooks) Delete(id string) error {
+	return r.db.Delete(&model.Webhook{}, "id = ?", id).Error
+}
+
+func (r gormWebhooks) ForGame(gameID string) ([]model.Webhook, error) {
+	var hooks []model.Webhook
+	err := r.db.Where("active = ? AND (game_id = ? OR (game_id IS NULL AND owner_id IN (?)))",
+		true, gameID, r.db.Model(&model.GamePlayer{}).Select("user_id").Where("game_id = ?", gameID)).
+		Order("created_at").Find(&hooks).Error
+	return hooks, err
+}
+
+func (r gormWebhooks) AddDelivery(d *model.WebhookDelivery) error {
+	return r.db.Create(d).Error
+}
+
+func (r gormWebhooks) SaveDelivery(d *model.WebhookDelivery) error {
+	return r.db.Save(d).Error
+}
+
+func (r gormWebhooks) Deliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.Where("webhook_id = ?", webhookID).Order("created_at desc").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
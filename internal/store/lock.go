@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// gameLocks hands out one mutex per game and forgets it once nobody holds or
+// waits for it
+type gameLocks struct {
+	mu    sync.Mutex
+	games map[string]*gameLock
+}
+
+type gameLock struct {
+	sync.Mutex
+	users int // holders and waiters, guarded by gameLocks.mu
+}
+
+func newGameLocks() *gameLocks {
+	return &gameLocks{games: make(map[string]*gameLock)}
+}
+
+// lock blocks until it holds the game's mutex and returns its release
+func (l *gameLocks) lock(gameID string) func() {
+	l.mu.Lock()
+	gl := l.games[gameID]
+	if gl == nil {
+		gl = &gameLock{}
+		l.games[gameID] = gl
+	}
+	gl.users++
+	l.mu.Unlock()
+
+	gl.Lock()
+	return func() {
+		gl.Unlock()
+		l.mu.Lock()
+		gl.users--
+		if gl.users == 0 {
+			delete(l.games, gameID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// advisoryKey maps a game ID onto the 64-bit key space of Postgres advisory
+// locks. A collision only makes two games wait on each other.
+func advisoryKey(gameID string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(gameID))
+	return int64(h.Sum64())
+}
+
+// advisoryLock takes a session-level Postgres advisory lock on a connection
+// set aside for it, so the lock is held by that session however many other
+// connections the locked work uses. The lock dies with the session, so a
+// crashed server never leaves a game locked.
+func advisoryLock(db *gorm.DB, gameID string) (func(), error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve lock connection: %w", err)
+	}
+	key := advisoryKey(gameID)
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	return func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// Never hand a session that may still hold the lock back to the
+			// pool; closing it releases the lock on the server.
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
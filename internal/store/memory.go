@@ -0,0 +1,1208 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// memory implements Store with plain maps. Records are copied in and out so
+// callers can never alias stored state, and relations are filled in on read
+// the way the GORM store preloads them.
+type memory struct {
+	locks *gameLocks
+	mu    rwLocker
+	undo  *[]func() // inside a transaction, what rolls back its writes
+	*tables
+}
+
+// tables are the rows of a memory store
+type tables struct {
+	users        map[string]model.User
+	games        map[string]model.Game
+	gamePlayers  map[pairKey]model.GamePlayer
+	settings     map[string]model.GameSettings
+	rounds       map[string]model.Round
+	roundPlayers map[pairKey]model.RoundPlayer
+	turns        map[string]model.Turn
+	playedCards  map[string]model.PlayedCard
+	cards        map[string]model.Card
+	logs         []model.GameSessionLog
+	events       map[string][]model.GameEvent // gameID -> journal
+	archives     map[string]model.GameArchive
+	stats        map[pairKey]model.PlayerGameStats
+	ratings      map[string]model.Rating
+	changes      []model.RatingChange
+	achievements map[pairKey]model.Achievement // keyed by badge and user
+	shares       map[string]model.ShareLink    // keyed by token
+	webhooks     map[string]model.Webhook
+	deliveries   map[string]model.WebhookDelivery
+}
+
+type rwLocker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+// pairKey identifies a row with a two-column primary key
+type pairKey struct{ parent, userID string }
+
+// NewMemory returns an empty Store that lives in process memory
+func NewMemory() Store {
+	return &memory{locks: newGameLocks(), mu: new(sync.RWMutex), tables: &tables{
+		users:        make(map[string]model.User),
+		games:        make(map[string]model.Game),
+		gamePlayers:  make(map[pairKey]model.GamePlayer),
+		settings:     make(map[string]model.GameSettings),
+		rounds:       make(map[string]model.Round),
+		roundPlayers: make(map[pairKey]model.RoundPlayer),
+		turns:        make(map[string]model.Turn),
+		playedCards:  make(map[string]model.PlayedCard),
+		cards:        make(map[string]model.Card),
+		events:       make(map[string][]model.GameEvent),
+		archives:     make(map[string]model.GameArchive),
+		stats:        make(map[pairKey]model.PlayerGameStats),
+		ratings:      make(map[string]model.Rating),
+		achievements: make(map[pairKey]model.Achievement),
+		shares:       make(map[string]model.ShareLink),
+		webhooks:     make(map[string]model.Webhook),
+		deliveries:   make(map[string]model.WebhookDelivery),
+	}}
+}
+
+func (m *memory) Games() Games               { return memGames{m} }
+func (m *memory) Rounds() Rounds             { return memRounds{m} }
+func (m *memory) Turns() Turns               { return memTurns{m} }
+func (m *memory) Cards() Cards               { return memCards{m} }
+func (m *memory) Logs() Logs                 { return memLogs{m} }
+func (m *memory) Users() Users               { return memUsers{m} }
+func (m *memory) Events() Events             { return memEvents{m} }
+func (m *memory) Archives() Archives         { return memArchives{m} }
+func (m *memory) Stats() Stats               { return memStats{m} }
+func (m *memory) Ratings() Ratings           { return memRatings{m} }
+func (m *memory) Achievements() Achievements { return memAchievements{m} }
+func (m *memory) Shares() Shares             { return memShares{m} }
+func (m *memory) Webhooks() Webhooks         { return memWebhooks{m} }
+
+// Transaction runs fn holding the store's lock, so other readers and writers
+// wait for it and never see its writes before it commits. Each write in fn
+// remembers the row it replaced, and a failed fn puts back only those rows.
+func (m *memory) Transaction(fn func(tx Store) error) (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var undo []func()
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+	if err := fn(memTx{&memory{locks: m.locks, mu: heldLock{}, undo: &undo, tables: m.tables}}); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (m *memory) Lock(gameID string) (func(), error) {
+	return m.locks.lock(gameID), nil
+}
+
+// memTx is the Store handed to a transaction; transactions nested in it
+// simply join the outer one
+type memTx struct{ *memory }
+
+func (t memTx) Transaction(fn func(tx Store) error) error {
+	return fn(t)
+}
+
+// heldLock stands in for the store's lock inside a transaction, which holds
+// it throughout
+type heldLock struct{}
+
+func (heldLock) Lock()    {}
+func (heldLock) Unlock()  {}
+func (heldLock) RLock()   {}
+func (heldLock) RUnlock() {}
+
+// put stores a row. Inside a transaction it first remembers the row it
+// replaces.
+func put[K comparable, V any](m *memory, table map[K]V, key K, value V) {
+	if m.undo != nil {
+		old, had := table[key]
+		*m.undo = append(*m.undo, func() {
+			if had {
+				table[key] = old
+			} else {
+				delete(table, key)
+			}
+		})
+	}
+	table[key] = value
+}
+
+// remove deletes a row. Inside a transaction it first remembers it.
+func remove[K comparable, V any](m *memory, table map[K]V, key K) {
+	if old, had := table[key]; had && m.undo != nil {
+		*m.undo = append(*m.undo, func() { table[key] = old })
+	}
+	delete(table, key)
+}
+
+// replace swaps the rows of a list table. Inside a transaction it first
+// remembers the old list.
+func replace[T any](m *memory, table *[]T, rows []T) {
+	if m.undo != nil {
+		old := *table
+		*m.undo = append(*m.undo, func() { *table = old })
+	}
+	*table = rows
+}
+
+func duplicate(table, id string) error {
+	return fmt.Errorf("duplicate %s %s", table, id)
+}
+
+type memUsers struct{ *memory }
+
+func (r memUsers) Create(user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.ID]; ok {
+		return duplicate("user", user.ID)
+	}
+	stored := *user
+	stored.Games = nil
+	put(r.memory, r.users, user.ID, stored)
+	return nil
+}
+
+func (r memUsers) Get(id string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r memUsers) GetWithGames(id string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user.Games = r.gamesOf(id)
+	return &user, nil
+}
+
+func (r memUsers) List() ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]model.User, 0, len(r.users))
+	for _, user := range r.users {
+		user.Games = r.gamesOf(user.ID)
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// gamesOf returns the games a user joined. The caller holds the lock.
+func (m *memory) gamesOf(userID string) []model.Game {
+	games := []model.Game{}
+	for key := range m.gamePlayers {
+		if key.userID == userID {
+			if game, ok := m.games[key.parent]; ok {
+				games = append(games, game)
+			}
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+	return games
+}
+
+type memGames struct{ *memory }
+
+func (r memGames) Create(game *model.Game) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.games[game.ID]; ok {
+		return duplicate("game", game.ID)
+	}
+	put(r.memory, r.games, game.ID, stripGame(*game))
+	return nil
+}
+
+func stripGame(game model.Game) model.Game {
+	game.Requester = model.User{}
+	game.Players = nil
+	game.Rounds = nil
+	game.GamePlayers = nil
+	game.SessionLogs = nil
+	return game
+}
+
+func (r memGames) Get(id string) (*model.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	game, ok := r.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &game, nil
+}
+
+func (r memGames) Save(game *model.Game) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.games[game.ID]; !ok || stored.Version != game.Version {
+		return ErrConflict
+	}
+	game.Version++
+	put(r.memory, r.games, game.ID, stripGame(*game))
+	return nil
+}
+
+func (r memGames) SetStatus(id, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if game, ok := r.games[id]; ok {
+		game.Status = status
+		game.Version++
+		put(r.memory, r.games, id, game)
+	}
+	return nil
+}
+
+func (r memGames) ListForUser(userID, status string) ([]model.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var games []model.Game
+	for _, game := range r.gamesOf(userID) {
+		if status == "" || game.Status == status {
+			games = append(games, game)
+		}
+	}
+	sort.SliceStable(games, func(i, j int) bool { return games[i].CreatedAt.After(games[j].CreatedAt) })
+	return games, nil
+}
+
+func (r memGames) AddPlayer(gp *model.GamePlayer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := pairKey{gp.GameID, gp.UserID}
+	if _, ok := r.gamePlayers[key]; ok {
+		return duplicate("game player", gp.UserID)
+	}
+	stored := *gp
+	stored.User = model.User{}
+	put(r.memory, r.gamePlayers, key, stored)
+	return nil
+}
+
+func (r memGames) Player(gameID, userID string) (*model.GamePlayer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	gp, ok := r.gamePlayers[pairKey{gameID, userID}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &gp, nil
+}
+
+func (r memGames) Players(gameID string) ([]model.GamePlayer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var players []model.GamePlayer
+	for key, gp := range r.gamePlayers {
+		if key.parent == gameID {
+			gp.User = r.users[gp.UserID]
+			players = append(players, gp)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].JoinOrder < players[j].JoinOrder })
+	return players, nil
+}
+
+func (r memGames) SavePlayer(gp *model.GamePlayer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *gp
+	stored.User = model.User{}
+	put(r.memory, r.gamePlayers, pairKey{gp.GameID, gp.UserID}, stored)
+	return nil
+}
+
+// updatePlayer applies fn to a stored game player, if it exists
+func (r memGames) updatePlayer(gameID, userID string, fn func(*model.GamePlayer)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := pairKey{gameID, userID}
+	if gp, ok := r.gamePlayers[key]; ok {
+		fn(&gp)
+		put(r.memory, r.gamePlayers, key, gp)
+	}
+	return nil
+}
+
+func (r memGames) SetConnected(gameID, userID string, connected bool) error {
+	return r.updatePlayer(gameID, userID, func(gp *model.GamePlayer) { gp.IsConnected = connected })
+}
+
+func (r memGames) Touch(gameID, userID string, at time.Time) error {
+	return r.updatePlayer(gameID, userID, func(gp *model.GamePlayer) { gp.LastSeenAt = at })
+}
+
+func (r memGames) IdlePlayers(cutoff time.Time) ([]model.GamePlayer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var idle []model.GamePlayer
+	for key, gp := range r.gamePlayers {
+		game := r.games[key.parent]
+		if (game.Status != "waiting" && game.Status != "active") || r.users[gp.UserID].IsBot {
+			continue
+		}
+		if gp.LastSeenAt.Before(cutoff) {
+			idle = append(idle, gp)
+		}
+	}
+	return idle, nil
+}
+
+func (r memGames) CreateSettings(settings *model.GameSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.settings[settings.GameID]; ok {
+		return duplicate("game settings", settings.GameID)
+	}
+	put(r.memory, r.settings, settings.GameID, *settings)
+	return nil
+}
+
+func (r memGames) Settings(gameID string) (*model.GameSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	settings, ok := r.settings[gameID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &settings, nil
+}
+
+func (r memGames) ListByStatus(status string) ([]model.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var games []model.Game
+	for _, game := range r.games {
+		if game.Status == status {
+			games = append(games, game)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].CreatedAt.Before(games[j].CreatedAt) })
+	return games, nil
+}
+
+// deleteWhere deletes the entries of a table that match and returns how many
+func deleteWhere[K comparable, V any](m *memory, table map[K]V, match func(K, V) bool) int64 {
+	var n int64
+	for key, value := range table {
+		if match(key, value) {
+			remove(m, table, key)
+			n++
+		}
+	}
+	return n
+}
+
+func (r memGames) PurgeDetails(gameID string) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rounds := make(map[string]bool)
+	for id, round := range r.rounds {
+		if round.GameID == gameID {
+			rounds[id] = true
+		}
+	}
+	turns := make(map[string]bool)
+	for id, turn := range r.turns {
+		if rounds[turn.RoundID] {
+			turns[id] = true
+		}
+	}
+
+	deleted := make(map[string]int64)
+	deleted["played_cards"] = deleteWhere(r.memory, r.playedCards, func(_ string, pc model.PlayedCard) bool { return turns[pc.TurnID] })
+	deleted["turns"] = deleteWhere(r.memory, r.turns, func(id string, _ model.Turn) bool { return turns[id] })
+	deleted["cards"] = deleteWhere(r.memory, r.cards, func(_ string, c model.Card) bool { return rounds[c.RoundID] })
+	deleted["round_players"] = deleteWhere(r.memory, r.roundPlayers, func(key pairKey, _ model.RoundPlayer) bool { return rounds[key.parent] })
+	deleted["rounds"] = deleteWhere(r.memory, r.rounds, func(id string, _ model.Round) bool { return rounds[id] })
+
+	logs := r.logs[:0:0]
+	for _, entry := range r.logs {
+		if entry.GameID != gameID {
+			logs = append(logs, entry)
+		}
+	}
+	deleted["game_session_logs"] = int64(len(r.logs) - len(logs))
+	replace(r.memory, &r.logs, logs)
+	return deleted, nil
+}
+
+func (r memGames) Delete(gameID string) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elsewhere := make(map[string]bool)
+	for key := range r.gamePlayers {
+		if key.parent != gameID {
+			elsewhere[key.userID] = true
+		}
+	}
+	var bots []string
+	for key := range r.gamePlayers {
+		if key.parent == gameID && r.users[key.userID].IsBot && !elsewhere[key.userID] {
+			bots = append(bots, key.userID)
+		}
+	}
+
+	deleted := make(map[string]int64)
+	deleted["webhook_deliveries"] = deleteWhere(r.memory, r.deliveries, func(_ string, d model.WebhookDelivery) bool { return d.GameID == gameID })
+	deleted["webhooks"] = deleteWhere(r.memory, r.webhooks, func(_ string, h model.Webhook) bool { return h.GameID != nil && *h.GameID == gameID })
+	deleted["share_links"] = deleteWhere(r.memory, r.shares, func(_ string, l model.ShareLink) bool { return l.GameID == gameID })
+	deleted["game_events"] = int64(len(r.events[gameID]))
+	remove(r.memory, r.events, gameID)
+	deleted["game_settings"] = deleteWhere(r.memory, r.settings, func(id string, _ model.GameSettings) bool { return id == gameID })
+	deleted["game_players"] = deleteWhere(r.memory, r.gamePlayers, func(key pairKey, _ model.GamePlayer) bool { return key.parent == gameID })
+	deleted["games"] = deleteWhere(r.memory, r.games, func(id string, _ model.Game) bool { return id == gameID })
+	if len(bots) > 0 {
+		deleted["users"] = deleteWhere(r.memory, r.users, func(id string, _ model.User) bool { return slices.Contains(bots, id) })
+	}
+	return deleted, nil
+}
+
+type memRounds struct{ *memory }
+
+func stripRound(round model.Round) model.Round {
+	round.Turns = nil
+	round.RoundPlayers = nil
+	round.Cards = nil
+	return round
+}
+
+func (r memRounds) Create(round *model.Round) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rounds[round.ID]; ok {
+		return duplicate("round", round.ID)
+	}
+	put(r.memory, r.rounds, round.ID, stripRound(*round))
+	return nil
+}
+
+func (r memRounds) Get(id string) (*model.Round, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	round, ok := r.rounds[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &round, nil
+}
+
+func (r memRounds) Save(round *model.Round) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.rounds[round.ID]; !ok || stored.Version != round.Version {
+		return ErrConflict
+	}
+	round.Version++
+	put(r.memory, r.rounds, round.ID, stripRound(*round))
+	return nil
+}
+
+func (r memRounds) Current(gameID string) (*model.Round, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var current *model.Round
+	for _, round := range r.rounds {
+		if round.GameID != gameID || (round.Status != "dealing" && round.Status != "active") {
+			continue
+		}
+		if current == nil || round.RoundNumber > current.RoundNumber {
+			current = &round
+		}
+	}
+	if current == nil {
+		return nil, ErrNotFound
+	}
+	return current, nil
+}
+
+func (r memRounds) List(gameID string) ([]model.Round, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var rounds []model.Round
+	for _, round := range r.rounds {
+		if round.GameID == gameID {
+			rounds = append(rounds, round)
+		}
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i].RoundNumber < rounds[j].RoundNumber })
+	return rounds, nil
+}
+
+func (r memRounds) AddPlayer(rp *model.RoundPlayer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := pairKey{rp.RoundID, rp.UserID}
+	if _, ok := r.roundPlayers[key]; ok {
+		return duplicate("round player", rp.UserID)
+	}
+	stored := *rp
+	stored.User = model.User{}
+	put(r.memory, r.roundPlayers, key, stored)
+	return nil
+}
+
+func (r memRounds) Players(roundID string) ([]model.RoundPlayer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var players []model.RoundPlayer
+	for key, rp := range r.roundPlayers {
+		if key.parent == roundID {
+			rp.User = r.users[rp.UserID]
+			players = append(players, rp)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Position < players[j].Position })
+	return players, nil
+}
+
+// updatePlayer applies fn to a stored round player, if it exists
+func (r memRounds) updatePlayer(roundID, userID string, fn func(*model.RoundPlayer)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := pairKey{roundID, userID}
+	if rp, ok := r.roundPlayers[key]; ok {
+		fn(&rp)
+		put(r.memory, r.roundPlayers, key, rp)
+	}
+	return nil
+}
+
+func (r memRounds) AdjustCardsInHand(roundID, userID string, delta int) error {
+	return r.updatePlayer(roundID, userID, func(rp *model.RoundPlayer) { rp.CardsInHand += delta })
+}
+
+func (r memRounds) FinishPlayer(roundID, userID string, at time.Time) error {
+	return r.updatePlayer(roundID, userID, func(rp *model.RoundPlayer) {
+		rp.IsFinished = true
+		rp.FinishedAt = &at
+		rp.CardsInHand = 0
+	})
+}
+
+type memTurns struct{ *memory }
+
+// withPlays returns a copy of a turn with its played cards loaded. The caller
+// holds the lock.
+func (r memTurns) withPlays(turn model.Turn) *model.Turn {
+	turn.PlayedCards = nil
+	for _, pc := range r.playedCards {
+		if pc.TurnID == turn.ID {
+			pc.Card = r.cards[pc.CardID]
+			pc.Player = r.users[pc.PlayerID]
+			turn.PlayedCards = append(turn.PlayedCards, pc)
+		}
+	}
+	sort.Slice(turn.PlayedCards, func(i, j int) bool {
+		return turn.PlayedCards[i].PlayOrder < turn.PlayedCards[j].PlayOrder
+	})
+	return &turn
+}
+
+func (r memTurns) Create(turn *model.Turn) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.turns[turn.ID]; ok {
+		return duplicate("turn", turn.ID)
+	}
+	stored := *turn
+	stored.PlayedCards = nil
+	put(r.memory, r.turns, turn.ID, stored)
+	return nil
+}
+
+func (r memTurns) Get(id string) (*model.Turn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	turn, ok := r.turns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return r.withPlays(turn), nil
+}
+
+func (r memTurns) Save(turn *model.Turn) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.turns[turn.ID]; !ok || stored.Version != turn.Version {
+		return ErrConflict
+	}
+	turn.Version++
+	stored := *turn
+	stored.PlayedCards = nil
+	put(r.memory, r.turns, turn.ID, stored)
+	return nil
+}
+
+func (r memTurns) Active(gameID string) (*model.Turn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var active *model.Turn
+	for _, turn := range r.turns {
+		if turn.Status != "active" || r.rounds[turn.RoundID].GameID != gameID {
+			continue
+		}
+		if active == nil || turn.ID < active.ID {
+			active = &turn
+		}
+	}
+	if active == nil {
+		return nil, ErrNotFound
+	}
+	return r.withPlays(*active), nil
+}
+
+func (r memTurns) Latest(roundID string) (*model.Turn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var latest *model.Turn
+	for _, turn := range r.turns {
+		if turn.RoundID != roundID {
+			continue
+		}
+		if latest == nil || turn.TurnNumber > latest.TurnNumber {
+			latest = &turn
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	return r.withPlays(*latest), nil
+}
+
+func (r memTurns) MaxNumber(roundID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	max := 0
+	for _, turn := range r.turns {
+		if turn.RoundID == roundID && turn.TurnNumber > max {
+			max = turn.TurnNumber
+		}
+	}
+	return max, nil
+}
+
+func (r memTurns) List(roundID string) ([]model.Turn, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var turns []model.Turn
+	for _, turn := range r.turns {
+		if turn.RoundID == roundID {
+			turns = append(turns, *r.withPlays(turn))
+		}
+	}
+	sort.Slice(turns, func(i, j int) bool { return turns[i].TurnNumber < turns[j].TurnNumber })
+	return turns, nil
+}
+
+func (r memTurns) AddPlayedCard(pc *model.PlayedCard) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.playedCards[pc.ID]; ok {
+		return duplicate("played card", pc.ID)
+	}
+	stored := *pc
+	stored.Card = model.Card{}
+	stored.Player = model.User{}
+	put(r.memory, r.playedCards, pc.ID, stored)
+	return nil
+}
+
+type memCards struct{ *memory }
+
+func (r memCards) Create(card *model.Card) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cards[card.ID]; ok {
+		return duplicate("card", card.ID)
+	}
+	put(r.memory, r.cards, card.ID, *card)
+	return nil
+}
+
+func (r memCards) Get(id string) (*model.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	card, ok := r.cards[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &card, nil
+}
+
+func (r memCards) Save(card *model.Card) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	put(r.memory, r.cards, card.ID, *card)
+	return nil
+}
+
+func (r memCards) Find(roundID, suit, rank string) (*model.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, card := range r.cards {
+		if card.RoundID == roundID && card.Suit == suit && card.Rank == rank {
+			return &card, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// where returns the cards of a round matching keep. The caller holds the lock.
+func (r memCards) where(roundID string, keep func(model.Card) bool) []model.Card {
+	var cards []model.Card
+	for _, card := range r.cards {
+		if card.RoundID == roundID && keep(card) {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+func (r memCards) Hand(roundID, userID string) ([]model.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hand := r.where(roundID, func(c model.Card) bool {
+		return c.Location == "hand" && c.OwnerID != nil && *c.OwnerID == userID
+	})
+	sort.Slice(hand, func(i, j int) bool { return hand[i].SortOrder < hand[j].SortOrder })
+	return hand, nil
+}
+
+func (r memCards) InHand(roundID string) ([]model.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cards := r.where(roundID, func(c model.Card) bool { return c.Location == "hand" && c.OwnerID != nil })
+	sort.Slice(cards, func(i, j int) bool {
+		if *cards[i].OwnerID != *cards[j].OwnerID {
+			return *cards[i].OwnerID < *cards[j].OwnerID
+		}
+		return cards[i].SortOrder < cards[j].SortOrder
+	})
+	return cards, nil
+}
+
+func (r memCards) Discarded(roundID string) ([]model.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	plays := make(map[string]model.PlayedCard)
+	for _, pc := range r.playedCards {
+		plays[pc.CardID] = pc
+	}
+	cards := r.where(roundID, func(c model.Card) bool {
+		_, played := plays[c.ID]
+		return c.Location == "discard" && played
+	})
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := plays[cards[i].ID], plays[cards[j].ID]
+		if !a.PlayedAt.Equal(b.PlayedAt) {
+			return a.PlayedAt.Before(b.PlayedAt)
+		}
+		return a.PlayOrder < b.PlayOrder
+	})
+	return cards, nil
+}
+
+type memLogs struct{ *memory }
+
+func (r memLogs) Create(entry *model.GameSessionLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replace(r.memory, &r.logs, append(r.logs, *entry))
+	return nil
+}
+
+func (r memLogs) Recent(gameID string, limit int) ([]model.GameSessionLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var logs []model.GameSessionLog
+	for i := len(r.logs) - 1; i >= 0; i-- {
+		if r.logs[i].GameID == gameID {
+			logs = append(logs, r.logs[i])
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].CreatedAt.After(logs[j].CreatedAt) })
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs, nil
+}
+
+type memEvents struct{ *memory }
+
+func (r memEvents) Append(ev *model.GameEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ev.Seq = len(r.events[ev.GameID]) + 1
+	put(r.memory, r.events, ev.GameID, append(r.events[ev.GameID], *ev))
+	return nil
+}
+
+func (r memEvents) List(gameID string) ([]model.GameEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.events[gameID]), nil
+}
+
+type memArchives struct{ *memory }
+
+func (r memArchives) Save(a *model.GameArchive) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	put(r.memory, r.archives, a.GameID, *a)
+	return nil
+}
+
+func (r memArchives) Get(gameID string) (*model.GameArchive, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.archives[gameID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &a, nil
+}
+
+type memStats struct{ *memory }
+
+func (r memStats) Record(gameID string, rows []model.PlayerGameStats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.stats {
+		if key.parent == gameID {
+			remove(r.memory, r.stats, key)
+		}
+	}
+	for _, row := range rows {
+		row.GameID = gameID
+		put(r.memory, r.stats, pairKey{gameID, row.UserID}, row)
+	}
+	return nil
+}
+
+func (r memStats) List(userID string, filter StatsFilter) ([]model.PlayerGameStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var rows []model.PlayerGameStats
+	for key, row := range r.stats {
+		if key.userID != userID {
+			continue
+		}
+		if !filter.From.IsZero() && row.CompletedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !row.CompletedAt.Before(filter.To) {
+			continue
+		}
+		played := true
+		for _, opponent := range filter.Opponents {
+			if _, ok := r.gamePlayers[pairKey{row.GameID, opponent}]; !ok {
+				played = false
+			}
+		}
+		if played {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].CompletedAt.Equal(rows[j].CompletedAt) {
+			return rows[i].CompletedAt.Before(rows[j].CompletedAt)
+		}
+		return rows[i].GameID < rows[j].GameID
+	})
+	return rows, nil
+}
+
+func (r memStats) ForGame(gameID string) ([]model.PlayerGameStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var rows []model.PlayerGameStats
+	for key, row := range r.stats {
+		if key.parent == gameID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].UserID < rows[j].UserID })
+	return rows, nil
+}
+
+type memRatings struct{ *memory }
+
+func (r memRatings) Get(subjectID string) (*model.Rating, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rating, ok := r.ratings[subjectID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &rating, nil
+}
+
+func (r memRatings) Save(rating *model.Rating) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	put(r.memory, r.ratings, rating.SubjectID, *rating)
+	return nil
+}
+
+func (r memRatings) Top(limit, minGames int) ([]model.Rating, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ratings []model.Rating
+	for _, rating := range r.ratings {
+		if rating.Games >= minGames {
+			ratings = append(ratings, rating)
+		}
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].SubjectID < ratings[j].SubjectID
+	})
+	if len(ratings) > limit {
+		ratings = ratings[:limit]
+	}
+	return ratings, nil
+}
+
+func (r memRatings) AddChange(c *model.RatingChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replace(r.memory, &r.changes, append(r.changes, *c))
+	return nil
+}
+
+func (r memRatings) History(subjectID string) ([]model.RatingChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var changes []model.RatingChange
+	for _, c := range r.changes {
+		if c.SubjectID == subjectID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func (r memRatings) ForGame(gameID string) ([]model.RatingChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var changes []model.RatingChange
+	for _, c := range r.changes {
+		if c.GameID == gameID {
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].SubjectID < changes[j].SubjectID })
+	return changes, nil
+}
+
+type memAchievements struct{ *memory }
+
+func (r memAchievements) Award(a *model.Achievement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := pairKey{a.Badge, a.UserID}
+	if _, ok := r.achievements[key]; ok {
+		return duplicate("achievement", a.Badge)
+	}
+	put(r.memory, r.achievements, key, *a)
+	return nil
+}
+
+func (r memAchievements) List(userID string) ([]model.Achievement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var achievements []model.Achievement
+	for key, a := range r.achievements {
+		if key.userID == userID {
+			achievements = append(achievements, a)
+		}
+	}
+	sort.Slice(achievements, func(i, j int) bool {
+		if !achievements[i].EarnedAt.Equal(achievements[j].EarnedAt) {
+			return achievements[i].EarnedAt.Before(achievements[j].EarnedAt)
+		}
+		return achievements[i].Badge < achievements[j].Badge
+	})
+	return achievements, nil
+}
+
+type memShares struct{ *memory }
+
+func (r memShares) Create(link *model.ShareLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.shares[link.Token]; ok {
+		return duplicate("share link", link.Token)
+	}
+	for _, l := range r.shares {
+		if l.GameID == link.GameID {
+			return duplicate("share link for game", link.GameID)
+		}
+	}
+	put(r.memory, r.shares, link.Token, *link)
+	return nil
+}
+
+func (r memShares) Get(token string) (*model.ShareLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	link, ok := r.shares[token]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &link, nil
+}
+
+func (r memShares) ForGame(gameID string) (*model.ShareLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, link := range r.shares {
+		if link.GameID == gameID {
+			return &link, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memShares) Revoke(gameID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for token, link := range r.shares {
+		if link.GameID == gameID {
+			remove(r.memory, r.shares, token)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+type memWebhooks struct{ *memory }
+
+func (r memWebhooks) Create(hook *model.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[hook.ID]; ok {
+		return duplicate("webhook", hook.ID)
+	}
+	put(r.memory, r.webhooks, hook.ID, *hook)
+	return nil
+}
+
+func (r memWebhooks) Get(id, ownerID string) (*model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hook, ok := r.webhooks[id]
+	if !ok || hook.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return &hook, nil
+}
+
+func (r memWebhooks) ListForOwner(ownerID string) ([]model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var hooks []model.Webhook
+	for _, hook := range r.webhooks {
+		if hook.OwnerID == ownerID {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].CreatedAt.After(hooks[j].CreatedAt) })
+	return hooks, nil
+}
+
+func (r memWebhooks) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	remove(r.memory, r.webhooks, id)
+	return nil
+}
+
+func (r memWebhooks) ForGame(gameID string) ([]model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var hooks []model.Webhook
+	for _, hook := range r.webhooks {
+		if !hook.Active {
+			continue
+		}
+		if hook.GameID != nil && *hook.GameID == gameID {
+			hooks = append(hooks, hook)
+			continue
+		}
+		if _, plays := r.gamePlayers[pairKey{gameID, hook.OwnerID}]; hook.GameID == nil && plays {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].CreatedAt.Before(hooks[j].CreatedAt) })
+	return hooks, nil
+}
+
+func (r memWebhooks) AddDelivery(d *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.deliveries[d.ID]; ok {
+		return duplicate("webhook delivery", d.ID)
+	}
+	put(r.memory, r.deliveries, d.ID, *d)
+	return nil
+}
+
+func (r memWebhooks) SaveDelivery(d *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	put(r.memory, r.deliveries, d.ID, *d)
+	return nil
+}
+
+func (r memWebhooks) Deliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var deliveries []model.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
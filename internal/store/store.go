@@ -0,0 +1,246 @@
+// Package store defines the repositories the game engine and API read and
+// write through, so they do not depend on a particular database. NewGorm backs
+// them with any GORM connection; NewMemory keeps everything in process, which
+// gives each test its own isolated store.
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+)
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when saving a game, round or turn that was changed
+// by someone else since it was read. Games, rounds and turns carry a version
+// that every save checks and advances, so of two racing writers only the
+// first succeeds, however many server instances share the database.
+var ErrConflict = errors.New("record was changed concurrently")
+
+// Store groups the repositories for every part of a game
+type Store interface {
+	Games() Games
+	Rounds() Rounds
+	Turns() Turns
+	Cards() Cards
+	Logs() Logs
+	Users() Users
+	Events() Events
+	Archives() Archives
+	Stats() Stats
+	Ratings() Ratings
+	Achievements() Achievements
+	Shares() Shares
+	Webhooks() Webhooks
+
+	// Transaction runs fn with a Store whose writes commit together when fn
+	// returns nil and are rolled back when it returns an error
+	Transaction(fn func(tx Store) error) error
+	// Lock blocks until it holds the lock of a game and returns the function
+	// that releases it. The lock is not reentrant.
+	Lock(gameID string) (unlock func(), err error)
+}
+
+// Users stores registered players and bots
+type Users interface {
+	Create(user *model.User) error
+	Get(id string) (*model.User, error)
+	// GetWithGames returns a user together with the games they joined
+	GetWithGames(id string) (*model.User, error)
+	// List returns every user together with the games they joined
+	List() ([]model.User, error)
+}
+
+// Games stores games, their players and their settings
+type Games interface {
+	Create(game *model.Game) error
+	Get(id string) (*model.Game, error)
+	// Save writes the game if it is still at its version, then advances the
+	// version; otherwise it returns ErrConflict
+	Save(game *model.Game) error
+	SetStatus(id, status string) error
+	// ListForUser returns the games a user plays in, newest first. An empty
+	// status matches every game.
+	ListForUser(userID, status string) ([]model.Game, error)
+
+	AddPlayer(gp *model.GamePlayer) error
+	Player(gameID, userID string) (*model.GamePlayer, error)
+	// Players returns a game's players with their users, in join order
+	Players(gameID string) ([]model.GamePlayer, error)
+	SavePlayer(gp *model.GamePlayer) error
+	SetConnected(gameID, userID string, connected bool) error
+	Touch(gameID, userID string, at time.Time) error
+	// IdlePlayers returns the human players of waiting or active games who
+	// were last seen before cutoff
+	IdlePlayers(cutoff time.Time) ([]model.GamePlayer, error)
+
+	CreateSettings(settings *model.GameSettings) error
+	Settings(gameID string) (*model.GameSettings, error)
+
+	// ListByStatus returns every game with the given status
+	ListByStatus(status string) ([]model.Game, error)
+	// PurgeDetails deletes a game's rounds, turns and cards along with its
+	// session logs and bot memories, keeping the game, its players, settings
+	// and event journal. It returns the rows deleted per table.
+	PurgeDetails(gameID string) (map[string]int64, error)
+	// Delete removes a game with its players, settings, share link, webhooks
+	// and event journal, and the bots that played in no other game. Its details must have been
+	// purged first. It returns the rows deleted per table.
+	Delete(gameID string) (map[string]int64, error)
+}
+
+// Rounds stores rounds and the players seated in them
+type Rounds interface {
+	Create(round *model.Round) error
+	Get(id string) (*model.Round, error)
+	// Save writes the round if it is still at its version, then advances the
+	// version; otherwise it returns ErrConflict
+	Save(round *model.Round) error
+	// Current returns the game's latest round that is dealing or active
+	Current(gameID string) (*model.Round, error)
+	// List returns every round of a game in round order
+	List(gameID string) ([]model.Round, error)
+
+	AddPlayer(rp *model.RoundPlayer) error
+	// Players returns a round's players with their users, in seating order
+	Players(roundID string) ([]model.RoundPlayer, error)
+	// AdjustCardsInHand adds delta to a player's card count
+	AdjustCardsInHand(roundID, userID string, delta int) error
+	// FinishPlayer marks a player as having played out their hand
+	FinishPlayer(roundID, userID string, at time.Time) error
+}
+
+// Turns stores turns and the cards played in them. Turns are returned with
+// their played cards, each with its card and player loaded.
+type Turns interface {
+	Create(turn *model.Turn) error
+	Get(id string) (*model.Turn, error)
+	// Save writes the turn if it is still at its version, then advances the
+	// version; otherwise it returns ErrConflict
+	Save(turn *model.Turn) error
+	// Active returns the game's active turn
+	Active(gameID string) (*model.Turn, error)
+	// Latest returns the highest-numbered turn of a round
+	Latest(roundID string) (*model.Turn, error)
+	// MaxNumber returns the highest turn number of a round, or 0 if none
+	MaxNumber(roundID string) (int, error)
+	// List returns every turn of a round in turn order
+	List(roundID string) ([]model.Turn, error)
+	AddPlayedCard(pc *model.PlayedCard) error
+}
+
+// Cards stores the deck of each round
+type Cards interface {
+	Create(card *model.Card) error
+	Get(id string) (*model.Card, error)
+	Save(card *model.Card) error
+	// Find returns the card of a round with the given suit and rank
+	Find(roundID, suit, rank string) (*model.Card, error)
+	// Hand returns the cards a player holds in a round, in sort order
+	Hand(roundID, userID string) ([]model.Card, error)
+	// InHand returns every card held in a round, by owner then sort order
+	InHand(roundID string) ([]model.Card, error)
+	// Discarded returns a round's discard pile in the order it was played
+	Discarded(roundID string) ([]model.Card, error)
+}
+
+// Logs stores a game's session log
+type Logs interface {
+	Create(entry *model.GameSessionLog) error
+	// Recent returns a game's logs newest first, at most limit of them
+	// unless limit is 0
+	Recent(gameID string, limit int) ([]model.GameSessionLog, error)
+}
+
+// Events stores the append-only journal of each game
+type Events interface {
+	// Append adds an event after the game's last one and sets its Seq. Two
+	// writers racing for the same Seq cannot both succeed.
+	Append(ev *model.GameEvent) error
+	// List returns a game's events in Seq order
+	List(gameID string) ([]model.GameEvent, error)
+}
+
+// Archives stores the compact records of completed games
+type Archives interface {
+	// Save creates or replaces a game's archive
+	Save(a *model.GameArchive) error
+	Get(gameID string) (*model.GameArchive, error)
+}
+
+// StatsFilter narrows the games a user's statistics cover. A zero From or To
+// leaves that end of the range open.
+type StatsFilter struct {
+	From      time.Time // completed at or after
+	To        time.Time // completed before
+	Opponents []string  // every one of them played in the game
+}
+
+// Stats stores each player's tally of the games they completed
+type Stats interface {
+	// Record writes the tallies of a game, replacing any it already had
+	Record(gameID string, rows []model.PlayerGameStats) error
+	// List returns a user's tallies of the games filter matches, oldest
+	// first
+	List(userID string, filter StatsFilter) ([]model.PlayerGameStats, error)
+	// ForGame returns the tallies of every player of a game
+	ForGame(gameID string) ([]model.PlayerGameStats, error)
+}
+
+// Ratings stores skill ratings and how each rated game changed them
+type Ratings interface {
+	Get(subjectID string) (*model.Rating, error)
+	// Save creates or replaces a rating
+	Save(r *model.Rating) error
+	// Top returns the highest ratings of at least minGames games, at most
+	// limit of them
+	Top(limit, minGames int) ([]model.Rating, error)
+	AddChange(c *model.RatingChange) error
+	// History returns a rating's changes, oldest first
+	History(subjectID string) ([]model.RatingChange, error)
+	// ForGame returns the changes a game made, by subject
+	ForGame(gameID string) ([]model.RatingChange, error)
+}
+
+// Achievements stores the badges users have earned
+type Achievements interface {
+	// Award stores a badge; a user earns each badge only once, so awarding
+	// it again fails
+	Award(a *model.Achievement) error
+	// List returns a user's badges in the order they were earned
+	List(userID string) ([]model.Achievement, error)
+}
+
+// Shares stores the links that let outsiders look at a game
+type Shares interface {
+	// Create stores a link; a game has at most one, so a second one fails
+	Create(link *model.ShareLink) error
+	Get(token string) (*model.ShareLink, error)
+	// ForGame returns the link of a game
+	ForGame(gameID string) (*model.ShareLink, error)
+	// Revoke deletes the link of a game, or returns ErrNotFound if it has none
+	Revoke(gameID string) error
+}
+
+// Webhooks stores outgoing webhooks and every attempt to deliver to them
+type Webhooks interface {
+	Create(hook *model.Webhook) error
+	// Get returns a webhook of the given owner
+	Get(id, ownerID string) (*model.Webhook, error)
+	// ListForOwner returns a user's webhooks, newest first
+	ListForOwner(ownerID string) ([]model.Webhook, error)
+	Delete(id string) error
+	// ForGame returns the active webhooks registered on a game, together
+	// with those without a game owned by one of its players
+	ForGame(gameID string) ([]model.Webhook, error)
+
+	AddDelivery(d *model.WebhookDelivery) error
+	// SaveDelivery writes the outcome of an attempt to deliver
+	SaveDelivery(d *model.WebhookDelivery) error
+	// Deliveries returns a webhook's deliveries, newest first, at most limit
+	// of them
+	Deliveries(webhookID string, limit int) ([]model.WebhookDelivery, error)
+}
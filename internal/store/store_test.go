@@ -0,0 +1,403 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/kairodrad/donkey/internal/db/migrations"
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// stores returns a fresh instance of every Store implementation
+func stores(t *testing.T) map[string]store.Store {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open("file:"+model.NewID()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, migrations.Up(conn))
+	return map[string]store.Store{
+		"gorm":   store.NewGorm(conn),
+		"memory": store.NewMemory(),
+	}
+}
+
+func TestStoresBehaveAlike(t *testing.T) {
+	for name, repo := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Truncate(time.Second)
+			ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+			bot := model.User{ID: model.NewID(), Name: "Bot", IsBot: true, BotDifficulty: "easy", CreatedAt: now}
+			require.NoError(t, repo.Users().Create(&ann))
+			require.NoError(t, repo.Users().Create(&bot))
+			_, err := repo.Users().Get("missing")
+			assert.ErrorIs(t, err, store.ErrNotFound)
+
+			game := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "active", MaxPlayers: 8, MinPlayers: 2, CreatedAt: now}
+			require.NoError(t, repo.Games().Create(&game))
+			require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: game.ID, UserID: bot.ID, JoinOrder: 1, JoinedAt: now, LastSeenAt: now}))
+			require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: game.ID, UserID: ann.ID, JoinOrder: 0, JoinedAt: now, LastSeenAt: now.Add(-time.Hour)}))
+			assert.Error(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: game.ID, UserID: ann.ID}), "a player joins once")
+
+			players, err := repo.Games().Players(game.ID)
+			require.NoError(t, err)
+			require.Len(t, players, 2)
+			assert.Equal(t, "Ann", players[0].User.Name, "players come in join order with their users")
+			assert.True(t, players[1].User.IsBot)
+
+			idle, err := repo.Games().IdlePlayers(now.Add(-time.Minute))
+			require.NoError(t, err)
+			require.Len(t, idle, 1, "bots are never idle")
+			assert.Equal(t, ann.ID, idle[0].UserID)
+
+			require.NoError(t, repo.Games().CreateSettings(&model.GameSettings{GameID: game.ID, MaxBots: 6}))
+			settings, err := repo.Games().Settings(game.ID)
+			require.NoError(t, err)
+			assert.False(t, settings.Rated, "false flags are stored, not their defaults")
+
+			listed, err := repo.Games().ListForUser(ann.ID, "active")
+			require.NoError(t, err)
+			assert.Len(t, listed, 1)
+			listed, err = repo.Games().ListForUser(ann.ID, "completed")
+			require.NoError(t, err)
+			assert.Empty(t, listed)
+			withGames, err := repo.Users().GetWithGames(ann.ID)
+			require.NoError(t, err)
+			assert.Len(t, withGames.Games, 1)
+
+			round := model.Round{ID: model.NewID(), GameID: game.ID, RoundNumber: 1, Status: "active", StartedAt: now}
+			require.NoError(t, repo.Rounds().Create(&round))
+			require.NoError(t, repo.Rounds().AddPlayer(&model.RoundPlayer{RoundID: round.ID, UserID: ann.ID, Position: 1}))
+			require.NoError(t, repo.Rounds().AddPlayer(&model.RoundPlayer{RoundID: round.ID, UserID: bot.ID, Position: 0}))
+			require.NoError(t, repo.Rounds().AdjustCardsInHand(round.ID, ann.ID, 2))
+			require.NoError(t, repo.Rounds().AdjustCardsInHand(round.ID, ann.ID, -1))
+			require.NoError(t, repo.Rounds().FinishPlayer(round.ID, bot.ID, now))
+			current, err := repo.Rounds().Current(game.ID)
+			require.NoError(t, err)
+			assert.Equal(t, round.ID, current.ID)
+			seated, err := repo.Rounds().Players(round.ID)
+			require.NoError(t, err)
+			require.Len(t, seated, 2)
+			assert.Equal(t, bot.ID, seated[0].UserID, "round players come in seating order")
+			assert.True(t, seated[0].IsFinished)
+			assert.Equal(t, 1, seated[1].CardsInHand)
+
+			deck := model.CreateStandardDeck(round.ID)
+			for i := range deck[:3] {
+				deck[i].Location = "hand"
+				deck[i].OwnerID = &ann.ID
+				require.NoError(t, repo.Cards().Create(&deck[i]))
+			}
+			hand, err := repo.Cards().Hand(round.ID, ann.ID)
+			require.NoError(t, err)
+			require.Len(t, hand, 3)
+			assert.LessOrEqual(t, hand[0].SortOrder, hand[1].SortOrder)
+
+			turn := model.Turn{ID: model.NewID(), RoundID: round.ID, TurnNumber: 1, StartPlayerID: ann.ID, Status: "active", StartedAt: now}
+			require.NoError(t, repo.Turns().Create(&turn))
+			for i, card := range hand[:2] {
+				card.Location = "discard"
+				card.OwnerID = nil
+				require.NoError(t, repo.Cards().Save(&card))
+				require.NoError(t, repo.Turns().AddPlayedCard(&model.PlayedCard{
+					ID: model.NewID(), TurnID: turn.ID, CardID: card.ID, PlayerID: ann.ID, PlayOrder: i + 1, PlayedAt: now.Add(time.Duration(i) * time.Second),
+				}))
+			}
+			active, err := repo.Turns().Active(game.ID)
+			require.NoError(t, err)
+			require.Len(t, active.PlayedCards, 2)
+			assert.Equal(t, hand[0].ID, active.PlayedCards[0].Card.ID, "played cards come with their cards")
+			assert.Equal(t, "Ann", active.PlayedCards[0].Player.Name)
+			discarded, err := repo.Cards().Discarded(round.ID)
+			require.NoError(t, err)
+			require.Len(t, discarded, 2)
+			assert.Equal(t, hand[0].ID, discarded[0].ID, "discard pile in play order")
+
+			active.Status = "completed"
+			require.NoError(t, repo.Turns().Save(active))
+			_, err = repo.Turns().Active(game.ID)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+			max, err := repo.Turns().MaxNumber(round.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, max)
+
+			for i, msg := range []string{"first", "second", "third"} {
+				require.NoError(t, repo.Logs().Create(&model.GameSessionLog{ID: model.NewID(), GameID: game.ID, Type: "chat", Message: msg, CreatedAt: now.Add(time.Duration(i) * time.Second)}))
+			}
+			logs, err := repo.Logs().Recent(game.ID, 2)
+			require.NoError(t, err)
+			require.Len(t, logs, 2)
+			assert.Equal(t, "third", logs[0].Message)
+
+			link := model.ShareLink{Token: model.NewID(), GameID: game.ID, CreatedBy: ann.ID, CreatedAt: now}
+			require.NoError(t, repo.Shares().Create(&link))
+			assert.Error(t, repo.Shares().Create(&model.ShareLink{Token: model.NewID(), GameID: game.ID, CreatedBy: ann.ID, CreatedAt: now}), "a game has one link")
+			shared, err := repo.Shares().ForGame(game.ID)
+			require.NoError(t, err)
+			assert.Equal(t, link.Token, shared.Token)
+			shared, err = repo.Shares().Get(link.Token)
+			require.NoError(t, err)
+			assert.Equal(t, game.ID, shared.GameID)
+			_, err = repo.Shares().Get(model.NewID())
+			assert.ErrorIs(t, err, store.ErrNotFound)
+			require.NoError(t, repo.Shares().Revoke(game.ID))
+			_, err = repo.Shares().Get(link.Token)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+			assert.ErrorIs(t, repo.Shares().Revoke(game.ID), store.ErrNotFound)
+		})
+	}
+}
+
+func TestMemoryStoreCopiesRecords(t *testing.T) {
+	repo := store.NewMemory()
+	game := model.Game{ID: model.NewID(), Status: "waiting"}
+	require.NoError(t, repo.Games().Create(&game))
+
+	loaded, err := repo.Games().Get(game.ID)
+	require.NoError(t, err)
+	loaded.Status = "active"
+
+	again, err := repo.Games().Get(game.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "waiting", again.Status, "changes only persist through Save")
+}
+
+func TestStaleSaveConflicts(t *testing.T) {
+	for name, repo := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			game := model.Game{ID: model.NewID(), Status: "waiting"}
+			require.NoError(t, repo.Games().Create(&game))
+			turn := model.Turn{ID: model.NewID(), RoundID: model.NewID(), TurnNumber: 1, Status: "active"}
+			require.NoError(t, repo.Turns().Create(&turn))
+
+			first, err := repo.Turns().Get(turn.ID)
+			require.NoError(t, err)
+			second, err := repo.Turns().Get(turn.ID)
+			require.NoError(t, err)
+			suit := "spades"
+			first.LeadSuit = &suit
+			require.NoError(t, repo.Turns().Save(first))
+			assert.Equal(t, 1, first.Version, "a save advances the version")
+			second.Status = "completed"
+			assert.ErrorIs(t, repo.Turns().Save(second), store.ErrConflict)
+			assert.Equal(t, 0, second.Version, "a failed save leaves the version alone")
+
+			saved, err := repo.Turns().Get(turn.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "active", saved.Status, "the stale save wrote nothing")
+			require.NoError(t, repo.Turns().Save(saved))
+
+			stale, err := repo.Games().Get(game.ID)
+			require.NoError(t, err)
+			require.NoError(t, repo.Games().SetStatus(game.ID, "active"))
+			stale.Status = "abandoned"
+			assert.ErrorIs(t, repo.Games().Save(stale), store.ErrConflict, "status changes advance the version too")
+		})
+	}
+}
+
+func TestTransactionsCommitOrRollBackTogether(t *testing.T) {
+	for name, repo := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			gameID := model.NewID()
+			failed := errors.New("action failed")
+			err := repo.Transaction(func(tx store.Store) error {
+				require.NoError(t, tx.Games().Create(&model.Game{ID: gameID, Status: "waiting"}))
+				require.NoError(t, tx.Events().Append(&model.GameEvent{ID: model.NewID(), GameID: gameID, Type: "deal", Payload: "{}"}))
+				return failed
+			})
+			assert.ErrorIs(t, err, failed)
+			_, err = repo.Games().Get(gameID)
+			assert.ErrorIs(t, err, store.ErrNotFound, "a failed transaction leaves nothing behind")
+			events, err := repo.Events().List(gameID)
+			require.NoError(t, err)
+			assert.Empty(t, events)
+
+			require.NoError(t, repo.Transaction(func(tx store.Store) error {
+				if err := tx.Games().Create(&model.Game{ID: gameID, Status: "waiting"}); err != nil {
+					return err
+				}
+				for _, typ := range []string{"deal", "play"} {
+					if err := tx.Events().Append(&model.GameEvent{ID: model.NewID(), GameID: gameID, Type: typ, Payload: "{}"}); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+			_, err = repo.Games().Get(gameID)
+			assert.NoError(t, err)
+			events, err = repo.Events().List(gameID)
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, "deal", events[0].Type)
+			assert.Equal(t, 1, events[0].Seq)
+			assert.Equal(t, 2, events[1].Seq)
+		})
+	}
+}
+
+func TestMemoryTransactionIsolatesAndRollsBackOnlyItsWrites(t *testing.T) {
+	repo := store.NewMemory()
+	require.NoError(t, repo.Games().Create(&model.Game{ID: "g1", Status: "waiting"}))
+
+	started, seen := make(chan struct{}), make(chan string)
+	go func() {
+		<-started
+		// Waits for the transaction, then writes outside it
+		game, _ := repo.Games().Get("g1")
+		repo.Logs().Create(&model.GameSessionLog{ID: model.NewID(), GameID: "g1", Message: "hi"})
+		seen <- game.Status
+	}()
+	err := repo.Transaction(func(tx store.Store) error {
+		require.NoError(t, tx.Games().SetStatus("g1", "active"))
+		require.NoError(t, tx.Games().Create(&model.Game{ID: "g2", Status: "waiting"}))
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		return errors.New("action failed")
+	})
+	require.Error(t, err)
+	assert.Equal(t, "waiting", <-seen, "nobody sees a transaction's writes before it commits")
+
+	game, err := repo.Games().Get("g1")
+	require.NoError(t, err)
+	assert.Equal(t, "waiting", game.Status)
+	_, err = repo.Games().Get("g2")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	logs, err := repo.Logs().Recent("g1", 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1, "a rollback keeps writes made outside the transaction")
+}
+
+func TestGameLocksSerializeOneGame(t *testing.T) {
+	for name, repo := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			unlock, err := repo.Lock("g1")
+			require.NoError(t, err)
+
+			acquired := make(chan struct{})
+			go func() {
+				again, err := repo.Lock("g1")
+				if err == nil {
+					close(acquired)
+					again()
+				}
+			}()
+			other, err := repo.Lock("g2")
+			require.NoError(t, err, "other games are not held up")
+			other()
+
+			select {
+			case <-acquired:
+				t.Fatal("the game was locked twice")
+			case <-time.After(50 * time.Millisecond):
+			}
+			unlock()
+			select {
+			case <-acquired:
+			case <-time.After(time.Second):
+				t.Fatal("the lock was not handed on")
+			}
+		})
+	}
+}
+
+func TestStatsFilterByTimeAndOpponents(t *testing.T) {
+	for name, repo := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+			for i, players := range [][]string{{"ann", "bob"}, {"ann", "bob", "cat"}, {"ann", "cat"}} {
+				gameID := model.NewID()
+				var rows []model.PlayerGameStats
+				for _, userID := range players {
+					require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: gameID, UserID: userID}))
+					rows = append(rows, model.PlayerGameStats{UserID: userID, GameID: gameID, CompletedAt: day.AddDate(0, 0, i), RoundsPlayed: i + 1})
+				}
+				require.NoError(t, repo.Stats().Record(gameID, rows))
+			}
+
+			rounds := func(filter store.StatsFilter) []int {
+				rows, err := repo.Stats().List("ann", filter)
+				require.NoError(t, err)
+				var played []int
+				for _, row := range rows {
+					played = append(played, row.RoundsPlayed)
+				}
+				return played
+			}
+			assert.Equal(t, []int{1, 2, 3}, rounds(store.StatsFilter{}))
+			assert.Equal(t, []int{2, 3}, rounds(store.StatsFilter{Opponents: []string{"cat"}}))
+			assert.Equal(t, []int{2}, rounds(store.StatsFilter{Opponents: []string{"bob", "cat"}}))
+			assert.Equal(t, []int{2}, rounds(store.StatsFilter{From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2)}))
+		})
+	}
+}
+
+func TestWebhooksAndGameDeletion(t *testing.T) {
+	for name, repo := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Truncate(time.Second)
+			ann := model.User{ID: model.NewID(), Name: "Ann", CreatedAt: now}
+			bot := model.User{ID: model.NewID(), Name: "Bot", IsBot: true, BotDifficulty: "easy", CreatedAt: now}
+			require.NoError(t, repo.Users().Create(&ann))
+			require.NoError(t, repo.Users().Create(&bot))
+			game := model.Game{ID: model.NewID(), RequesterID: ann.ID, Status: "abandoned", MaxPlayers: 8, MinPlayers: 2, CreatedAt: now}
+			require.NoError(t, repo.Games().Create(&game))
+			require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: game.ID, UserID: ann.ID, JoinedAt: now, LastSeenAt: now}))
+			require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: game.ID, UserID: bot.ID, JoinOrder: 1, JoinedAt: now, LastSeenAt: now}))
+
+			forGame := model.Webhook{ID: model.NewID(), OwnerID: ann.ID, GameID: &game.ID, URL: "https://example.com/a", Secret: "s", Active: true, CreatedAt: now}
+			forOwner := model.Webhook{ID: model.NewID(), OwnerID: ann.ID, URL: "https://example.com/b", Secret: "s", Active: true, CreatedAt: now.Add(time.Second)}
+			stranger := model.Webhook{ID: model.NewID(), OwnerID: model.NewID(), URL: "https://example.com/c", Secret: "s", Active: true, CreatedAt: now}
+			for _, hook := range []*model.Webhook{&forGame, &forOwner, &stranger} {
+				require.NoError(t, repo.Webhooks().Create(hook))
+			}
+			hooks, err := repo.Webhooks().ForGame(game.ID)
+			require.NoError(t, err)
+			assert.Len(t, hooks, 2, "the game's hooks and its players' own")
+			hooks, err = repo.Webhooks().ListForOwner(ann.ID)
+			require.NoError(t, err)
+			require.Len(t, hooks, 2)
+			assert.Equal(t, forOwner.ID, hooks[0].ID, "newest first")
+			_, err = repo.Webhooks().Get(stranger.ID, ann.ID)
+			assert.ErrorIs(t, err, store.ErrNotFound, "hooks are only found by their owner")
+
+			delivery := model.WebhookDelivery{ID: model.NewID(), WebhookID: forGame.ID, GameID: game.ID, Event: "game_ended", Status: "pending", CreatedAt: now}
+			require.NoError(t, repo.Webhooks().AddDelivery(&delivery))
+			delivery.Status, delivery.Attempts = "delivered", 1
+			require.NoError(t, repo.Webhooks().SaveDelivery(&delivery))
+			deliveries, err := repo.Webhooks().Deliveries(forGame.ID, 10)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 1)
+			assert.Equal(t, "delivered", deliveries[0].Status)
+
+			require.NoError(t, repo.Logs().Create(&model.GameSessionLog{ID: model.NewID(), GameID: game.ID, Type: "chat", Message: "hi", CreatedAt: now}))
+			require.NoError(t, repo.Events().Append(&model.GameEvent{ID: model.NewID(), GameID: game.ID, Type: "deal", Payload: "{}"}))
+			games, err := repo.Games().ListByStatus("abandoned")
+			require.NoError(t, err)
+			require.Len(t, games, 1)
+			purged, err := repo.Games().PurgeDetails(game.ID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), purged["game_session_logs"])
+			events, err := repo.Events().List(game.ID)
+			require.NoError(t, err)
+			assert.Len(t, events, 1, "purging keeps the journal")
+			deleted, err := repo.Games().Delete(game.ID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), deleted["games"])
+			assert.Equal(t, int64(2), deleted["game_players"])
+			assert.Equal(t, int64(1), deleted["webhooks"])
+			assert.Equal(t, int64(1), deleted["webhook_deliveries"])
+			assert.Equal(t, int64(1), deleted["game_events"])
+			assert.Equal(t, int64(1), deleted["users"], "the bot played nowhere else")
+			_, err = repo.Users().Get(ann.ID)
+			assert.NoError(t, err)
+			_, err = repo.Games().Get(game.ID)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+// Game lifecycle events that can be delivered to webhooks
+const (
+	GameCreated  = "game_created"
+	PlayerJoined = "player_joined"
+	GameStarted  = "game_started"
+	RoundEnded   = "round_ended"
+	GameEnded    = "game_ended"
+)
+
+// Events lists every event type a webhook may subscribe to
+var Events = []string{GameCreated, PlayerJoined, GameStarted, RoundEnded, GameEnded}
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Donkey-Signature"
+	EventHeader     = "X-Donkey-Event"
+	DeliveryHeader  = "X-Donkey-Delivery"
+)
+
+// MaxAttempts is how many times a delivery is tried before it is marked failed
+var MaxAttempts = 5
+
+// InitialBackoff is the wait before the first retry; it doubles on every retry
+var InitialBackoff = 2 * time.Second
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Payload is the JSON body POSTed to a webhook
+type Payload struct {
+	DeliveryID string      `json:"deliveryId"`
+	Event      string      `json:"event"`
+	GameID     string      `json:"gameId"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	Data       interface{} `json:"data"`
+}
+
+// Sign returns the signature header value for a body: "sha256=" followed by
+// the hex HMAC-SHA256 of the body keyed with the webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of body
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// IsValidEvent reports whether event is a known lifecycle event
+func IsValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribes reports whether the webhook wants the given event
+func subscribes(hook model.Webhook, event string) bool {
+	if strings.TrimSpace(hook.Events) == "" {
+		return true
+	}
+	for _, e := range strings.Split(hook.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatch queues an event for every active webhook in repo registered on
+// the game or owned by one of its players. Deliveries happen in the
+// background and are recorded in repo, so it must not be a transaction.
+func Dispatch(repo store.Store, gameID, event string, data interface{}) {
+	hooks, err := repo.Webhooks().ForGame(gameID)
+	if err != nil {
+		return
+	}
+
+	for _, hook := range hooks {
+		if !subscribes(hook, event) {
+			continue
+		}
+		payload := Payload{
+			DeliveryID: model.NewID(),
+			Event:      event,
+			GameID:     gameID,
+			CreatedAt:  time.Now(),
+			Data:       data,
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			continue
+		}
+		delivery := model.WebhookDelivery{
+			ID:        payload.DeliveryID,
+			WebhookID: hook.ID,
+			GameID:    gameID,
+			Event:     event,
+			Payload:   string(body),
+			Status:    "pending",
+			CreatedAt: payload.CreatedAt,
+		}
+		if err := repo.Webhooks().AddDelivery(&delivery); err != nil {
+			continue
+		}
+		go deliver(repo, hook, delivery)
+	}
+}
+
+// deliver POSTs a delivery, retrying with exponential backoff until it is
+// accepted or MaxAttempts is reached. Every attempt is recorded.
+func deliver(repo store.Store, hook model.Webhook, delivery model.WebhookDelivery) {
+	backoff := InitialBackoff
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		statusCode, err := post(hook, delivery)
+
+		delivery.Attempts = attempt
+		delivery.StatusCode = statusCode
+		delivery.LastError = ""
+		if err != nil {
+			delivery.LastError = err.Error()
+		}
+		if err == nil {
+			now := time.Now()
+			delivery.Status = "delivered"
+			delivery.DeliveredAt = &now
+		} else if attempt == MaxAttempts {
+			delivery.Status = "failed"
+		}
+		repo.Webhooks().SaveDelivery(&delivery)
+
+		if err == nil {
+			return
+		}
+		if attempt < MaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+// post sends a single attempt and returns the HTTP status received
+func post(hook model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Donkey-Webhook/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kairodrad/donkey/internal/model"
+	"github.com/kairodrad/donkey/internal/store"
+)
+
+func TestDispatchSignsAndRetriesUntilDelivered(t *testing.T) {
+	repo := store.NewMemory()
+	InitialBackoff = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	var calls int
+	var bodies [][]byte
+	var signatures []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(SignatureHeader))
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	owner := model.User{ID: model.NewID(), Name: "Owner", CreatedAt: time.Now()}
+	game := model.Game{ID: model.NewID(), RequesterID: owner.ID, Status: "active", CreatedAt: time.Now()}
+	require.NoError(t, repo.Users().Create(&owner))
+	require.NoError(t, repo.Games().Create(&game))
+	require.NoError(t, repo.Games().AddPlayer(&model.GamePlayer{GameID: game.ID, UserID: owner.ID, JoinedAt: time.Now(), LastSeenAt: time.Now()}))
+	hook := model.Webhook{ID: model.NewID(), OwnerID: owner.ID, URL: receiver.URL, Secret: "s3cret", Events: RoundEnded, Active: true, CreatedAt: time.Now()}
+	require.NoError(t, repo.Webhooks().Create(&hook))
+
+	Dispatch(repo, game.ID, GameStarted, nil) // not subscribed
+	Dispatch(repo, game.ID, RoundEnded, map[string]interface{}{"roundNumber": 1, "letter": "D"})
+
+	var delivery model.WebhookDelivery
+	assert.Eventually(t, func() bool {
+		deliveries, err := repo.Webhooks().Deliveries(hook.ID, 10)
+		if err != nil || len(deliveries) != 1 {
+			return false
+		}
+		delivery = deliveries[0]
+		return delivery.Status == "delivered"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+	assert.Equal(t, RoundEnded, delivery.Event)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, calls)
+	for i := range bodies {
+		assert.True(t, Verify("s3cret", bodies[i], signatures[i]))
+	}
+}
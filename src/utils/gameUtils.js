@@ -212,8 +212,8 @@ export async function getGameState(gameId, userId) {
   return apiCall(`/api/game/${gameId}/state/${userId}`)
 }
 
-export async function getGameLogs(gameId) {
-  return apiCall(`/api/game/${gameId}/logs`)
+export async function getGameLogs(gameId, userId) {
+  return apiCall(`/api/game/${gameId}/logs?userId=${encodeURIComponent(userId)}`)
 }
 
 export async function getUser(userId) {